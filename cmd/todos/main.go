@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log/slog"
@@ -9,13 +10,21 @@ import (
 	"os"
 	"time"
 
+	"github.com/course-go/todos/internal/blob"
 	"github.com/course-go/todos/internal/config"
 	"github.com/course-go/todos/internal/health"
 	"github.com/course-go/todos/internal/http"
+	cattachments "github.com/course-go/todos/internal/http/controllers/attachments"
+	ccomments "github.com/course-go/todos/internal/http/controllers/comments"
 	chealth "github.com/course-go/todos/internal/http/controllers/health"
+	ckeys "github.com/course-go/todos/internal/http/controllers/keys"
+	clists "github.com/course-go/todos/internal/http/controllers/lists"
+	ctags "github.com/course-go/todos/internal/http/controllers/tags"
 	ctodos "github.com/course-go/todos/internal/http/controllers/todos"
 	"github.com/course-go/todos/internal/http/metrics"
+	"github.com/course-go/todos/internal/http/middleware"
 	"github.com/course-go/todos/internal/logger"
+	"github.com/course-go/todos/internal/purge"
 	"github.com/course-go/todos/internal/repository"
 	ttime "github.com/course-go/todos/internal/time"
 	"github.com/go-playground/validator/v10"
@@ -25,6 +34,12 @@ import (
 
 var Version string
 
+// errMissingAdminKey reports that no API keys could ever be minted.
+var errMissingAdminKey = errors.New(
+	"authentication is enabled without admin key, " +
+		"set auth.adminKey in the config file or the TODOS_ADMIN_KEY environment variable",
+)
+
 var (
 	versionFlag    = flag.Bool("version", false, "output program version and exit")
 	configPathFlag = flag.String("config", "/etc/course-go/todos/config.yaml", "path to config file")
@@ -53,6 +68,10 @@ func runApp() error { //nolint: cyclop
 		return fmt.Errorf("failed parsing config: %w", err)
 	}
 
+	if !config.Disabled && config.AdminKey == "" {
+		return errMissingAdminKey
+	}
+
 	location, err := time.LoadLocation(config.Location)
 	if err != nil {
 		return fmt.Errorf("failed loading location %s: %w", config.Location, err)
@@ -65,11 +84,6 @@ func runApp() error { //nolint: cyclop
 		return fmt.Errorf("failed creating logger: %w", err)
 	}
 
-	err = repository.Migrate(&config.Database, logger)
-	if err != nil {
-		return fmt.Errorf("failed migrating database: %w", err)
-	}
-
 	ctx := context.Background()
 
 	registry, err := health.NewRegistry(ctx, health.WithService(config.Service.Name, Version))
@@ -77,11 +91,16 @@ func runApp() error { //nolint: cyclop
 		return fmt.Errorf("failed creating health registry: %w", err)
 	}
 
-	repo, err := repository.New(ctx, logger, registry, &config.Database)
+	repo, err := repository.Open(ctx, logger, registry, &config.Database)
 	if err != nil {
 		return fmt.Errorf("failed creating todo repository: %w", err)
 	}
 
+	store, err := blob.Open(&config.Storage, config.TransferTimeout)
+	if err != nil {
+		return fmt.Errorf("failed creating attachment store: %w", err)
+	}
+
 	exporter, err := prometheus.New()
 	if err != nil {
 		return fmt.Errorf("failed creating prometheus exporter: %w", err)
@@ -94,12 +113,42 @@ func runApp() error { //nolint: cyclop
 		return fmt.Errorf("failed creating http metrics: %w", err)
 	}
 
+	purger, err := purge.New(ctx, logger, registry, provider, repo, store, &config.Purge, ttime.Now())
+	if err != nil {
+		return fmt.Errorf("failed creating purge worker: %w", err)
+	}
+
+	go purger.Run(ctx)
+
 	hostname := net.JoinHostPort(config.Service.Host, config.Service.Port)
 	validator := validator.New(validator.WithRequiredStructEnabled())
 	todos := ctodos.NewController(logger, validator, repo, ttime.Now())
+	tags := ctags.NewController(logger, validator, repo, ttime.Now())
+	lists := clists.NewController(logger, validator, repo, ttime.Now())
+	comments := ccomments.NewController(logger, validator, repo, ttime.Now())
+	attachments := cattachments.NewController(logger, repo, store, &config.Attachments, ttime.Now())
+	keys := ckeys.NewController(logger, validator, repo, ttime.Now())
 	health := chealth.NewController(registry)
 
-	server, err := http.NewServer(logger, metrics, hostname, health, todos)
+	if config.Disabled {
+		logger.Warn("authentication is disabled, the api is open to anyone")
+	}
+
+	authenticate := middleware.Authenticate(logger, repo, &config.Auth, ttime.Now())
+
+	server, err := http.NewServer(
+		logger,
+		metrics,
+		hostname,
+		authenticate,
+		health,
+		todos,
+		tags,
+		lists,
+		comments,
+		attachments,
+		keys,
+	)
 	if err != nil {
 		return fmt.Errorf("failed creating http server: %w", err)
 	}
@@ -119,6 +168,7 @@ func runApp() error { //nolint: cyclop
 		"version", Version,
 		"hostname", hostname,
 		"location", config.Location,
+		"purgeRetention", config.Retention,
 	)
 
 	err = server.ListenAndServe()
@@ -0,0 +1,24 @@
+// Package actor passes the name of whoever makes changes through the context.
+package actor
+
+import "context"
+
+// Anonymous is the actor of changes made without any actor in the context.
+const Anonymous = "anonymous"
+
+type contextKey struct{}
+
+// With returns context carrying the actor.
+func With(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, contextKey{}, name)
+}
+
+// From returns the actor carried by the context or [Anonymous] if there is none.
+func From(ctx context.Context) string {
+	name, ok := ctx.Value(contextKey{}).(string)
+	if !ok || name == "" {
+		return Anonymous
+	}
+
+	return name
+}
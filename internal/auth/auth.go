@@ -0,0 +1,66 @@
+// Package auth describes API keys and passes the key authenticating the request through the context.
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// ScopeRead allows reading todos and everything related to them.
+	ScopeRead = "read"
+	// ScopeWrite allows changing todos and everything related to them.
+	ScopeWrite = "write"
+	// ScopeAdmin allows managing API keys and grants all the other scopes.
+	ScopeAdmin = "admin"
+
+	// tokenPrefix makes the tokens easy to recognize, for example by secret scanners.
+	tokenPrefix = "todos_"
+)
+
+// Key is an API key. Only hash of its token is kept, so the token is known just when the key is minted.
+type Key struct {
+	ID         uuid.UUID  `json:"id,omitempty"`
+	Name       string     `json:"name,omitempty"`
+	Hash       string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt,omitzero"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+}
+
+// HasScope reports whether the key grants the scope.
+func (k Key) HasScope(scope string) bool {
+	return slices.Contains(k.Scopes, scope) || slices.Contains(k.Scopes, ScopeAdmin)
+}
+
+// NewToken generates random token of a new key.
+func NewToken() string {
+	return tokenPrefix + rand.Text()
+}
+
+// Hash returns hex encoded SHA-256 hash of the token. Tokens are random enough not to need any salt.
+func Hash(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+type contextKey struct{}
+
+// With returns context carrying the key.
+func With(ctx context.Context, key Key) context.Context {
+	return context.WithValue(ctx, contextKey{}, key)
+}
+
+// From returns the key carried by the context.
+func From(ctx context.Context) (key Key, ok bool) {
+	key, ok = ctx.Value(contextKey{}).(Key)
+	return key, ok
+}
@@ -0,0 +1,57 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	stdtime "time"
+
+	"github.com/course-go/todos/internal/config"
+	"github.com/course-go/todos/internal/time"
+)
+
+const S3Driver = "s3"
+
+var (
+	ErrBlobNotFound = errors.New("blob with given key does not exist")
+	ErrInvalidKey   = errors.New("invalid blob key")
+	ErrStorage      = errors.New("failed accessing blob storage")
+)
+
+// Store keeps binary contents by their keys. Keys cannot contain slashes.
+type Store interface {
+	// Put stores the content of the given size under the key replacing any previous content.
+	Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error
+	// Get returns reader of the content stored under the key which has to be closed.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the content stored under the key. Deleting missing content is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// Open creates blob store selected by the storage config.
+// Requests to remote storages are abandoned after the timeout.
+func Open(config *config.Storage, timeout stdtime.Duration) (store Store, err error) { //nolint: ireturn
+	if config.Driver == S3Driver {
+		client := &http.Client{Timeout: timeout}
+		return NewS3(config, client, time.Now()), nil
+	}
+
+	local, err := NewLocal(config.Directory)
+	if err != nil {
+		return nil, err
+	}
+
+	return local, nil
+}
+
+// checkKey rejects empty keys and keys which could escape the directory or the bucket.
+func checkKey(key string) error {
+	if key == "" || strings.ContainsAny(key, `/\`) || strings.HasPrefix(key, ".") {
+		return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+
+	return nil
+}
@@ -0,0 +1,164 @@
+package blob_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/course-go/todos/internal/blob"
+	"github.com/course-go/todos/internal/config"
+)
+
+func TestStore(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Local", func(t *testing.T) {
+		t.Parallel()
+
+		store, err := blob.NewLocal(t.TempDir())
+		if err != nil {
+			t.Fatalf("could not create local store: %v", err)
+		}
+
+		testStore(t, store)
+	})
+
+	t.Run("S3", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(newS3StandIn(t))
+		t.Cleanup(server.Close)
+
+		cfg := &config.Storage{
+			Driver:    blob.S3Driver,
+			Endpoint:  server.URL,
+			Region:    "eu-central-1",
+			Bucket:    "todos",
+			AccessKey: "access",
+			SecretKey: "secret",
+		}
+		store := blob.NewS3(cfg, server.Client(), time.Now)
+
+		testStore(t, store)
+	})
+}
+
+// testStore runs the same tests against every store.
+func testStore(t *testing.T, store blob.Store) {
+	t.Helper()
+
+	ctx := t.Context()
+	key := "62446c85-3798-471f-abb8-75c1cdd7153b"
+	content := "receipt"
+
+	err := store.Put(ctx, key, strings.NewReader(content), int64(len(content)), "text/plain")
+	if err != nil {
+		t.Fatalf("could not put content: %v", err)
+	}
+
+	reader, err := store.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("could not get content: %v", err)
+	}
+
+	storedContent, err := io.ReadAll(reader)
+	_ = reader.Close()
+
+	if err != nil || string(storedContent) != content {
+		t.Fatalf("content does not match: expected: %s != actual: %s (%v)", content, storedContent, err)
+	}
+
+	err = store.Delete(ctx, key)
+	if err != nil {
+		t.Fatalf("could not delete content: %v", err)
+	}
+
+	_, err = store.Get(ctx, key)
+	if !errors.Is(err, blob.ErrBlobNotFound) {
+		t.Fatalf("content should be deleted: expected: %v != actual: %v", blob.ErrBlobNotFound, err)
+	}
+
+	err = store.Delete(ctx, key)
+	if err != nil {
+		t.Fatalf("deleting missing content should not fail: %v", err)
+	}
+
+	err = store.Put(ctx, "../escape", strings.NewReader(content), int64(len(content)), "text/plain")
+	if !errors.Is(err, blob.ErrInvalidKey) {
+		t.Fatalf("key should be invalid: expected: %v != actual: %v", blob.ErrInvalidKey, err)
+	}
+}
+
+// newS3StandIn returns handler keeping objects of the "todos" bucket in memory.
+// It only checks that requests are signed, not the signatures themselves.
+func newS3StandIn(t *testing.T) http.Handler {
+	t.Helper()
+
+	bucket := &s3Bucket{objects: make(map[string][]byte)}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization := r.Header.Get("Authorization")
+		if !strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=access/") ||
+			!strings.Contains(authorization, "/eu-central-1/s3/aws4_request") ||
+			r.Header.Get("X-Amz-Date") == "" {
+			t.Errorf("request is not signed: %s", authorization)
+			w.WriteHeader(http.StatusForbidden)
+
+			return
+		}
+
+		key, ok := strings.CutPrefix(r.URL.Path, "/todos/")
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		bucket.serveObject(w, r, key)
+	})
+}
+
+type s3Bucket struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (b *s3Bucket) serveObject(w http.ResponseWriter, r *http.Request, key string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		body, err := io.ReadAll(r.Body)
+		if err != nil || int64(len(body)) != r.ContentLength {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		b.objects[key] = body
+	case http.MethodGet:
+		object, ok := b.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		_, _ = w.Write(object)
+	case http.MethodDelete:
+		// Some S3 compatible services report deleting missing objects.
+		_, ok := b.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		delete(b.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
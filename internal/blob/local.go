@@ -0,0 +1,107 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+const localDirectoryPermissions = 0o750
+
+// LocalStore keeps contents as files in a local directory.
+type LocalStore struct {
+	directory string
+}
+
+var _ Store = LocalStore{}
+
+// NewLocal creates store in the directory. The directory is created if it does not exist.
+func NewLocal(directory string) (store LocalStore, err error) {
+	err = os.MkdirAll(directory, localDirectoryPermissions)
+	if err != nil {
+		return LocalStore{}, fmt.Errorf("failed creating blob directory: %w", err)
+	}
+
+	return LocalStore{directory: directory}, nil
+}
+
+// Put writes the content to a temporary file first so that incomplete content is never visible.
+func (s LocalStore) Put(_ context.Context, key string, content io.Reader, _ int64, _ string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(s.directory, ".upload-*")
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrStorage, err)
+	}
+
+	defer func() {
+		_ = os.Remove(file.Name())
+	}()
+
+	_, err = io.Copy(file, content)
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("%w: %w", ErrStorage, err)
+	}
+
+	err = file.Close()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrStorage, err)
+	}
+
+	err = os.Rename(file.Name(), path)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrStorage, err)
+	}
+
+	return nil
+}
+
+func (s LocalStore) Get(_ context.Context, key string) (content io.ReadCloser, err error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path) //nolint: gosec // The key cannot escape the directory.
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrBlobNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrStorage, err)
+	}
+
+	return file, nil
+}
+
+func (s LocalStore) Delete(_ context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: %w", ErrStorage, err)
+	}
+
+	return nil
+}
+
+// path returns path of the file with the content.
+func (s LocalStore) path(key string) (path string, err error) {
+	err = checkKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(s.directory, key), nil
+}
@@ -0,0 +1,201 @@
+package blob
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/course-go/todos/internal/config"
+	"github.com/course-go/todos/internal/time"
+)
+
+const (
+	// s3TimeFormat is the format of the request time used for signing.
+	s3TimeFormat = "20060102T150405Z"
+	// s3UnsignedPayload skips hashing of uploaded content so that it does not have to be read twice.
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	// s3EmptyPayload is SHA-256 hash of empty request body.
+	s3EmptyPayload  = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	s3SignedHeaders = "host;x-amz-content-sha256;x-amz-date"
+)
+
+// errS3NotFound reports missing object. Its meaning depends on the request.
+var errS3NotFound = errors.New("object not found")
+
+// S3Store keeps contents as objects in a bucket of S3 compatible service.
+// Requests are authenticated by AWS Signature Version 4.
+type S3Store struct {
+	client    *http.Client
+	time      time.Factory
+	endpoint  string
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+}
+
+var _ Store = S3Store{}
+
+func NewS3(config *config.Storage, client *http.Client, time time.Factory) S3Store {
+	return S3Store{
+		client:    client,
+		time:      time,
+		endpoint:  strings.TrimSuffix(config.Endpoint, "/"),
+		region:    config.Region,
+		bucket:    config.Bucket,
+		accessKey: config.AccessKey,
+		secretKey: config.SecretKey,
+	}
+}
+
+func (s S3Store) Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error {
+	req, err := s.newRequest(ctx, http.MethodPut, key, content)
+	if err != nil {
+		return err
+	}
+
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType)
+
+	res, err := s.do(req, s3UnsignedPayload)
+	if err != nil {
+		return err
+	}
+
+	_ = res.Body.Close()
+
+	return nil
+}
+
+func (s S3Store) Get(ctx context.Context, key string) (content io.ReadCloser, err error) {
+	req, err := s.newRequest(ctx, http.MethodGet, key, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.do(req, s3EmptyPayload)
+	if errors.Is(err, errS3NotFound) {
+		return nil, ErrBlobNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Body, nil
+}
+
+func (s S3Store) Delete(ctx context.Context, key string) error {
+	req, err := s.newRequest(ctx, http.MethodDelete, key, http.NoBody)
+	if err != nil {
+		return err
+	}
+
+	// Deleting missing content is not an error.
+	res, err := s.do(req, s3EmptyPayload)
+	if errors.Is(err, errS3NotFound) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	_ = res.Body.Close()
+
+	return nil
+}
+
+// newRequest creates request of the object addressed by the path of the bucket.
+func (s S3Store) newRequest(ctx context.Context, method, key string, body io.Reader) (*http.Request, error) {
+	err := checkKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	objectURL := s.endpoint + "/" + url.PathEscape(s.bucket) + "/" + url.PathEscape(key)
+
+	req, err := http.NewRequestWithContext(ctx, method, objectURL, body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrStorage, err)
+	}
+
+	return req, nil
+}
+
+// do signs and sends the request. Response body has to be closed when no error is returned.
+func (s S3Store) do(req *http.Request, payloadHash string) (*http.Response, error) {
+	s.sign(req, payloadHash)
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrStorage, err)
+	}
+
+	if res.StatusCode >= http.StatusOK && res.StatusCode < http.StatusMultipleChoices {
+		return res, nil
+	}
+
+	_ = res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %w", ErrStorage, errS3NotFound)
+	}
+
+	return nil, fmt.Errorf("%w: unexpected status %s", ErrStorage, res.Status)
+}
+
+// sign adds AWS Signature Version 4 authorization to the request.
+func (s S3Store) sign(req *http.Request, payloadHash string) {
+	now := s.time().UTC().Format(s3TimeFormat)
+	scope := now[:len("20060102")] + "/" + s.region + "/s3/aws4_request"
+
+	req.Header.Set("X-Amz-Date", now)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		"host:" + req.URL.Host,
+		"x-amz-content-sha256:" + payloadHash,
+		"x-amz-date:" + now,
+		"",
+		s3SignedHeaders,
+		payloadHash,
+	}, "\n")
+	canonicalRequestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		now,
+		scope,
+		hex.EncodeToString(canonicalRequestHash[:]),
+	}, "\n")
+
+	key := []byte("AWS4" + s.secretKey)
+	for part := range strings.SplitSeq(scope, "/") {
+		key = hmacSHA256(key, part)
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKey,
+		scope,
+		s3SignedHeaders,
+		hex.EncodeToString(hmacSHA256(key, stringToSign)),
+	))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	_, _ = h.Write([]byte(data))
+
+	return h.Sum(nil)
+}
@@ -4,10 +4,24 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
+// adminKeyEnv is the environment variable overriding [Auth.AdminKey].
+const adminKeyEnv = "TODOS_ADMIN_KEY"
+
+const (
+	defaultPurgeRetention = 30 * 24 * time.Hour
+	defaultPurgeInterval  = time.Hour
+	defaultPurgeBatchSize = 1000
+
+	defaultAttachmentsMaxSize         = 10 << 20
+	defaultAttachmentsDirectory       = "attachments"
+	defaultAttachmentsTransferTimeout = time.Minute
+)
+
 type Service struct {
 	Name     string `yaml:"name,omitempty"`
 	Host     string `yaml:"host,omitempty"`
@@ -20,6 +34,9 @@ type Logging struct {
 }
 
 type Database struct {
+	// Driver selects the storage, "memory" keeps todos only in memory. Database is used by default.
+	Driver string `yaml:"driver,omitempty"`
+	// Protocol selects the database, "sqlite" stores todos in file named by Name. Postgres is used otherwise.
 	Protocol string `yaml:"protocol"`
 	User     string `yaml:"user"`
 	Password string `yaml:"password"`
@@ -29,10 +46,61 @@ type Database struct {
 	Options  string `yaml:"options,omitempty"`
 }
 
+// Purge configures removal of deleted todos.
+type Purge struct {
+	// Retention is how long deleted todos are kept before being removed for good.
+	Retention time.Duration `yaml:"retention,omitempty"`
+	// Interval is the time between purges.
+	Interval time.Duration `yaml:"interval,omitempty"`
+	// BatchSize is the maximum number of todos removed by a single query.
+	BatchSize int `yaml:"batchSize,omitempty"`
+}
+
+// Attachments configures files attached to todos.
+type Attachments struct {
+	// MaxSize is the maximum size of a single attachment in bytes.
+	MaxSize int64 `yaml:"maxSize,omitempty"`
+	// ContentType selects how content type of attachments is determined, "client" trusts the type
+	// sent with the uploaded file. Content type is sniffed from the content of the file by default.
+	ContentType string `yaml:"contentType,omitempty"`
+	// AllowedTypes are the media types attachments can have. Any type is allowed when empty.
+	AllowedTypes []string `yaml:"allowedTypes,omitempty"`
+	// TransferTimeout limits how long uploading or downloading a single attachment can take
+	// including the requests to the storage. It replaces the much shorter server timeouts.
+	TransferTimeout time.Duration `yaml:"transferTimeout,omitempty"`
+	Storage         Storage       `yaml:"storage,omitempty"`
+}
+
+// Storage configures the blob store keeping contents of attachments.
+type Storage struct {
+	// Driver selects the store, "s3" stores attachments in S3 compatible bucket. Local directory is used by default.
+	Driver string `yaml:"driver,omitempty"`
+	// Directory is the local directory attachments are stored in.
+	Directory string `yaml:"directory,omitempty"`
+	// Endpoint is URL of the S3 compatible service. Buckets are addressed by the path.
+	Endpoint  string `yaml:"endpoint,omitempty"`
+	Region    string `yaml:"region,omitempty"`
+	Bucket    string `yaml:"bucket,omitempty"`
+	AccessKey string `yaml:"accessKey,omitempty"`
+	SecretKey string `yaml:"secretKey,omitempty"`
+}
+
+// Auth configures authentication of API requests with API keys.
+type Auth struct {
+	// Disabled leaves the API open to anyone who can reach it. API keys are required by default.
+	Disabled bool `yaml:"disabled,omitempty"`
+	// AdminKey is token of API key with admin scope used to mint the other keys. There is no such key when empty.
+	// It can be set by the TODOS_ADMIN_KEY environment variable, which takes precedence over the config file.
+	AdminKey string `yaml:"adminKey,omitempty"`
+}
+
 type Config struct {
-	Service  `yaml:"service,omitempty"`
-	Logging  `yaml:"logging,omitempty"`
-	Database `yaml:"database"`
+	Service     `yaml:"service,omitempty"`
+	Logging     `yaml:"logging,omitempty"`
+	Database    `yaml:"database"`
+	Purge       `yaml:"purge,omitempty"`
+	Attachments `yaml:"attachments,omitempty"`
+	Auth        `yaml:"auth,omitempty"`
 }
 
 func Parse(configPath string) (config *Config, err error) {
@@ -56,12 +124,21 @@ func Parse(configPath string) (config *Config, err error) {
 		return
 	}
 
+	setEnv(&cfg)
 	setDefaults(&cfg)
 	config = &cfg
 
 	return
 }
 
+// setEnv overrides the config by environment variables meant for secrets.
+func setEnv(cfg *Config) {
+	adminKey, ok := os.LookupEnv(adminKeyEnv)
+	if ok {
+		cfg.AdminKey = adminKey
+	}
+}
+
 func setDefaults(cfg *Config) {
 	if cfg.Service.Name == "" {
 		cfg.Service.Name = "unknown"
@@ -78,4 +155,35 @@ func setDefaults(cfg *Config) {
 	if cfg.Level == "" {
 		cfg.Level = "info"
 	}
+
+	setPurgeDefaults(&cfg.Purge)
+	setAttachmentsDefaults(&cfg.Attachments)
+}
+
+func setPurgeDefaults(cfg *Purge) {
+	if cfg.Retention == 0 {
+		cfg.Retention = defaultPurgeRetention
+	}
+
+	if cfg.Interval == 0 {
+		cfg.Interval = defaultPurgeInterval
+	}
+
+	if cfg.BatchSize == 0 {
+		cfg.BatchSize = defaultPurgeBatchSize
+	}
+}
+
+func setAttachmentsDefaults(cfg *Attachments) {
+	if cfg.MaxSize == 0 {
+		cfg.MaxSize = defaultAttachmentsMaxSize
+	}
+
+	if cfg.Storage.Directory == "" {
+		cfg.Storage.Directory = defaultAttachmentsDirectory
+	}
+
+	if cfg.TransferTimeout == 0 {
+		cfg.TransferTimeout = defaultAttachmentsTransferTimeout
+	}
 }
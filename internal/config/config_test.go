@@ -4,6 +4,7 @@ import (
 	"io"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/course-go/todos/internal/config"
 )
@@ -32,14 +33,47 @@ func TestConfig(t *testing.T) {
   password: postgres
   host: postgres
   port: 5432
-  name: todos`,
+  name: todos
+purge:
+  retention: 48h`,
 	)
 	if err != nil {
 		t.Fatalf("could not write to test config file: %v", err)
 	}
 
-	_, err = config.Parse(cfgPath)
+	cfg, err := config.Parse(cfgPath)
 	if err != nil {
 		t.Fatalf("could not parse config: %v", err)
 	}
+
+	expectedRetention := 48 * time.Hour
+	if cfg.Retention != expectedRetention {
+		t.Fatalf("purge retention does not match: expected: %s != actual: %s", expectedRetention, cfg.Retention)
+	}
+
+	var expectedMaxSize int64 = 10 << 20
+	if cfg.MaxSize != expectedMaxSize {
+		t.Fatalf("attachments max size does not match: expected: %d != actual: %d", expectedMaxSize, cfg.MaxSize)
+	}
+}
+
+func TestConfigAdminKeyFromEnv(t *testing.T) {
+	cfgPath := t.TempDir() + "/config.yaml"
+
+	err := os.WriteFile(cfgPath, []byte("auth:\n  adminKey: todos_from-file"), 0o600)
+	if err != nil {
+		t.Fatalf("could not write test config file: %v", err)
+	}
+
+	t.Setenv("TODOS_ADMIN_KEY", "todos_from-env")
+
+	cfg, err := config.Parse(cfgPath)
+	if err != nil {
+		t.Fatalf("could not parse config: %v", err)
+	}
+
+	expectedAdminKey := "todos_from-env"
+	if cfg.AdminKey != expectedAdminKey {
+		t.Fatalf("admin key does not match: expected: %s != actual: %s", expectedAdminKey, cfg.AdminKey)
+	}
 }
@@ -0,0 +1,505 @@
+package attachments
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	stdtime "time"
+
+	"github.com/course-go/todos/internal/blob"
+	"github.com/course-go/todos/internal/config"
+	"github.com/course-go/todos/internal/http/dto/response"
+	"github.com/course-go/todos/internal/http/exchange"
+	"github.com/course-go/todos/internal/repository"
+	"github.com/course-go/todos/internal/time"
+	"github.com/course-go/todos/internal/todos"
+)
+
+const (
+	// ClientContentType trusts the content type sent by the client instead of sniffing it.
+	ClientContentType = "client"
+
+	// fileFormName is the name of the multipart form field containing the uploaded file.
+	fileFormName = "file"
+	// maxFormOverhead is the space left in the request for the multipart boundaries and headers.
+	maxFormOverhead = 1 << 20
+	// sniffLength is the number of leading bytes considered when sniffing the content type.
+	sniffLength = 512
+)
+
+type Controller struct {
+	logger     *slog.Logger
+	repository repository.AttachmentRepository
+	store      blob.Store
+	config     *config.Attachments
+	time       time.Factory
+}
+
+func NewController(
+	logger *slog.Logger,
+	repository repository.AttachmentRepository,
+	store blob.Store,
+	config *config.Attachments,
+	time time.Factory,
+) *Controller {
+	return &Controller{
+		logger:     logger.With("component", "http.controllers.attachments"),
+		repository: repository,
+		store:      store,
+		config:     config,
+		time:       time,
+	}
+}
+
+func (c *Controller) GetAttachmentsController(w http.ResponseWriter, r *http.Request) {
+	todoID, ok := exchange.ParseID(w, r, c.logger, "id")
+	if !ok {
+		return
+	}
+
+	attachments, err := c.repository.GetAttachments(r.Context(), todoID)
+	if errors.Is(err, repository.ErrTodoNotFound) {
+		c.logger.Debug("no matching id for todo",
+			"todoId", todoID,
+		)
+
+		code := http.StatusNotFound
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	if err != nil {
+		c.logger.Error("failed retrieving attachments",
+			"error", err,
+			"todoId", todoID,
+		)
+
+		code := http.StatusInternalServerError
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	exchange.WriteData(w, c.logger, http.StatusOK, "attachments", attachments)
+}
+
+// GetAttachmentController downloads content of the attachment.
+func (c *Controller) GetAttachmentController(w http.ResponseWriter, r *http.Request) {
+	c.extendDeadlines(w)
+
+	todoID, ok := exchange.ParseID(w, r, c.logger, "id")
+	if !ok {
+		return
+	}
+
+	id, ok := exchange.ParseID(w, r, c.logger, "attachmentId")
+	if !ok {
+		return
+	}
+
+	attachment, err := c.repository.GetAttachment(r.Context(), todoID, id)
+	if errors.Is(err, repository.ErrTodoNotFound) || errors.Is(err, repository.ErrAttachmentNotFound) {
+		c.logger.Debug("no matching id for attachment",
+			"todoId", todoID,
+			"id", id,
+		)
+
+		code := http.StatusNotFound
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	if err != nil {
+		c.logger.Error("failed retrieving attachment",
+			"error", err,
+			"todoId", todoID,
+			"id", id,
+		)
+
+		code := http.StatusInternalServerError
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	content, err := c.store.Get(r.Context(), attachment.ID.String())
+	if err != nil {
+		c.logger.Error("failed retrieving attachment content",
+			"error", err,
+			"todoId", todoID,
+			"id", id,
+		)
+
+		code := http.StatusInternalServerError
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	defer func() {
+		_ = content.Close()
+	}()
+
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(attachment.Size, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+		"filename": attachment.Name,
+	}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+
+	_, err = io.Copy(w, content)
+	if err != nil {
+		c.logger.Error("failed writing attachment content",
+			"error", err,
+			"todoId", todoID,
+			"id", id,
+		)
+	}
+}
+
+// CreateAttachmentController uploads file sent as the "file" field of multipart form.
+func (c *Controller) CreateAttachmentController(w http.ResponseWriter, r *http.Request) {
+	c.extendDeadlines(w)
+
+	todoID, ok := exchange.ParseID(w, r, c.logger, "id")
+	if !ok {
+		return
+	}
+
+	attachment, content, ok := c.readFile(w, r)
+	if !ok {
+		return
+	}
+
+	defer func() {
+		_ = content.Close()
+		_ = os.Remove(content.Name())
+	}()
+
+	attachment.TodoID = todoID
+	attachment.CreatedAt = c.time()
+
+	attachment, err := c.repository.CreateAttachment(r.Context(), attachment)
+	if errors.Is(err, repository.ErrTodoNotFound) {
+		c.logger.Debug("no matching id for todo",
+			"todoId", todoID,
+		)
+
+		code := http.StatusNotFound
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	if err != nil {
+		c.logger.Error("failed creating attachment",
+			"error", err,
+			"todoId", todoID,
+		)
+
+		code := http.StatusInternalServerError
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	key := attachment.ID.String()
+
+	err = c.store.Put(r.Context(), key, content, attachment.Size, attachment.ContentType)
+	if err != nil {
+		c.logger.Error("failed storing attachment content",
+			"error", err,
+			"todoId", todoID,
+			"id", attachment.ID,
+		)
+
+		// The attachment would have no content otherwise.
+		err = c.repository.DeleteAttachment(r.Context(), todoID, attachment.ID)
+		if err != nil {
+			c.logger.Error("failed deleting attachment without content",
+				"error", err,
+				"todoId", todoID,
+				"id", attachment.ID,
+			)
+		}
+
+		code := http.StatusInternalServerError
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	exchange.WriteData(w, c.logger, http.StatusCreated, "attachment", attachment)
+}
+
+func (c *Controller) DeleteAttachmentController(w http.ResponseWriter, r *http.Request) {
+	todoID, ok := exchange.ParseID(w, r, c.logger, "id")
+	if !ok {
+		return
+	}
+
+	id, ok := exchange.ParseID(w, r, c.logger, "attachmentId")
+	if !ok {
+		return
+	}
+
+	err := c.repository.DeleteAttachment(r.Context(), todoID, id)
+	if errors.Is(err, repository.ErrTodoNotFound) || errors.Is(err, repository.ErrAttachmentNotFound) {
+		c.logger.Debug("no matching id for attachment",
+			"todoId", todoID,
+			"id", id,
+		)
+
+		code := http.StatusNotFound
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	if err != nil {
+		c.logger.Error("failed deleting attachment",
+			"error", err,
+			"todoId", todoID,
+			"id", id,
+		)
+
+		code := http.StatusInternalServerError
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	// The attachment is already gone so leftover content is only logged.
+	err = c.store.Delete(r.Context(), id.String())
+	if err != nil {
+		c.logger.Error("failed deleting attachment content",
+			"error", err,
+			"todoId", todoID,
+			"id", id,
+		)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// extendDeadlines replaces the server timeouts, which are too short for transferring files,
+// with the configured transfer timeout.
+func (c *Controller) extendDeadlines(w http.ResponseWriter) {
+	// Deadlines of the connection are always compared with the wall clock.
+	deadline := stdtime.Now().Add(c.config.TransferTimeout)
+	rc := http.NewResponseController(w)
+
+	err := errors.Join(rc.SetReadDeadline(deadline), rc.SetWriteDeadline(deadline))
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		c.logger.Warn("failed extending deadlines",
+			"error", err,
+		)
+	}
+}
+
+// readFile reads the uploaded file enforcing the configured size limit and content types.
+// The content type is checked right after the leading bytes are read and the content is then
+// spooled to a temporary file, so the size is known before storing it. The caller has to close
+// and remove the file.
+func (c *Controller) readFile(
+	w http.ResponseWriter,
+	r *http.Request,
+) (attachment todos.Attachment, content *os.File, ok bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, c.config.MaxSize+maxFormOverhead)
+
+	part, err := filePart(r)
+	if err != nil {
+		c.logger.Warn("failed reading multipart form",
+			"error", err,
+		)
+
+		c.writeReadError(w, err, "multipart form with file is required")
+
+		return todos.Attachment{}, nil, false
+	}
+
+	head := make([]byte, sniffLength)
+
+	n, err := io.ReadFull(part, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		c.logger.Warn("failed reading uploaded file",
+			"error", err,
+		)
+
+		c.writeReadError(w, err, "file could not be read")
+
+		return todos.Attachment{}, nil, false
+	}
+
+	head = head[:n]
+
+	contentType := c.contentType(part, head)
+	if !c.isAllowed(contentType) {
+		code := http.StatusUnsupportedMediaType
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorMessageBytes(code, "files of type "+contentType+" are not allowed"))
+
+		return todos.Attachment{}, nil, false
+	}
+
+	content, size, ok := c.spoolFile(w, io.MultiReader(bytes.NewReader(head), part))
+	if !ok {
+		return todos.Attachment{}, nil, false
+	}
+
+	attachment = todos.Attachment{
+		Name:        part.FileName(),
+		ContentType: contentType,
+		Size:        size,
+	}
+
+	return attachment, content, true
+}
+
+// spoolFile copies the uploaded file to a temporary file enforcing the configured size limit.
+// The returned file is positioned at its start.
+func (c *Controller) spoolFile(w http.ResponseWriter, upload io.Reader) (content *os.File, size int64, ok bool) {
+	content, err := os.CreateTemp("", "todos-upload-*")
+	if err != nil {
+		c.logger.Error("failed creating temporary file",
+			"error", err,
+		)
+
+		code := http.StatusInternalServerError
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return nil, 0, false
+	}
+
+	discard := func() {
+		_ = content.Close()
+		_ = os.Remove(content.Name())
+	}
+
+	size, err = io.Copy(content, io.LimitReader(upload, c.config.MaxSize+1))
+	if err != nil {
+		c.logger.Warn("failed reading uploaded file",
+			"error", err,
+		)
+
+		discard()
+		c.writeReadError(w, err, "file could not be read")
+
+		return nil, 0, false
+	}
+
+	if size > c.config.MaxSize {
+		discard()
+
+		message := "file cannot exceed " + strconv.FormatInt(c.config.MaxSize, 10) + " bytes"
+
+		code := http.StatusRequestEntityTooLarge
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorMessageBytes(code, message))
+
+		return nil, 0, false
+	}
+
+	_, err = content.Seek(0, io.SeekStart)
+	if err != nil {
+		c.logger.Error("failed rewinding temporary file",
+			"error", err,
+		)
+
+		discard()
+
+		code := http.StatusInternalServerError
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return nil, 0, false
+	}
+
+	return content, size, true
+}
+
+// contentType determines content type of the uploaded file as configured.
+// It falls back to sniffing the leading bytes when the client did not send any valid one.
+func (c *Controller) contentType(part *multipart.Part, head []byte) string {
+	if c.config.ContentType == ClientContentType {
+		contentType := part.Header.Get("Content-Type")
+
+		_, _, err := mime.ParseMediaType(contentType)
+		if err == nil {
+			return contentType
+		}
+	}
+
+	return http.DetectContentType(head)
+}
+
+// isAllowed reports whether attachments can have the content type.
+// Parameters of the content type such as charset are ignored.
+func (c *Controller) isAllowed(contentType string) bool {
+	if len(c.config.AllowedTypes) == 0 {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return slices.Contains(c.config.AllowedTypes, mediaType)
+}
+
+// writeReadError responds to failed reading of the request body.
+func (c *Controller) writeReadError(w http.ResponseWriter, err error, message string) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		code := http.StatusRequestEntityTooLarge
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	code := http.StatusBadRequest
+	w.WriteHeader(code)
+	_, _ = w.Write(response.ErrorMessageBytes(code, message))
+}
+
+// filePart returns the part of the multipart form containing named file.
+func filePart(r *http.Request) (*multipart.Part, error) {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, err //nolint: wrapcheck
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			return nil, err //nolint: wrapcheck
+		}
+
+		if part.FormName() == fileFormName && part.FileName() != "" {
+			return part, nil
+		}
+	}
+}
@@ -0,0 +1,319 @@
+package attachments_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/course-go/todos/internal/blob"
+	"github.com/course-go/todos/internal/http/controllers/attachments"
+	"github.com/course-go/todos/internal/utils/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+)
+
+const (
+	apiURLPrefix = "/api/v1"
+
+	nonExistingAttachmentID = "be95c29a-c4dd-4d31-a5c4-d229f3374ab7"
+)
+
+func TestAttachmentsControllers(t *testing.T) { //nolint: tparallel
+	t.Parallel()
+
+	ctx := t.Context()
+	logger := test.NewTestLogger(t)
+	r := test.NewTestRouter(ctx, t, logger)
+
+	todoURL := apiURLPrefix + "/todos/62446c85-3798-471f-abb8-75c1cdd7153b"
+	attachmentsURL := todoURL + "/attachments"
+
+	var attachmentID string
+
+	t.Run("Get empty Attachments", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodGet, attachmentsURL, http.NoBody)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusOK)
+
+		expectedBodyBytes := []byte(`{"data":{"attachments":[]}}`)
+		test.CompareResponseBodies(t, res, expectedBodyBytes)
+	})
+
+	t.Run("Create Attachment", func(t *testing.T) { //nolint: paralleltest
+		// The client content type is ignored as the content type is sniffed.
+		req := newUploadRequest(t, attachmentsURL, "shopping.txt", "image/png", "Buy a new mop")
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusCreated)
+
+		attachment, _ := test.DecodeResponseBody(t, res).Data["attachment"].(map[string]any)
+		attachmentID, _ = attachment["id"].(string)
+
+		expectedAttachment := map[string]any{
+			"id":          attachmentID,
+			"todoId":      "62446c85-3798-471f-abb8-75c1cdd7153b",
+			"name":        "shopping.txt",
+			"contentType": "text/plain; charset=utf-8",
+			"size":        float64(len("Buy a new mop")),
+			"createdAt":   "2024-08-18T12:14:45.847679Z",
+		}
+		if attachmentID == "" || !cmp.Equal(expectedAttachment, attachment) {
+			t.Errorf("created attachment does not match: %s", cmp.Diff(expectedAttachment, attachment))
+		}
+	})
+
+	t.Run("Get Attachments", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodGet, attachmentsURL, http.NoBody)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusOK)
+
+		expectedBodyBytes := []byte(`{
+		   "data":{
+			  "attachments":[
+				 {
+					"id":"` + attachmentID + `",
+					"todoId":"62446c85-3798-471f-abb8-75c1cdd7153b",
+					"name":"shopping.txt",
+					"contentType":"text/plain; charset=utf-8",
+					"size":13,
+					"createdAt":"2024-08-18T12:14:45.847679Z"
+				 }
+			  ]
+		   }
+		}`)
+		test.CompareResponseBodies(t, res, expectedBodyBytes)
+	})
+
+	t.Run("Download Attachment", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodGet, attachmentsURL+"/"+attachmentID, http.NoBody)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusOK)
+
+		expectedHeaders := map[string]string{
+			"Content-Type":        "text/plain; charset=utf-8",
+			"Content-Length":      "13",
+			"Content-Disposition": "attachment; filename=shopping.txt",
+		}
+		for name, expectedValue := range expectedHeaders {
+			if values := res.Header.Values(name); len(values) != 1 || values[0] != expectedValue {
+				t.Errorf("header %s does not match: expected: %s != actual: %v", name, expectedValue, values)
+			}
+		}
+
+		content, _ := io.ReadAll(res.Body)
+		if string(content) != "Buy a new mop" {
+			t.Errorf("content does not match: expected: Buy a new mop != actual: %s", content)
+		}
+	})
+
+	t.Run("Create Attachment exceeding max size", func(t *testing.T) { //nolint: paralleltest
+		content := strings.Repeat("mop", 1024)
+		req := newUploadRequest(t, attachmentsURL, "mops.txt", "text/plain", content)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusRequestEntityTooLarge)
+
+		expectedBodyBytes := []byte(`{"error":"Request Entity Too Large: file cannot exceed 1024 bytes"}`)
+		test.CompareResponseBodies(t, res, expectedBodyBytes)
+	})
+
+	t.Run("Create Attachment longer than sniffed content", func(t *testing.T) { //nolint: paralleltest
+		content := strings.Repeat("mop", 300)
+		req := newUploadRequest(t, attachmentsURL, "mops.txt", "text/plain", content)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusCreated)
+
+		attachment, _ := test.DecodeResponseBody(t, res).Data["attachment"].(map[string]any)
+		id, _ := attachment["id"].(string)
+
+		req = httptest.NewRequest(http.MethodGet, attachmentsURL+"/"+id, http.NoBody)
+		rr = httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res = rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusOK)
+
+		storedContent, _ := io.ReadAll(res.Body)
+		if string(storedContent) != content || attachment["size"] != float64(len(content)) {
+			t.Errorf("content does not match: expected %d bytes != actual: %d bytes (%v)",
+				len(content),
+				len(storedContent),
+				attachment["size"],
+			)
+		}
+	})
+
+	t.Run("Create Attachment of not allowed type", func(t *testing.T) { //nolint: paralleltest
+		req := newUploadRequest(t, attachmentsURL, "receipt.pdf", "text/plain", "%PDF-1.7")
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusUnsupportedMediaType)
+
+		expectedBodyBytes := []byte(`{"error":"Unsupported Media Type: files of type application/pdf are not allowed"}`)
+		test.CompareResponseBodies(t, res, expectedBodyBytes)
+	})
+
+	t.Run("Create Attachment without file", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodPost, attachmentsURL, strings.NewReader(`{"name":"shopping.txt"}`))
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		test.CompareResponseCodes(t, rr.Result(), http.StatusBadRequest)
+	})
+
+	t.Run("Create Attachment of non-existing Todo", func(t *testing.T) { //nolint: paralleltest
+		req := newUploadRequest(
+			t,
+			apiURLPrefix+"/todos/be95c29a-c4dd-4d31-a5c4-d229f3374ab7/attachments",
+			"shopping.txt",
+			"text/plain",
+			"Buy a new mop",
+		)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusNotFound)
+
+		expectedBodyBytes := []byte(`{"error":"Not Found"}`)
+		test.CompareResponseBodies(t, res, expectedBodyBytes)
+	})
+
+	t.Run("Download non-existing Attachment", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodGet, attachmentsURL+"/"+nonExistingAttachmentID, http.NoBody)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		test.CompareResponseCodes(t, rr.Result(), http.StatusNotFound)
+	})
+
+	t.Run("Delete Attachment", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodDelete, attachmentsURL+"/"+attachmentID, http.NoBody)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		test.CompareResponseCodes(t, rr.Result(), http.StatusNoContent)
+
+		req = httptest.NewRequest(http.MethodGet, attachmentsURL+"/"+attachmentID, http.NoBody)
+		rr = httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		test.CompareResponseCodes(t, rr.Result(), http.StatusNotFound)
+	})
+}
+
+// failingStore is blob store whose every operation fails.
+type failingStore struct{}
+
+func (failingStore) Put(context.Context, string, io.Reader, int64, string) error {
+	return blob.ErrStorage
+}
+
+func (failingStore) Get(context.Context, string) (io.ReadCloser, error) {
+	return nil, blob.ErrStorage
+}
+
+func (failingStore) Delete(context.Context, string) error {
+	return blob.ErrStorage
+}
+
+func TestCreateAttachmentControllerWithFailingStore(t *testing.T) {
+	t.Parallel()
+
+	todoID := "62446c85-3798-471f-abb8-75c1cdd7153b"
+	repository := test.NewTestMemoryRepository(t)
+	c := attachments.NewController(
+		test.NewTestLogger(t),
+		repository,
+		failingStore{},
+		test.NewTestAttachmentsConfig(t),
+		test.NewTimeNow(t),
+	)
+
+	req := newUploadRequest(
+		t,
+		apiURLPrefix+"/todos/"+todoID+"/attachments",
+		"shopping.txt",
+		"text/plain",
+		"Buy a new mop",
+	)
+	req.SetPathValue("id", todoID)
+
+	rr := httptest.NewRecorder()
+
+	c.CreateAttachmentController(rr, req)
+
+	test.CompareResponseCodes(t, rr.Result(), http.StatusInternalServerError)
+
+	storedAttachments, err := repository.GetAttachments(t.Context(), uuid.MustParse(todoID))
+	if err != nil {
+		t.Fatalf("could not get attachments: %v", err)
+	}
+
+	if len(storedAttachments) != 0 {
+		t.Errorf("attachment without content should not be kept: %v", storedAttachments)
+	}
+}
+
+// newUploadRequest creates request uploading the content as file of multipart form.
+func newUploadRequest(t *testing.T, url, name, contentType, content string) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+
+	writer := multipart.NewWriter(&body)
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", `form-data; name="file"; filename="`+name+`"`)
+	header.Set("Content-Type", contentType)
+
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		t.Fatalf("could not create multipart form: %v", err)
+	}
+
+	_, _ = part.Write([]byte(content))
+	_ = writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, url, &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	return req
+}
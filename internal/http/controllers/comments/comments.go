@@ -0,0 +1,219 @@
+package comments
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/course-go/todos/internal/http/dto/request"
+	"github.com/course-go/todos/internal/http/dto/response"
+	"github.com/course-go/todos/internal/http/exchange"
+	"github.com/course-go/todos/internal/repository"
+	"github.com/course-go/todos/internal/time"
+	"github.com/course-go/todos/internal/todos"
+	"github.com/go-playground/validator/v10"
+)
+
+type Controller struct {
+	logger     *slog.Logger
+	validator  *validator.Validate
+	repository repository.CommentRepository
+	time       time.Factory
+}
+
+func NewController(
+	logger *slog.Logger,
+	validator *validator.Validate,
+	repository repository.CommentRepository,
+	time time.Factory,
+) *Controller {
+	return &Controller{
+		logger:     logger.With("component", "http.controllers.comments"),
+		validator:  validator,
+		repository: repository,
+		time:       time,
+	}
+}
+
+func (c *Controller) GetCommentsController(w http.ResponseWriter, r *http.Request) {
+	todoID, ok := exchange.ParseID(w, r, c.logger, "id")
+	if !ok {
+		return
+	}
+
+	comments, err := c.repository.GetComments(r.Context(), todoID)
+	if errors.Is(err, repository.ErrTodoNotFound) {
+		c.logger.Debug("no matching id for todo",
+			"todoId", todoID,
+		)
+
+		code := http.StatusNotFound
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	if err != nil {
+		c.logger.Error("failed retrieving comments",
+			"error", err,
+			"todoId", todoID,
+		)
+
+		code := http.StatusInternalServerError
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	exchange.WriteData(w, c.logger, http.StatusOK, "comments", comments)
+}
+
+func (c *Controller) CreateCommentController(w http.ResponseWriter, r *http.Request) {
+	todoID, ok := exchange.ParseID(w, r, c.logger, "id")
+	if !ok {
+		return
+	}
+
+	var req request.CreateCommentRequest
+
+	ok = exchange.BindRequest(w, r, c.logger, c.validator, &req)
+	if !ok {
+		return
+	}
+
+	comment := todos.Comment{
+		TodoID:    todoID,
+		Author:    req.Author,
+		Body:      req.Body,
+		CreatedAt: c.time(),
+	}
+
+	comment, err := c.repository.CreateComment(r.Context(), comment)
+	if errors.Is(err, repository.ErrTodoNotFound) {
+		c.logger.Debug("no matching id for todo",
+			"todoId", todoID,
+		)
+
+		code := http.StatusNotFound
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	if err != nil {
+		c.logger.Error("failed creating comment",
+			"error", err,
+			"todoId", todoID,
+		)
+
+		code := http.StatusInternalServerError
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	exchange.WriteData(w, c.logger, http.StatusCreated, "comment", comment)
+}
+
+func (c *Controller) UpdateCommentController(w http.ResponseWriter, r *http.Request) {
+	todoID, ok := exchange.ParseID(w, r, c.logger, "id")
+	if !ok {
+		return
+	}
+
+	id, ok := exchange.ParseID(w, r, c.logger, "commentId")
+	if !ok {
+		return
+	}
+
+	var req request.UpdateCommentRequest
+
+	ok = exchange.BindRequest(w, r, c.logger, c.validator, &req)
+	if !ok {
+		return
+	}
+
+	now := c.time()
+	comment := todos.Comment{
+		ID:        id,
+		TodoID:    todoID,
+		Body:      req.Body,
+		UpdatedAt: &now,
+	}
+
+	comment, err := c.repository.SaveComment(r.Context(), comment)
+	if errors.Is(err, repository.ErrTodoNotFound) || errors.Is(err, repository.ErrCommentNotFound) {
+		c.logger.Debug("no matching id for comment",
+			"todoId", todoID,
+			"id", id,
+		)
+
+		code := http.StatusNotFound
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	if err != nil {
+		c.logger.Error("failed saving comment",
+			"error", err,
+			"todoId", todoID,
+			"id", id,
+		)
+
+		code := http.StatusInternalServerError
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	exchange.WriteData(w, c.logger, http.StatusOK, "comment", comment)
+}
+
+func (c *Controller) DeleteCommentController(w http.ResponseWriter, r *http.Request) {
+	todoID, ok := exchange.ParseID(w, r, c.logger, "id")
+	if !ok {
+		return
+	}
+
+	id, ok := exchange.ParseID(w, r, c.logger, "commentId")
+	if !ok {
+		return
+	}
+
+	err := c.repository.DeleteComment(r.Context(), todoID, id, c.time())
+	if errors.Is(err, repository.ErrTodoNotFound) || errors.Is(err, repository.ErrCommentNotFound) {
+		c.logger.Debug("no matching id for comment",
+			"todoId", todoID,
+			"id", id,
+		)
+
+		code := http.StatusNotFound
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	if err != nil {
+		c.logger.Error("failed deleting comment",
+			"error", err,
+			"todoId", todoID,
+			"id", id,
+		)
+
+		code := http.StatusInternalServerError
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
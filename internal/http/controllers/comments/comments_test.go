@@ -0,0 +1,207 @@
+package comments_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/course-go/todos/internal/utils/test"
+	"github.com/google/go-cmp/cmp"
+)
+
+const (
+	apiURLPrefix = "/api/v1"
+
+	nonExistingCommentID = "be95c29a-c4dd-4d31-a5c4-d229f3374ab7"
+)
+
+func TestCommentsControllers(t *testing.T) { //nolint: tparallel
+	t.Parallel()
+
+	ctx := t.Context()
+	logger := test.NewTestLogger(t)
+	r := test.NewTestRouter(ctx, t, logger)
+
+	todoURL := apiURLPrefix + "/todos/62446c85-3798-471f-abb8-75c1cdd7153b"
+	commentsURL := todoURL + "/comments"
+
+	var commentID string
+
+	t.Run("Get empty Comments", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodGet, commentsURL, http.NoBody)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusOK)
+
+		expectedBodyBytes := []byte(`{"data":{"comments":[]}}`)
+		test.CompareResponseBodies(t, res, expectedBodyBytes)
+	})
+
+	t.Run("Create Comment", func(t *testing.T) { //nolint: paralleltest
+		reader := strings.NewReader(`{"author":"Alex","body":"Use the new mop"}`)
+		req := httptest.NewRequest(http.MethodPost, commentsURL, reader)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusCreated)
+
+		comment, _ := test.DecodeResponseBody(t, res).Data["comment"].(map[string]any)
+		commentID, _ = comment["id"].(string)
+
+		expectedComment := map[string]any{
+			"id":        commentID,
+			"todoId":    "62446c85-3798-471f-abb8-75c1cdd7153b",
+			"author":    "Alex",
+			"body":      "Use the new mop",
+			"createdAt": "2024-08-18T12:14:45.847679Z",
+		}
+		if commentID == "" || !cmp.Equal(expectedComment, comment) {
+			t.Errorf("created comment does not match: %s", cmp.Diff(expectedComment, comment))
+		}
+	})
+
+	t.Run("Create Comment with invalid body", func(t *testing.T) { //nolint: paralleltest
+		for _, body := range []string{`{}`, `{"author":"Alex"}`, `{"body":"Use the new mop"}`, `{"author":`} {
+			req := httptest.NewRequest(http.MethodPost, commentsURL, strings.NewReader(body))
+			rr := httptest.NewRecorder()
+
+			r.ServeHTTP(rr, req)
+
+			test.CompareResponseCodes(t, rr.Result(), http.StatusBadRequest)
+		}
+	})
+
+	t.Run("Create Comment of non-existing Todo", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(
+			http.MethodPost,
+			apiURLPrefix+"/todos/be95c29a-c4dd-4d31-a5c4-d229f3374ab7/comments",
+			strings.NewReader(`{"author":"Alex","body":"Use the new mop"}`),
+		)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusNotFound)
+
+		expectedBodyBytes := []byte(`{"error":"Not Found"}`)
+		test.CompareResponseBodies(t, res, expectedBodyBytes)
+	})
+
+	t.Run("Update Comment", func(t *testing.T) { //nolint: paralleltest
+		reader := strings.NewReader(`{"body":"Use the old mop"}`)
+		req := httptest.NewRequest(http.MethodPut, commentsURL+"/"+commentID, reader)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusOK)
+
+		expectedBodyBytes := []byte(`{
+		   "data":{
+			  "comment":{
+				 "id":"` + commentID + `",
+				 "todoId":"62446c85-3798-471f-abb8-75c1cdd7153b",
+				 "author":"Alex",
+				 "body":"Use the old mop",
+				 "createdAt":"2024-08-18T12:14:45.847679Z",
+				 "updatedAt":"2024-08-18T12:14:45.847679Z"
+			  }
+		   }
+		}`)
+		test.CompareResponseBodies(t, res, expectedBodyBytes)
+	})
+
+	t.Run("Update non-existing Comment", func(t *testing.T) { //nolint: paralleltest
+		reader := strings.NewReader(`{"body":"Use the old mop"}`)
+		req := httptest.NewRequest(http.MethodPut, commentsURL+"/"+nonExistingCommentID, reader)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		test.CompareResponseCodes(t, rr.Result(), http.StatusNotFound)
+	})
+
+	t.Run("Hide Comments of deleted Todo", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodDelete, todoURL, http.NoBody)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		test.CompareResponseCodes(t, rr.Result(), http.StatusNoContent)
+
+		req = httptest.NewRequest(http.MethodGet, commentsURL, http.NoBody)
+		rr = httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		test.CompareResponseCodes(t, rr.Result(), http.StatusNotFound)
+
+		req = httptest.NewRequest(http.MethodPost, todoURL+"/restore", http.NoBody)
+		rr = httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		test.CompareResponseCodes(t, rr.Result(), http.StatusOK)
+
+		req = httptest.NewRequest(http.MethodGet, commentsURL, http.NoBody)
+		rr = httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusOK)
+
+		comments, _ := test.DecodeResponseBody(t, res).Data["comments"].([]any)
+		if len(comments) != 1 {
+			t.Errorf("expected comment of restored todo but was: %v", comments)
+		}
+	})
+
+	t.Run("Delete Comment", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodDelete, commentsURL+"/"+commentID, http.NoBody)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		test.CompareResponseCodes(t, rr.Result(), http.StatusNoContent)
+
+		req = httptest.NewRequest(http.MethodGet, commentsURL, http.NoBody)
+		rr = httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusOK)
+
+		expectedBodyBytes := []byte(`{"data":{"comments":[]}}`)
+		test.CompareResponseBodies(t, res, expectedBodyBytes)
+	})
+
+	t.Run("Delete deleted Comment", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodDelete, commentsURL+"/"+commentID, http.NoBody)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusNotFound)
+	})
+
+	t.Run("Delete Comment with invalid UUID", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodDelete, commentsURL+"/not-uuid", http.NoBody)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusBadRequest)
+	})
+}
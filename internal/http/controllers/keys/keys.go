@@ -0,0 +1,164 @@
+package keys
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+
+	"github.com/course-go/todos/internal/auth"
+	"github.com/course-go/todos/internal/http/dto/request"
+	"github.com/course-go/todos/internal/http/dto/response"
+	"github.com/course-go/todos/internal/http/exchange"
+	"github.com/course-go/todos/internal/repository"
+	"github.com/course-go/todos/internal/time"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+// mintedKey is the newly minted key together with its token. The token cannot be retrieved later.
+type mintedKey struct {
+	auth.Key
+
+	Token string `json:"token"`
+}
+
+type Controller struct {
+	logger     *slog.Logger
+	validator  *validator.Validate
+	repository repository.APIKeyRepository
+	time       time.Factory
+}
+
+func NewController(
+	logger *slog.Logger,
+	validator *validator.Validate,
+	repository repository.APIKeyRepository,
+	time time.Factory,
+) *Controller {
+	return &Controller{
+		logger:     logger.With("component", "http.controllers.keys"),
+		validator:  validator,
+		repository: repository,
+		time:       time,
+	}
+}
+
+// GetKeysController lists all API keys including the revoked ones. Their tokens are never listed.
+func (c *Controller) GetKeysController(w http.ResponseWriter, r *http.Request) {
+	keys, err := c.repository.GetAPIKeys(r.Context())
+	if err != nil {
+		c.logger.Error("failed retrieving api keys",
+			"error", err,
+		)
+
+		code := http.StatusInternalServerError
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	exchange.WriteData(w, c.logger, http.StatusOK, "keys", keys)
+}
+
+// CreateKeyController mints API key. The response is the only place its token is ever shown.
+func (c *Controller) CreateKeyController(w http.ResponseWriter, r *http.Request) {
+	var req request.CreateAPIKeyRequest
+
+	ok := exchange.BindRequest(w, r, c.logger, c.validator, &req)
+	if !ok {
+		return
+	}
+
+	now := c.time()
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(now) {
+		code := http.StatusBadRequest
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorMessageBytes(code, "expiresAt must be in the future"))
+
+		return
+	}
+
+	scopes := slices.Clone(req.Scopes)
+	slices.Sort(scopes)
+
+	token := auth.NewToken()
+	key := auth.Key{
+		Name:      req.Name,
+		Hash:      auth.Hash(token),
+		Scopes:    slices.Compact(scopes),
+		ExpiresAt: req.ExpiresAt,
+		CreatedAt: now,
+	}
+
+	key, err := c.repository.CreateAPIKey(r.Context(), key)
+	if err != nil {
+		c.logger.Error("failed creating api key",
+			"error", err,
+		)
+
+		code := http.StatusInternalServerError
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	c.logger.Info("minted api key",
+		"id", key.ID,
+		"name", key.Name,
+		"scopes", key.Scopes,
+	)
+
+	exchange.WriteData(w, c.logger, http.StatusCreated, "key", mintedKey{Key: key, Token: token})
+}
+
+// RevokeKeyController revokes API key so that it can no longer be used.
+func (c *Controller) RevokeKeyController(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		c.logger.Error("failed parsing uuid",
+			"uuid", r.PathValue("id"),
+			"error", err,
+		)
+
+		code := http.StatusBadRequest
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	err = c.repository.RevokeAPIKey(r.Context(), id, c.time())
+	if errors.Is(err, repository.ErrAPIKeyNotFound) {
+		c.logger.Debug("no matching id for api key",
+			"id", id,
+		)
+
+		code := http.StatusNotFound
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	if err != nil {
+		c.logger.Error("failed revoking api key",
+			"error", err,
+			"id", id,
+		)
+
+		code := http.StatusInternalServerError
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	c.logger.Info("revoked api key",
+		"id", id,
+	)
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,235 @@
+package keys_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/course-go/todos/internal/utils/test"
+	"github.com/google/go-cmp/cmp"
+)
+
+const (
+	apiURLPrefix = "/api/v1"
+
+	nonExistingKeyID = "be95c29a-c4dd-4d31-a5c4-d229f3374ab7"
+)
+
+func TestKeysControllers(t *testing.T) { //nolint: tparallel
+	t.Parallel()
+
+	ctx := t.Context()
+	logger := test.NewTestLogger(t)
+	r := test.NewTestAuthRouter(ctx, t, logger)
+
+	keysURL := apiURLPrefix + "/keys"
+	todosURL := apiURLPrefix + "/todos"
+
+	var keyID, token string
+
+	t.Run("Request without API key", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodGet, todosURL, http.NoBody)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusUnauthorized)
+
+		if challenge := res.Header.Get("WWW-Authenticate"); challenge != "Bearer" {
+			t.Errorf("challenge does not match: expected: Bearer != actual: %s", challenge)
+		}
+
+		expectedBodyBytes := []byte(`{"error":"Unauthorized"}`)
+		test.CompareResponseBodies(t, res, expectedBodyBytes)
+	})
+
+	t.Run("Request with invalid API key", func(t *testing.T) { //nolint: paralleltest
+		req := newRequest(http.MethodGet, todosURL, "todos_invalid", "")
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusUnauthorized)
+
+		expectedBodyBytes := []byte(`{"error":"Unauthorized"}`)
+		test.CompareResponseBodies(t, res, expectedBodyBytes)
+	})
+
+	t.Run("Get health without API key", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodGet, apiURLPrefix+"/healthz", http.NoBody)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		test.CompareResponseCodes(t, rr.Result(), http.StatusOK)
+	})
+
+	t.Run("Get empty Keys", func(t *testing.T) { //nolint: paralleltest
+		req := newRequest(http.MethodGet, keysURL, test.AdminKey, "")
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusOK)
+
+		expectedBodyBytes := []byte(`{"data":{"keys":[]}}`)
+		test.CompareResponseBodies(t, res, expectedBodyBytes)
+	})
+
+	t.Run("Create Key", func(t *testing.T) { //nolint: paralleltest
+		body := `{"name":"reader","scopes":["read","read"],"expiresAt":"2025-08-18T12:14:45Z"}`
+		req := newRequest(http.MethodPost, keysURL, test.AdminKey, body)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusCreated)
+
+		key, _ := test.DecodeResponseBody(t, res).Data["key"].(map[string]any)
+		keyID, _ = key["id"].(string)
+		token, _ = key["token"].(string)
+
+		expectedKey := map[string]any{
+			"id":        keyID,
+			"name":      "reader",
+			"scopes":    []any{"read"},
+			"expiresAt": "2025-08-18T12:14:45Z",
+			"createdAt": "2024-08-18T12:14:45.847679Z",
+			"token":     token,
+		}
+		if keyID == "" || !strings.HasPrefix(token, "todos_") || !cmp.Equal(expectedKey, key) {
+			t.Errorf("created key does not match: %s", cmp.Diff(expectedKey, key))
+		}
+	})
+
+	t.Run("Create Key with unknown scope", func(t *testing.T) { //nolint: paralleltest
+		req := newRequest(http.MethodPost, keysURL, test.AdminKey, `{"name":"reader","scopes":["delete"]}`)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		test.CompareResponseCodes(t, rr.Result(), http.StatusBadRequest)
+	})
+
+	t.Run("Create expired Key", func(t *testing.T) { //nolint: paralleltest
+		body := `{"name":"reader","scopes":["read"],"expiresAt":"2024-08-18T12:14:45Z"}`
+		req := newRequest(http.MethodPost, keysURL, test.AdminKey, body)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusBadRequest)
+
+		expectedBodyBytes := []byte(`{"error":"Bad Request: expiresAt must be in the future"}`)
+		test.CompareResponseBodies(t, res, expectedBodyBytes)
+	})
+
+	t.Run("Read Todos with Key", func(t *testing.T) { //nolint: paralleltest
+		req := newRequest(http.MethodGet, todosURL, token, "")
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		test.CompareResponseCodes(t, rr.Result(), http.StatusOK)
+	})
+
+	t.Run("Create Todo with Key lacking scope", func(t *testing.T) { //nolint: paralleltest
+		req := newRequest(http.MethodPost, todosURL, token, `{"description":"Water the plants"}`)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusForbidden)
+
+		expectedBodyBytes := []byte(`{"error":"Forbidden"}`)
+		test.CompareResponseBodies(t, res, expectedBodyBytes)
+	})
+
+	t.Run("Get Keys with Key lacking scope", func(t *testing.T) { //nolint: paralleltest
+		req := newRequest(http.MethodGet, keysURL, token, "")
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		test.CompareResponseCodes(t, rr.Result(), http.StatusForbidden)
+	})
+
+	t.Run("Get Keys", func(t *testing.T) { //nolint: paralleltest
+		req := newRequest(http.MethodGet, keysURL, test.AdminKey, "")
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusOK)
+
+		expectedBodyBytes := []byte(`{
+		   "data":{
+			  "keys":[
+				 {
+					"id":"` + keyID + `",
+					"name":"reader",
+					"scopes":["read"],
+					"expiresAt":"2025-08-18T12:14:45Z",
+					"lastUsedAt":"2024-08-18T12:14:45.847679Z",
+					"createdAt":"2024-08-18T12:14:45.847679Z"
+				 }
+			  ]
+		   }
+		}`)
+		test.CompareResponseBodies(t, res, expectedBodyBytes)
+	})
+
+	t.Run("Revoke Key", func(t *testing.T) { //nolint: paralleltest
+		req := newRequest(http.MethodDelete, keysURL+"/"+keyID, test.AdminKey, "")
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		test.CompareResponseCodes(t, rr.Result(), http.StatusNoContent)
+
+		req = newRequest(http.MethodGet, todosURL, token, "")
+		rr = httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		test.CompareResponseCodes(t, rr.Result(), http.StatusUnauthorized)
+	})
+
+	t.Run("Revoke revoked Key", func(t *testing.T) { //nolint: paralleltest
+		req := newRequest(http.MethodDelete, keysURL+"/"+keyID, test.AdminKey, "")
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		test.CompareResponseCodes(t, rr.Result(), http.StatusNotFound)
+	})
+
+	t.Run("Revoke non-existing Key", func(t *testing.T) { //nolint: paralleltest
+		req := newRequest(http.MethodDelete, keysURL+"/"+nonExistingKeyID, test.AdminKey, "")
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusNotFound)
+
+		expectedBodyBytes := []byte(`{"error":"Not Found"}`)
+		test.CompareResponseBodies(t, res, expectedBodyBytes)
+	})
+}
+
+// newRequest creates request authenticated with the token.
+func newRequest(method, url, token, body string) *http.Request {
+	req := httptest.NewRequest(method, url, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	return req
+}
@@ -0,0 +1,207 @@
+package lists
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/course-go/todos/internal/http/dto/request"
+	"github.com/course-go/todos/internal/http/dto/response"
+	"github.com/course-go/todos/internal/http/exchange"
+	"github.com/course-go/todos/internal/repository"
+	"github.com/course-go/todos/internal/time"
+	"github.com/course-go/todos/internal/todos"
+	"github.com/go-playground/validator/v10"
+)
+
+type Controller struct {
+	logger     *slog.Logger
+	validator  *validator.Validate
+	repository repository.ListRepository
+	time       time.Factory
+}
+
+func NewController(
+	logger *slog.Logger,
+	validator *validator.Validate,
+	repository repository.ListRepository,
+	time time.Factory,
+) *Controller {
+	return &Controller{
+		logger:     logger.With("component", "http.controllers.lists"),
+		validator:  validator,
+		repository: repository,
+		time:       time,
+	}
+}
+
+func (c *Controller) GetListsController(w http.ResponseWriter, r *http.Request) {
+	lists, err := c.repository.GetLists(r.Context())
+	if err != nil {
+		c.logger.Error("failed retrieving lists",
+			"error", err,
+		)
+
+		code := http.StatusInternalServerError
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	exchange.WriteData(w, c.logger, http.StatusOK, "lists", lists)
+}
+
+func (c *Controller) GetListController(w http.ResponseWriter, r *http.Request) {
+	id, ok := exchange.ParseID(w, r, c.logger, "id")
+	if !ok {
+		return
+	}
+
+	list, err := c.repository.GetList(r.Context(), id)
+	if errors.Is(err, repository.ErrListNotFound) {
+		c.logger.Debug("no matching id for list",
+			"id", id,
+		)
+
+		code := http.StatusNotFound
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	if err != nil {
+		c.logger.Error("failed retrieving list",
+			"error", err,
+			"id", id,
+		)
+
+		code := http.StatusInternalServerError
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	exchange.WriteData(w, c.logger, http.StatusOK, "list", list)
+}
+
+func (c *Controller) CreateListController(w http.ResponseWriter, r *http.Request) {
+	var req request.CreateListRequest
+
+	ok := exchange.BindRequest(w, r, c.logger, c.validator, &req)
+	if !ok {
+		return
+	}
+
+	list := todos.List{
+		Name:      req.Name,
+		Color:     req.Color,
+		Archived:  req.Archived,
+		CreatedAt: c.time(),
+	}
+
+	list, err := c.repository.CreateList(r.Context(), list)
+	if err != nil {
+		c.logger.Error("failed creating list",
+			"error", err,
+		)
+
+		code := http.StatusInternalServerError
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	exchange.WriteData(w, c.logger, http.StatusCreated, "list", list)
+}
+
+func (c *Controller) UpdateListController(w http.ResponseWriter, r *http.Request) {
+	id, ok := exchange.ParseID(w, r, c.logger, "id")
+	if !ok {
+		return
+	}
+
+	var req request.UpdateListRequest
+
+	ok = exchange.BindRequest(w, r, c.logger, c.validator, &req)
+	if !ok {
+		return
+	}
+
+	now := c.time()
+	list := todos.List{
+		ID:        id,
+		Name:      req.Name,
+		Color:     req.Color,
+		Archived:  req.Archived,
+		UpdatedAt: &now,
+	}
+
+	list, err := c.repository.SaveList(r.Context(), list)
+	if errors.Is(err, repository.ErrListNotFound) {
+		c.logger.Debug("no matching id for list",
+			"id", id,
+		)
+
+		code := http.StatusNotFound
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	if err != nil {
+		c.logger.Error("failed saving list",
+			"error", err,
+			"id", id,
+		)
+
+		code := http.StatusInternalServerError
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	exchange.WriteData(w, c.logger, http.StatusOK, "list", list)
+}
+
+// DeleteListController deletes the list together with all of its todos.
+// The todos are moved to trash so they can still be restored without the list.
+func (c *Controller) DeleteListController(w http.ResponseWriter, r *http.Request) {
+	id, ok := exchange.ParseID(w, r, c.logger, "id")
+	if !ok {
+		return
+	}
+
+	err := c.repository.DeleteList(r.Context(), id, c.time())
+	if errors.Is(err, repository.ErrListNotFound) {
+		c.logger.Debug("no matching id for list",
+			"id", id,
+		)
+
+		code := http.StatusNotFound
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	if err != nil {
+		c.logger.Error("failed deleting list",
+			"error", err,
+			"id", id,
+		)
+
+		code := http.StatusInternalServerError
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
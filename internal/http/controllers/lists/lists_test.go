@@ -0,0 +1,218 @@
+package lists_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/course-go/todos/internal/utils/test"
+	"github.com/google/go-cmp/cmp"
+)
+
+const (
+	apiURLPrefix = "/api/v1"
+
+	nonExistingListID = "be95c29a-c4dd-4d31-a5c4-d229f3374ab7"
+)
+
+func TestListsControllers(t *testing.T) { //nolint: tparallel
+	t.Parallel()
+
+	ctx := t.Context()
+	logger := test.NewTestLogger(t)
+	r := test.NewTestRouter(ctx, t, logger)
+
+	var listID string
+
+	t.Run("Get empty Lists", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodGet, apiURLPrefix+"/lists", http.NoBody)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusOK)
+
+		expectedBodyBytes := []byte(`{"data":{"lists":[]}}`)
+		test.CompareResponseBodies(t, res, expectedBodyBytes)
+	})
+
+	t.Run("Create List", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(
+			http.MethodPost,
+			apiURLPrefix+"/lists",
+			strings.NewReader(`{"name":"Garden","color":"#228b22"}`),
+		)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusCreated)
+
+		body := test.DecodeResponseBody(t, res)
+
+		list, _ := body.Data["list"].(map[string]any)
+		listID, _ = list["id"].(string)
+
+		expectedList := map[string]any{
+			"id":        listID,
+			"name":      "Garden",
+			"color":     "#228b22",
+			"archived":  false,
+			"createdAt": "2024-08-18T12:14:45.847679Z",
+		}
+		if listID == "" || !cmp.Equal(expectedList, list) {
+			t.Errorf("created list does not match: %s", cmp.Diff(expectedList, list))
+		}
+	})
+
+	t.Run("Create List with invalid body", func(t *testing.T) { //nolint: paralleltest
+		for _, body := range []string{`{}`, `{"name":"Garden","color":"green"}`, `{"name":`} {
+			req := httptest.NewRequest(http.MethodPost, apiURLPrefix+"/lists", strings.NewReader(body))
+			rr := httptest.NewRecorder()
+
+			r.ServeHTTP(rr, req)
+
+			res := rr.Result()
+			test.CompareResponseCodes(t, res, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("Archive List", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(
+			http.MethodPut,
+			apiURLPrefix+"/lists/"+listID,
+			strings.NewReader(`{"name":"Backyard","archived":true}`),
+		)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusOK)
+
+		expectedBodyBytes := []byte(`{
+		   "data":{
+			  "list":{
+				 "id":"` + listID + `",
+				 "name":"Backyard",
+				 "archived":true,
+				 "createdAt":"2024-08-18T12:14:45.847679Z",
+				 "updatedAt":"2024-08-18T12:14:45.847679Z"
+			  }
+		   }
+		}`)
+		test.CompareResponseBodies(t, res, expectedBodyBytes)
+	})
+
+	t.Run("Update non-existing List", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(
+			http.MethodPut,
+			apiURLPrefix+"/lists/"+nonExistingListID,
+			strings.NewReader(`{"name":"Backyard"}`),
+		)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusNotFound)
+	})
+
+	t.Run("Get List Todos", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(
+			http.MethodPost,
+			apiURLPrefix+"/todos",
+			strings.NewReader(`{"description":"Water the plants","listId":"`+listID+`"}`),
+		)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusCreated)
+
+		req = httptest.NewRequest(http.MethodGet, apiURLPrefix+"/lists/"+listID+"/todos", http.NoBody)
+		rr = httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res = rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusOK)
+
+		todos, _ := test.DecodeResponseBody(t, res).Data["todos"].([]any)
+		if len(todos) != 1 {
+			t.Fatalf("expected 1 todo in list but was: %v", todos)
+		}
+
+		todo, _ := todos[0].(map[string]any)
+		if todo["description"] != "Water the plants" || todo["listId"] != listID {
+			t.Errorf("expected todo in list but was: %v", todo)
+		}
+	})
+
+	t.Run("Get non-existing List Todos", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodGet, apiURLPrefix+"/lists/"+nonExistingListID+"/todos", http.NoBody)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusNotFound)
+
+		expectedBodyBytes := []byte(`{"error":"Not Found"}`)
+		test.CompareResponseBodies(t, res, expectedBodyBytes)
+	})
+
+	t.Run("Delete List", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodDelete, apiURLPrefix+"/lists/"+listID, http.NoBody)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusNoContent)
+
+		req = httptest.NewRequest(http.MethodGet, apiURLPrefix+"/todos/trash", http.NoBody)
+		rr = httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res = rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusOK)
+
+		var trashed bool
+
+		todos, _ := test.DecodeResponseBody(t, res).Data["todos"].([]any)
+		for _, todo := range todos {
+			todo, _ := todo.(map[string]any)
+			trashed = trashed || todo["description"] == "Water the plants"
+		}
+
+		if !trashed {
+			t.Errorf("expected list todo in trash but was: %v", todos)
+		}
+	})
+
+	t.Run("Get deleted List", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodGet, apiURLPrefix+"/lists/"+listID, http.NoBody)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusNotFound)
+	})
+
+	t.Run("Delete non-existing List", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodDelete, apiURLPrefix+"/lists/"+listID, http.NoBody)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusNotFound)
+	})
+}
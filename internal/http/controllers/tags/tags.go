@@ -0,0 +1,215 @@
+package tags
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/course-go/todos/internal/http/dto/request"
+	"github.com/course-go/todos/internal/http/dto/response"
+	"github.com/course-go/todos/internal/http/exchange"
+	"github.com/course-go/todos/internal/repository"
+	"github.com/course-go/todos/internal/time"
+	"github.com/course-go/todos/internal/todos"
+	"github.com/go-playground/validator/v10"
+)
+
+type Controller struct {
+	logger     *slog.Logger
+	validator  *validator.Validate
+	repository repository.TagRepository
+	time       time.Factory
+}
+
+func NewController(
+	logger *slog.Logger,
+	validator *validator.Validate,
+	repository repository.TagRepository,
+	time time.Factory,
+) *Controller {
+	return &Controller{
+		logger:     logger.With("component", "http.controllers.tags"),
+		validator:  validator,
+		repository: repository,
+		time:       time,
+	}
+}
+
+func (c *Controller) GetTagsController(w http.ResponseWriter, r *http.Request) {
+	tags, err := c.repository.GetTags(r.Context())
+	if err != nil {
+		c.logger.Error("failed retrieving tags",
+			"error", err,
+		)
+
+		code := http.StatusInternalServerError
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	exchange.WriteData(w, c.logger, http.StatusOK, "tags", tags)
+}
+
+func (c *Controller) GetTagController(w http.ResponseWriter, r *http.Request) {
+	id, ok := exchange.ParseID(w, r, c.logger, "id")
+	if !ok {
+		return
+	}
+
+	tag, err := c.repository.GetTag(r.Context(), id)
+	if errors.Is(err, repository.ErrTagNotFound) {
+		c.logger.Debug("no matching id for tag",
+			"id", id,
+		)
+
+		code := http.StatusNotFound
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	if err != nil {
+		c.logger.Error("failed retrieving tag",
+			"error", err,
+			"id", id,
+		)
+
+		code := http.StatusInternalServerError
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	exchange.WriteData(w, c.logger, http.StatusOK, "tag", tag)
+}
+
+func (c *Controller) CreateTagController(w http.ResponseWriter, r *http.Request) {
+	var req request.CreateTagRequest
+
+	ok := exchange.BindRequest(w, r, c.logger, c.validator, &req)
+	if !ok {
+		return
+	}
+
+	tag, err := c.repository.CreateTag(r.Context(), todos.Tag{Name: req.Name})
+	if errors.Is(err, repository.ErrTagExists) {
+		c.logger.Debug("tag already exists",
+			"name", req.Name,
+		)
+
+		code := http.StatusConflict
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	if err != nil {
+		c.logger.Error("failed creating tag",
+			"error", err,
+		)
+
+		code := http.StatusInternalServerError
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	exchange.WriteData(w, c.logger, http.StatusCreated, "tag", tag)
+}
+
+// UpdateTagController renames the tag. The new name is reflected by all todos labeled with the tag.
+func (c *Controller) UpdateTagController(w http.ResponseWriter, r *http.Request) {
+	id, ok := exchange.ParseID(w, r, c.logger, "id")
+	if !ok {
+		return
+	}
+
+	var req request.UpdateTagRequest
+
+	ok = exchange.BindRequest(w, r, c.logger, c.validator, &req)
+	if !ok {
+		return
+	}
+
+	tag, err := c.repository.SaveTag(r.Context(), todos.Tag{ID: id, Name: req.Name}, c.time())
+	if errors.Is(err, repository.ErrTagNotFound) {
+		c.logger.Debug("no matching id for tag",
+			"id", id,
+		)
+
+		code := http.StatusNotFound
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	if errors.Is(err, repository.ErrTagExists) {
+		c.logger.Debug("tag already exists",
+			"name", req.Name,
+		)
+
+		code := http.StatusConflict
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	if err != nil {
+		c.logger.Error("failed saving tag",
+			"error", err,
+			"id", id,
+		)
+
+		code := http.StatusInternalServerError
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	exchange.WriteData(w, c.logger, http.StatusOK, "tag", tag)
+}
+
+// DeleteTagController deletes the tag and removes it from all todos.
+func (c *Controller) DeleteTagController(w http.ResponseWriter, r *http.Request) {
+	id, ok := exchange.ParseID(w, r, c.logger, "id")
+	if !ok {
+		return
+	}
+
+	err := c.repository.DeleteTag(r.Context(), id, c.time())
+	if errors.Is(err, repository.ErrTagNotFound) {
+		c.logger.Debug("no matching id for tag",
+			"id", id,
+		)
+
+		code := http.StatusNotFound
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	if err != nil {
+		c.logger.Error("failed deleting tag",
+			"error", err,
+			"id", id,
+		)
+
+		code := http.StatusInternalServerError
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
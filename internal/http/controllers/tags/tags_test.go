@@ -0,0 +1,217 @@
+package tags_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/course-go/todos/internal/utils/test"
+)
+
+const (
+	apiURLPrefix = "/api/v1"
+
+	homeTagID   = "c5f3a5e2-1d4b-4c8e-9a51-7f2b8d3e6a10"
+	workTagID   = "0b9e4d7a-5c2f-4e61-8d3a-2f6c1b7e9d42"
+	urgentTagID = "8e2d6c1f-3a7b-4d95-b0c4-5e9f2a8d1c37"
+
+	vacuumTodoID = "f52bad23-c201-414e-9bdb-af4327c42aa7"
+)
+
+func TestTagsControllers(t *testing.T) { //nolint: tparallel
+	t.Parallel()
+
+	ctx := t.Context()
+	logger := test.NewTestLogger(t)
+	r := test.NewTestRouter(ctx, t, logger)
+
+	t.Run("Get Tags", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodGet, apiURLPrefix+"/tags", http.NoBody)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusOK)
+
+		expectedBodyBytes := []byte(`{
+		   "data":{
+			  "tags":[
+				 {"id":"` + homeTagID + `","name":"home"},
+				 {"id":"` + urgentTagID + `","name":"urgent"},
+				 {"id":"` + workTagID + `","name":"work"}
+			  ]
+		   }
+		}`)
+		test.CompareResponseBodies(t, res, expectedBodyBytes)
+	})
+
+	t.Run("Get existing Tag", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodGet, apiURLPrefix+"/tags/"+workTagID, http.NoBody)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusOK)
+
+		expectedBodyBytes := []byte(`{"data":{"tag":{"id":"` + workTagID + `","name":"work"}}}`)
+		test.CompareResponseBodies(t, res, expectedBodyBytes)
+	})
+
+	t.Run("Get non-existing Tag", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(
+			http.MethodGet,
+			apiURLPrefix+"/tags/be95c29a-c4dd-4d31-a5c4-d229f3374ab7",
+			http.NoBody,
+		)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusNotFound)
+
+		expectedBodyBytes := []byte(`{"error":"Not Found"}`)
+		test.CompareResponseBodies(t, res, expectedBodyBytes)
+	})
+
+	t.Run("Create Tag", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodPost, apiURLPrefix+"/tags", strings.NewReader(`{"name":"garden"}`))
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusCreated)
+
+		body := test.DecodeResponseBody(t, res)
+
+		tag, ok := body.Data["tag"].(map[string]any)
+		if !ok || tag["name"] != "garden" || tag["id"] == "" {
+			t.Errorf("expected created garden tag but was: %v", body)
+		}
+	})
+
+	t.Run("Create existing Tag", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodPost, apiURLPrefix+"/tags", strings.NewReader(`{"name":"home"}`))
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusConflict)
+
+		expectedBodyBytes := []byte(`{"error":"Conflict"}`)
+		test.CompareResponseBodies(t, res, expectedBodyBytes)
+	})
+
+	t.Run("Create Tag with invalid body", func(t *testing.T) { //nolint: paralleltest
+		for _, body := range []string{`{}`, `{"name":"home,work"}`, `{"name":`} {
+			req := httptest.NewRequest(http.MethodPost, apiURLPrefix+"/tags", strings.NewReader(body))
+			rr := httptest.NewRecorder()
+
+			r.ServeHTTP(rr, req)
+
+			res := rr.Result()
+			test.CompareResponseCodes(t, res, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("Rename Tag", func(t *testing.T) { //nolint: paralleltest
+		previousETag := getTodoETag(t, r, vacuumTodoID)
+
+		req := httptest.NewRequest(
+			http.MethodPut,
+			apiURLPrefix+"/tags/"+homeTagID,
+			strings.NewReader(`{"name":"household"}`),
+		)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusOK)
+
+		expectedBodyBytes := []byte(`{"data":{"tag":{"id":"` + homeTagID + `","name":"household"}}}`)
+		test.CompareResponseBodies(t, res, expectedBodyBytes)
+
+		req = httptest.NewRequest(http.MethodGet, apiURLPrefix+"/todos?tag=household", http.NoBody)
+		rr = httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res = rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusOK)
+
+		todos, _ := test.DecodeResponseBody(t, res).Data["todos"].([]any)
+		if len(todos) != 2 {
+			t.Errorf("expected 2 todos with renamed tag but was: %v", todos)
+		}
+
+		if etag := getTodoETag(t, r, vacuumTodoID); etag == previousETag {
+			t.Errorf("entity tag of tagged todo should change: %s", etag)
+		}
+	})
+
+	t.Run("Rename Tag to existing name", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(
+			http.MethodPut,
+			apiURLPrefix+"/tags/"+homeTagID,
+			strings.NewReader(`{"name":"work"}`),
+		)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusConflict)
+	})
+
+	t.Run("Delete Tag", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodDelete, apiURLPrefix+"/tags/"+urgentTagID, http.NoBody)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusNoContent)
+
+		req = httptest.NewRequest(http.MethodGet, apiURLPrefix+"/todos?tag=urgent", http.NoBody)
+		rr = httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res = rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusOK)
+
+		expectedBodyBytes := []byte(`{"data":{"todos":[]}}`)
+		test.CompareResponseBodies(t, res, expectedBodyBytes)
+	})
+
+	t.Run("Delete non-existing Tag", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodDelete, apiURLPrefix+"/tags/"+urgentTagID, http.NoBody)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		test.CompareResponseCodes(t, res, http.StatusNotFound)
+	})
+}
+
+// getTodoETag returns the entity tag of the todo.
+func getTodoETag(t *testing.T, r http.Handler, id string) string {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, apiURLPrefix+"/todos/"+id, http.NoBody)
+	rr := httptest.NewRecorder()
+
+	r.ServeHTTP(rr, req)
+
+	res := rr.Result()
+	test.CompareResponseCodes(t, res, http.StatusOK)
+
+	return res.Header.Get("ETag")
+}
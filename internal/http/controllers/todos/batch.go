@@ -0,0 +1,192 @@
+package todos
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+
+	"github.com/course-go/todos/internal/http/dto/request"
+	"github.com/course-go/todos/internal/http/dto/response"
+	"github.com/course-go/todos/internal/repository"
+	"github.com/course-go/todos/internal/todos"
+)
+
+const bestEffortBatchMode = "bestEffort"
+
+func (c *Controller) BatchTodosController(w http.ResponseWriter, r *http.Request) {
+	body := r.Body
+
+	bodyBytes, err := io.ReadAll(body)
+	if err != nil {
+		c.logger.Error("failed reading request body",
+			"error", err,
+		)
+
+		code := http.StatusInternalServerError
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	defer func() {
+		_ = body.Close()
+	}()
+
+	var req request.BatchRequest
+
+	err = json.Unmarshal(bodyBytes, &req)
+	if err != nil {
+		c.logger.Error("failed binding request body",
+			"error", err,
+		)
+
+		code := http.StatusBadRequest
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	err = c.validator.Struct(req)
+	if err != nil {
+		c.logger.Warn("failed validating request body",
+			"error", err,
+		)
+
+		code := http.StatusBadRequest
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	operations := make([]repository.BatchOperation, 0, len(req.Operations))
+	for _, operation := range req.Operations {
+		operations = append(operations, batchOperation(operation))
+	}
+
+	atomic := req.Mode != bestEffortBatchMode
+
+	results, err := c.repository.ExecuteBatch(r.Context(), operations, atomic, c.time())
+	if err != nil {
+		c.logger.Error("failed executing batch",
+			"error", err,
+		)
+
+		code := http.StatusInternalServerError
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	code, bytes, err := batchResponse(operations, results, atomic)
+	if err != nil {
+		c.logger.Error("failed constructing response",
+			"error", err,
+		)
+
+		code := http.StatusInternalServerError
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	w.WriteHeader(code)
+	_, _ = w.Write(bytes)
+}
+
+// batchResponse describes results of the operations. Failed atomic batch changed nothing,
+// so the whole request fails with the index of the failed operation.
+func batchResponse(
+	operations []repository.BatchOperation,
+	results []repository.BatchResult,
+	atomic bool,
+) (code int, bytes []byte, err error) {
+	batchResults := make([]response.BatchResult, 0, len(results))
+	for i, result := range results {
+		batchResults = append(batchResults, batchResult(operations[i], result))
+	}
+
+	failed := slices.IndexFunc(results, func(result repository.BatchResult) bool {
+		return result.Err != nil && !errors.Is(result.Err, repository.ErrBatchAborted)
+	})
+	if !atomic || failed < 0 {
+		code = http.StatusOK
+		bytes, err = response.DataBytes("results", batchResults)
+	} else {
+		code = http.StatusUnprocessableEntity
+		bytes, err = response.ErrorDataBytes(code, fmt.Sprintf("operation %d failed", failed), map[string]any{
+			"failedIndex": failed,
+			"results":     batchResults,
+		})
+	}
+
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed encoding batch results: %w", err)
+	}
+
+	return code, bytes, nil
+}
+
+func batchOperation(req request.BatchOperationRequest) repository.BatchOperation {
+	operation := repository.BatchOperation{
+		Kind: repository.BatchOperationKind(req.Op),
+		Todo: todos.Todo{
+			Description: req.Description,
+			ListID:      req.ListID,
+			ParentID:    req.ParentID,
+			Priority:    req.Priority,
+			DueAt:       req.DueAt,
+			Recurrence:  req.Recurrence,
+			Tags:        req.Tags,
+			CompletedAt: req.CompletedAt,
+		},
+	}
+	if req.ID != nil {
+		operation.Todo.ID = *req.ID
+	}
+
+	return operation
+}
+
+func batchResult(operation repository.BatchOperation, result repository.BatchResult) response.BatchResult {
+	if result.Err != nil {
+		code := batchErrorCode(result.Err)
+
+		return response.BatchResult{
+			Status: code,
+			Error:  http.StatusText(code),
+		}
+	}
+
+	switch operation.Kind {
+	case repository.BatchCreate:
+		return response.BatchResult{Status: http.StatusCreated, Todo: &result.Todo}
+	case repository.BatchDelete:
+		return response.BatchResult{Status: http.StatusNoContent}
+	case repository.BatchUpdate:
+	}
+
+	return response.BatchResult{Status: http.StatusOK, Todo: &result.Todo}
+}
+
+func batchErrorCode(err error) int {
+	switch {
+	case errors.Is(err, repository.ErrTodoNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, repository.ErrListNotFound),
+		errors.Is(err, repository.ErrParentNotFound),
+		errors.Is(err, repository.ErrParentCycle):
+		return http.StatusBadRequest
+	case errors.Is(err, repository.ErrBatchAborted):
+		return http.StatusFailedDependency
+	default:
+		return http.StatusInternalServerError
+	}
+}
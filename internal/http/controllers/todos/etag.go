@@ -0,0 +1,105 @@
+package todos
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/course-go/todos/internal/todos"
+)
+
+// entityTag returns strong entity tag of the todo derived from its version.
+// Progress of subtasks changes without changing the version so it is part of the tag as well.
+func entityTag(todo todos.Todo) string {
+	if todo.Progress == nil {
+		return `"` + strconv.Itoa(todo.Version) + `"`
+	}
+
+	return `"` + strconv.Itoa(todo.Version) +
+		"." + strconv.Itoa(todo.Progress.Completed) +
+		"." + strconv.Itoa(todo.Progress.Total) + `"`
+}
+
+// bodyEntityTag returns strong entity tag derived from the response body.
+func bodyEntityTag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// lastModified returns time of the latest change of the todo.
+func lastModified(todo todos.Todo) time.Time {
+	if todo.UpdatedAt != nil {
+		return *todo.UpdatedAt
+	}
+
+	return todo.CreatedAt
+}
+
+// matchesIfMatch reports whether the entity tag satisfies If-Match header value.
+// Weak entity tags never match as If-Match requires strong comparison.
+func matchesIfMatch(header, etag string) bool {
+	for tag := range strings.SplitSeq(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchesIfNoneMatch reports whether the entity tag is listed in If-None-Match header value.
+// If-None-Match uses weak comparison so the weakness indicators are ignored.
+func matchesIfNoneMatch(header, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for tag := range strings.SplitSeq(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// notModified evaluates conditional GET request headers as described by RFC 9110.
+// If-Modified-Since is only evaluated if the request has no If-None-Match header.
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	ifNoneMatch := r.Header.Get("If-None-Match")
+	if ifNoneMatch != "" {
+		return matchesIfNoneMatch(ifNoneMatch, etag)
+	}
+
+	if lastModified.IsZero() {
+		return false
+	}
+
+	ifModifiedSince, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+
+	// HTTP dates have only second precision.
+	return !lastModified.Truncate(time.Second).After(ifModifiedSince)
+}
+
+// writeCacheable writes the body with its validators unless
+// the client already has the same representation cached.
+// Zero lastModified means that the modification time is unknown.
+func writeCacheable(w http.ResponseWriter, r *http.Request, body []byte, etag string, lastModified time.Time) {
+	w.Header().Set("ETag", etag)
+
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if notModified(r, etag, lastModified) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	_, _ = w.Write(body)
+}
@@ -0,0 +1,60 @@
+package todos
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/course-go/todos/internal/http/dto/response"
+	"github.com/course-go/todos/internal/http/exchange"
+	"github.com/course-go/todos/internal/repository"
+)
+
+// GetTodoHistoryController lists changes of the todo from the oldest one.
+func (c *Controller) GetTodoHistoryController(w http.ResponseWriter, r *http.Request) {
+	id, ok := exchange.ParseID(w, r, c.logger, "id")
+	if !ok {
+		return
+	}
+
+	events, err := c.repository.GetTodoEvents(r.Context(), id)
+	if errors.Is(err, repository.ErrTodoNotFound) {
+		c.logger.Warn("todo with given id does not exist",
+			"error", err,
+			"id", id,
+		)
+
+		code := http.StatusNotFound
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	if err != nil {
+		c.logger.Error("failed retrieving todo history",
+			"error", err,
+			"id", id,
+		)
+
+		code := http.StatusInternalServerError
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	bytes, err := response.DataBytes("events", events)
+	if err != nil {
+		c.logger.Error("failed constructing response",
+			"error", err,
+		)
+
+		code := http.StatusInternalServerError
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	_, _ = w.Write(bytes)
+}
@@ -0,0 +1,107 @@
+package todos_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/course-go/todos/internal/http/middleware"
+	"github.com/course-go/todos/internal/utils/test"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTodoHistoryController(t *testing.T) { //nolint: tparallel
+	t.Parallel()
+
+	ctx := t.Context()
+	logger := test.NewTestLogger(t)
+	r := test.NewTestRouter(ctx, t, logger)
+
+	var todoURL string
+
+	t.Run("Change Todo", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(
+			http.MethodPost,
+			apiURLPrefix+"/todos",
+			strings.NewReader(`{"description":"Water plants"}`),
+		)
+		req.Header.Set(middleware.ActorHeader, "alex")
+
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		compareResponseCodes(t, res, http.StatusCreated)
+
+		todo, _ := decodeResponseBody(t, res).Data["todo"].(map[string]any)
+		id, _ := todo["id"].(string)
+		todoURL = apiURLPrefix + "/todos/" + id
+
+		req = httptest.NewRequest(http.MethodPut, todoURL, strings.NewReader(`{"description":"Water all plants"}`))
+		rr = httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		compareResponseCodes(t, rr.Result(), http.StatusOK)
+
+		req = httptest.NewRequest(http.MethodPost, todoURL+"/complete", http.NoBody)
+		req.Header.Set(middleware.ActorHeader, "sam")
+
+		rr = httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		compareResponseCodes(t, rr.Result(), http.StatusOK)
+	})
+
+	t.Run("Get Todo history", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodGet, todoURL+"/history", http.NoBody)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		compareResponseCodes(t, res, http.StatusOK)
+
+		events, _ := decodeResponseBody(t, res).Data["events"].([]any)
+
+		var changes []string
+
+		for _, event := range events {
+			event, _ := event.(map[string]any)
+			after, _ := event["after"].(map[string]any)
+			change, _ := event["type"].(string)
+			actor, _ := event["actor"].(string)
+			description, _ := after["description"].(string)
+			changes = append(changes, change+" by "+actor+": "+description)
+		}
+
+		expectedChanges := []string{
+			"created by alex: Water plants",
+			"updated by anonymous: Water all plants",
+			"completed by sam: Water all plants",
+		}
+		if !cmp.Equal(expectedChanges, changes) {
+			t.Errorf("history does not match: %s", cmp.Diff(expectedChanges, changes))
+		}
+	})
+
+	t.Run("Get history of non-existing Todo", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(
+			http.MethodGet,
+			apiURLPrefix+"/todos/be95c29a-c4dd-4d31-a5c4-d229f3374ab7/history",
+			http.NoBody,
+		)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		compareResponseCodes(t, res, http.StatusNotFound)
+
+		expectedBodyBytes := []byte(`{"error":"Not Found"}`)
+		compareResponseBodies(t, res, expectedBodyBytes)
+	})
+}
@@ -0,0 +1,132 @@
+package todos_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/course-go/todos/internal/utils/test"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMoveTodoController(t *testing.T) { //nolint: tparallel
+	t.Parallel()
+
+	ctx := t.Context()
+	logger := test.NewTestLogger(t)
+	r := test.NewTestRouter(ctx, t, logger)
+
+	vacuumTodoID := "f52bad23-c201-414e-9bdb-af4327c42aa7"
+
+	t.Run("Move Todo before other Todo", func(t *testing.T) { //nolint: paralleltest
+		reader := strings.NewReader(`{"before":"` + mopTodoID + `"}`)
+		req := httptest.NewRequest(http.MethodPost, apiURLPrefix+"/todos/"+vacuumTodoID+"/move", reader)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		compareResponseCodes(t, res, http.StatusOK)
+
+		expectedBodyBytes := []byte(`{
+		   "data":{
+			  "todo":{
+				 "id":"f52bad23-c201-414e-9bdb-af4327c42aa7",
+				 "description":"Vacuum",
+				 "priority":"high",
+				 "dueAt":"2024-07-28T12:00:00Z",
+				 "tags":["home","urgent"],
+				 "position":"1",
+				 "createdAt":"2024-07-26T22:49:47.366006Z",
+				 "completedAt":"2024-07-27T22:50:19.594495Z",
+				 "updatedAt":"2024-08-18T12:14:45.847679Z"
+			  }
+		   }
+		}`)
+		compareResponseBodies(t, res, expectedBodyBytes)
+		assertJSONContentType(t, res)
+
+		req = httptest.NewRequest(http.MethodGet, apiURLPrefix+"/todos", http.NoBody)
+		rr = httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res = rr.Result()
+		compareResponseCodes(t, res, http.StatusOK)
+
+		var ids []any
+
+		retrievedTodos, _ := decodeResponseBody(t, res).Data["todos"].([]any)
+		for _, todo := range retrievedTodos {
+			todo, _ := todo.(map[string]any)
+			ids = append(ids, todo["id"])
+		}
+
+		expectedIDs := []any{vacuumTodoID, mopTodoID}
+		if !cmp.Equal(expectedIDs, ids) {
+			t.Fatalf("todos order does not match: %s", cmp.Diff(expectedIDs, ids))
+		}
+	})
+
+	t.Run("Move Todo without neighbors", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(
+			http.MethodPost,
+			apiURLPrefix+"/todos/"+vacuumTodoID+"/move",
+			strings.NewReader(`{}`),
+		)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		compareResponseCodes(t, rr.Result(), http.StatusBadRequest)
+	})
+
+	t.Run("Move Todo next to itself", func(t *testing.T) { //nolint: paralleltest
+		reader := strings.NewReader(`{"after":"` + vacuumTodoID + `"}`)
+		req := httptest.NewRequest(http.MethodPost, apiURLPrefix+"/todos/"+vacuumTodoID+"/move", reader)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		compareResponseCodes(t, res, http.StatusBadRequest)
+
+		expectedBodyBytes := []byte(`{
+			"error":"Bad Request: todo has to be moved next to other todos and after has to precede before"
+		}`)
+		compareResponseBodies(t, res, expectedBodyBytes)
+	})
+
+	t.Run("Move Todo next to non-existing Todo", func(t *testing.T) { //nolint: paralleltest
+		reader := strings.NewReader(`{"after":"be95c29a-c4dd-4d31-a5c4-d229f3374ab7"}`)
+		req := httptest.NewRequest(http.MethodPost, apiURLPrefix+"/todos/"+vacuumTodoID+"/move", reader)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		compareResponseCodes(t, res, http.StatusBadRequest)
+
+		expectedBodyBytes := []byte(`{"error":"Bad Request: neighbor todo does not exist"}`)
+		compareResponseBodies(t, res, expectedBodyBytes)
+	})
+
+	t.Run("Move non-existing Todo", func(t *testing.T) { //nolint: paralleltest
+		reader := strings.NewReader(`{"after":"` + mopTodoID + `"}`)
+		req := httptest.NewRequest(
+			http.MethodPost,
+			apiURLPrefix+"/todos/be95c29a-c4dd-4d31-a5c4-d229f3374ab7/move",
+			reader,
+		)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		compareResponseCodes(t, res, http.StatusNotFound)
+
+		expectedBodyBytes := []byte(`{"error":"Not Found"}`)
+		compareResponseBodies(t, res, expectedBodyBytes)
+	})
+}
@@ -0,0 +1,106 @@
+package todos
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+
+	"github.com/course-go/todos/internal/http/dto/request"
+	"github.com/course-go/todos/internal/todos"
+)
+
+const mergePatchContentType = "application/merge-patch+json"
+
+// patchTodo applies merge patch to the editable fields of the todo and validates the result.
+func (c *Controller) patchTodo(todo todos.Todo, patch []byte) (req request.UpdateTodoRequest, err error) {
+	document, err := json.Marshal(request.UpdateTodoRequest{
+		Description: todo.Description,
+		ListID:      todo.ListID,
+		ParentID:    todo.ParentID,
+		Priority:    todo.Priority,
+		DueAt:       todo.DueAt,
+		Recurrence:  todo.Recurrence,
+		Tags:        todo.Tags,
+		CompletedAt: todo.CompletedAt,
+	})
+	if err != nil {
+		return request.UpdateTodoRequest{}, fmt.Errorf("failed marshaling todo: %w", err)
+	}
+
+	patched, err := applyMergePatch(document, patch)
+	if err != nil {
+		return request.UpdateTodoRequest{}, err
+	}
+
+	err = unmarshalStrict(patched, &req)
+	if err != nil {
+		return request.UpdateTodoRequest{}, fmt.Errorf("failed binding patched todo: %w", err)
+	}
+
+	err = c.validator.Struct(req)
+	if err != nil {
+		return request.UpdateTodoRequest{}, fmt.Errorf("failed validating patched todo: %w", err)
+	}
+
+	return req, nil
+}
+
+func isMergePatch(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return mediaType == mergePatchContentType
+}
+
+// applyMergePatch applies JSON Merge Patch as defined by RFC 7396 to the document.
+func applyMergePatch(document, patch []byte) (patched []byte, err error) {
+	var target any
+
+	err = json.Unmarshal(document, &target)
+	if err != nil {
+		return nil, fmt.Errorf("failed unmarshaling patched document: %w", err)
+	}
+
+	var p any
+
+	err = json.Unmarshal(patch, &p)
+	if err != nil {
+		return nil, fmt.Errorf("failed unmarshaling merge patch: %w", err)
+	}
+
+	return json.Marshal(mergePatch(target, p)) //nolint: wrapcheck
+}
+
+func mergePatch(target, patch any) any {
+	patchObject, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]any)
+	if !ok {
+		targetObject = make(map[string]any)
+	}
+
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+
+		targetObject[key] = mergePatch(targetObject[key], value)
+	}
+
+	return targetObject
+}
+
+// unmarshalStrict works like [json.Unmarshal] but rejects unknown fields.
+func unmarshalStrict(data []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	return decoder.Decode(v) //nolint: wrapcheck
+}
@@ -0,0 +1,232 @@
+package todos
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/course-go/todos/internal/repository"
+	"github.com/course-go/todos/internal/todos"
+)
+
+const (
+	defaultTodosLimit = 50
+	maxTodosLimit     = 100
+)
+
+var (
+	ErrInvalidLimit  = errors.New("invalid limit")
+	ErrInvalidFilter = errors.New("invalid filter")
+	ErrMissingText   = errors.New("missing search text")
+)
+
+func parseTodosQuery(values url.Values, now time.Time) (query repository.TodosQuery, err error) {
+	query.Filter, err = parseTodosFilter(values, now)
+	if err != nil {
+		return repository.TodosQuery{}, err
+	}
+
+	query.Sort = repository.DefaultSort()
+
+	if values.Has("sort") {
+		query.Sort, err = repository.ParseSort(values.Get("sort"))
+		if err != nil {
+			return repository.TodosQuery{}, err //nolint: wrapcheck
+		}
+	}
+
+	query.Limit, err = parseLimit(values)
+	if err != nil {
+		return repository.TodosQuery{}, err
+	}
+
+	query.Cursor, err = parseCursor(values, query.Sort)
+	if err != nil {
+		return repository.TodosQuery{}, err
+	}
+
+	return query, nil
+}
+
+func parseSearchQuery(values url.Values) (query repository.SearchQuery, err error) {
+	query.Text = strings.TrimSpace(values.Get("q"))
+	if query.Text == "" {
+		return repository.SearchQuery{}, fmt.Errorf("%w: q must not be empty", ErrMissingText)
+	}
+
+	query.Limit, err = parseLimit(values)
+	if err != nil {
+		return repository.SearchQuery{}, err
+	}
+
+	if values.Has("cursor") {
+		cursor, err := repository.DecodeSearchCursor(values.Get("cursor"))
+		if err != nil {
+			return repository.SearchQuery{}, err //nolint: wrapcheck
+		}
+
+		if cursor.Text != query.Text {
+			return repository.SearchQuery{}, fmt.Errorf("%w: cursor was created for different search",
+				repository.ErrInvalidCursor,
+			)
+		}
+
+		query.Cursor = cursor
+	}
+
+	return query, nil
+}
+
+func parseLimit(values url.Values) (limit int, err error) {
+	if !values.Has("limit") {
+		return defaultTodosLimit, nil
+	}
+
+	limit, err = strconv.Atoi(values.Get("limit"))
+	if err != nil || limit < 1 || limit > maxTodosLimit {
+		return 0, fmt.Errorf("%w: must be a number between 1 and %d",
+			ErrInvalidLimit,
+			maxTodosLimit,
+		)
+	}
+
+	return limit, nil
+}
+
+func parseCursor(values url.Values, sort []repository.Sort) (cursor *repository.Cursor, err error) {
+	if !values.Has("cursor") {
+		return nil, nil //nolint: nilnil
+	}
+
+	cursor, err = repository.DecodeCursor(values.Get("cursor"))
+	if err != nil {
+		return nil, err //nolint: wrapcheck
+	}
+
+	if cursor.Sort != repository.FormatSort(sort) {
+		return nil, fmt.Errorf("%w: cursor was created for different sort", repository.ErrInvalidCursor)
+	}
+
+	return cursor, nil
+}
+
+// parseTodosFilter parses filter of todos. Overdue todos are those due before now.
+func parseTodosFilter(values url.Values, now time.Time) (filter repository.TodosFilter, err error) {
+	filter.Completed, err = parseBoolParameter(values, "completed")
+	if err != nil {
+		return repository.TodosFilter{}, err
+	}
+
+	filter.Overdue, err = parseBoolParameter(values, "overdue")
+	if err != nil {
+		return repository.TodosFilter{}, err
+	}
+
+	filter.Now = now
+
+	timeParameters := []struct {
+		name   string
+		target **time.Time
+	}{
+		{name: "createdAfter", target: &filter.CreatedAfter},
+		{name: "createdBefore", target: &filter.CreatedBefore},
+		{name: "completedAfter", target: &filter.CompletedAfter},
+		{name: "dueAfter", target: &filter.DueAfter},
+		{name: "dueBefore", target: &filter.DueBefore},
+	}
+	for _, parameter := range timeParameters {
+		*parameter.target, err = parseTimeParameter(values, parameter.name)
+		if err != nil {
+			return repository.TodosFilter{}, err
+		}
+	}
+
+	filter.Priorities, err = parsePriorities(values)
+	if err != nil {
+		return repository.TodosFilter{}, err
+	}
+
+	filter.Tags, filter.AllTags, err = parseTags(values)
+	if err != nil {
+		return repository.TodosFilter{}, err
+	}
+
+	filter.Text = values.Get("q")
+
+	return filter, nil
+}
+
+// parseTags parses tag names given as comma separated lists in one or more tag parameters.
+// The tagMode parameter decides whether todos have to have any (default) or all of the tags.
+func parseTags(values url.Values) (tags []string, all bool, err error) {
+	for _, value := range values["tag"] {
+		for name := range strings.SplitSeq(value, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				return nil, false, fmt.Errorf("%w: tag must be comma separated list of tag names", ErrInvalidFilter)
+			}
+
+			tags = append(tags, name)
+		}
+	}
+
+	switch values.Get("tagMode") {
+	case "", "any":
+		return tags, false, nil
+	case "all":
+		return tags, true, nil
+	default:
+		return nil, false, fmt.Errorf("%w: tagMode must be any or all", ErrInvalidFilter)
+	}
+}
+
+// parsePriorities parses comma separated list of priority names.
+func parsePriorities(values url.Values) (priorities []todos.Priority, err error) {
+	if !values.Has("priority") {
+		return nil, nil
+	}
+
+	for name := range strings.SplitSeq(values.Get("priority"), ",") {
+		name = strings.TrimSpace(name)
+
+		priority, err := todos.ParsePriority(name)
+		if err != nil || name == "" {
+			return nil, fmt.Errorf("%w: priority must be comma separated list of none, low, medium, high or urgent",
+				ErrInvalidFilter,
+			)
+		}
+
+		priorities = append(priorities, priority)
+	}
+
+	return priorities, nil
+}
+
+func parseBoolParameter(values url.Values, name string) (b *bool, err error) {
+	if !values.Has(name) {
+		return nil, nil //nolint: nilnil
+	}
+
+	parsed, err := strconv.ParseBool(values.Get(name))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s must be true or false", ErrInvalidFilter, name)
+	}
+
+	return &parsed, nil
+}
+
+func parseTimeParameter(values url.Values, name string) (t *time.Time, err error) {
+	if !values.Has(name) {
+		return nil, nil //nolint: nilnil
+	}
+
+	parsed, err := time.Parse(time.RFC3339Nano, values.Get(name))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s must be RFC 3339 timestamp", ErrInvalidFilter, name)
+	}
+
+	return &parsed, nil
+}
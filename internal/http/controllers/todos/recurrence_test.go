@@ -0,0 +1,88 @@
+package todos_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/course-go/todos/internal/utils/test"
+)
+
+func TestRecurringTodosControllers(t *testing.T) { //nolint: tparallel
+	t.Parallel()
+
+	ctx := t.Context()
+	logger := test.NewTestLogger(t)
+	r := test.NewTestRouter(ctx, t, logger)
+
+	t.Run("Patch Todo with invalid recurrence", func(t *testing.T) { //nolint: paralleltest
+		for _, recurrence := range []string{"FREQ=YEARLY", "FREQ=WEEKLY;BYDAY=XX", "FREQ=DAILY;COUNT=2;UNTIL=20241231"} {
+			reader := strings.NewReader(`{"recurrence":"` + recurrence + `"}`)
+			req := httptest.NewRequest(http.MethodPatch, apiURLPrefix+"/todos/"+mopTodoID, reader)
+			req.Header.Set("Content-Type", "application/merge-patch+json")
+
+			rr := httptest.NewRecorder()
+
+			r.ServeHTTP(rr, req)
+
+			compareResponseCodes(t, rr.Result(), http.StatusBadRequest)
+		}
+	})
+
+	t.Run("Patch Todo with recurrence", func(t *testing.T) { //nolint: paralleltest
+		reader := strings.NewReader(`{"recurrence":"FREQ=WEEKLY;INTERVAL=2;BYDAY=TH"}`)
+		req := httptest.NewRequest(http.MethodPatch, apiURLPrefix+"/todos/"+mopTodoID, reader)
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		compareResponseCodes(t, res, http.StatusOK)
+
+		todo, _ := decodeResponseBody(t, res).Data["todo"].(map[string]any)
+		if todo["recurrence"] != "FREQ=WEEKLY;INTERVAL=2;BYDAY=TH" {
+			t.Fatalf("recurrence does not match: expected: FREQ=WEEKLY;INTERVAL=2;BYDAY=TH != actual: %v",
+				todo["recurrence"],
+			)
+		}
+	})
+
+	t.Run("Complete recurring Todo", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodPost, apiURLPrefix+"/todos/"+mopTodoID+"/complete", http.NoBody)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		compareResponseCodes(t, rr.Result(), http.StatusOK)
+
+		req = httptest.NewRequest(http.MethodGet, apiURLPrefix+"/todos?completed=false", http.NoBody)
+		rr = httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		compareResponseCodes(t, res, http.StatusOK)
+
+		nextTodos, _ := decodeResponseBody(t, res).Data["todos"].([]any)
+		if len(nextTodos) != 1 {
+			t.Fatalf("expected next occurrence of the todo but was: %v", nextTodos)
+		}
+
+		next, _ := nextTodos[0].(map[string]any)
+		expectedTodo := map[string]any{
+			"description": "Mop the floor",
+			"dueAt":       "2024-08-15T12:00:00Z",
+			"recurrence":  "FREQ=WEEKLY;INTERVAL=2;BYDAY=TH",
+			"createdAt":   "2024-08-18T12:14:45.847679Z",
+		}
+
+		for key, expected := range expectedTodo {
+			if next[key] != expected {
+				t.Errorf("next occurrence %s does not match: expected: %v != actual: %v", key, expected, next[key])
+			}
+		}
+	})
+}
@@ -0,0 +1,122 @@
+package todos
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/course-go/todos/internal/http/dto/request"
+	"github.com/course-go/todos/internal/http/dto/response"
+	"github.com/course-go/todos/internal/http/exchange"
+	"github.com/course-go/todos/internal/repository"
+	"github.com/course-go/todos/internal/todos"
+	"github.com/google/uuid"
+)
+
+// GetSubtasksController lists subtasks of the todo in their order.
+func (c *Controller) GetSubtasksController(w http.ResponseWriter, r *http.Request) {
+	id, ok := exchange.ParseID(w, r, c.logger, "id")
+	if !ok {
+		return
+	}
+
+	subtasks, err := c.repository.GetSubtasks(r.Context(), id)
+	c.writeSubtasks(w, id, subtasks, err)
+}
+
+// CreateSubtaskController creates todo as the last subtask of the todo.
+func (c *Controller) CreateSubtaskController(w http.ResponseWriter, r *http.Request) {
+	id, ok := exchange.ParseID(w, r, c.logger, "id")
+	if !ok {
+		return
+	}
+
+	var req request.CreateTodoRequest
+
+	ok = exchange.BindRequest(w, r, c.logger, c.validator, &req)
+	if !ok {
+		return
+	}
+
+	_, ok = c.currentTodo(w, r, id)
+	if !ok {
+		return
+	}
+
+	req.ParentID = &id
+	c.createTodo(w, r, req)
+}
+
+// ReorderSubtasksController orders subtasks of the todo as given by their IDs.
+func (c *Controller) ReorderSubtasksController(w http.ResponseWriter, r *http.Request) {
+	id, ok := exchange.ParseID(w, r, c.logger, "id")
+	if !ok {
+		return
+	}
+
+	var req request.ReorderSubtasksRequest
+
+	ok = exchange.BindRequest(w, r, c.logger, c.validator, &req)
+	if !ok {
+		return
+	}
+
+	subtasks, err := c.repository.ReorderSubtasks(r.Context(), id, req.Order, c.time())
+	if errors.Is(err, repository.ErrSubtasksMismatch) {
+		c.logger.Warn("failed reordering subtasks",
+			"error", err,
+			"id", id,
+		)
+
+		code := http.StatusBadRequest
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorMessageBytes(code, "order has to contain every subtask exactly once"))
+
+		return
+	}
+
+	c.writeSubtasks(w, id, subtasks, err)
+}
+
+// writeSubtasks writes the subtasks of the todo or the error retrieving them to the response.
+func (c *Controller) writeSubtasks(w http.ResponseWriter, id uuid.UUID, subtasks []todos.Todo, err error) {
+	if errors.Is(err, repository.ErrTodoNotFound) {
+		c.logger.Warn("todo with given id does not exist",
+			"error", err,
+			"id", id,
+		)
+
+		code := http.StatusNotFound
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	if err != nil {
+		c.logger.Error("failed retrieving subtasks",
+			"error", err,
+			"id", id,
+		)
+
+		code := http.StatusInternalServerError
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	bytes, err := response.DataBytes("todos", subtasks)
+	if err != nil {
+		c.logger.Error("failed constructing response",
+			"error", err,
+		)
+
+		code := http.StatusInternalServerError
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	_, _ = w.Write(bytes)
+}
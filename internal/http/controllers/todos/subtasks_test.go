@@ -0,0 +1,166 @@
+package todos_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/course-go/todos/internal/utils/test"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSubtasksControllers(t *testing.T) { //nolint: tparallel
+	t.Parallel()
+
+	ctx := t.Context()
+	logger := test.NewTestLogger(t)
+	r := test.NewTestRouter(ctx, t, logger)
+
+	vacuumTodoID := "f52bad23-c201-414e-9bdb-af4327c42aa7"
+	subtasksURL := apiURLPrefix + "/todos/" + vacuumTodoID + "/subtasks"
+
+	var subtaskIDs []string
+
+	t.Run("Create Subtasks", func(t *testing.T) { //nolint: paralleltest
+		for _, description := range []string{"Empty the bin", "Vacuum the bedroom"} {
+			reader := strings.NewReader(`{"description":"` + description + `"}`)
+			req := httptest.NewRequest(http.MethodPost, subtasksURL, reader)
+			rr := httptest.NewRecorder()
+
+			r.ServeHTTP(rr, req)
+
+			res := rr.Result()
+			compareResponseCodes(t, res, http.StatusCreated)
+
+			todo, _ := decodeResponseBody(t, res).Data["todo"].(map[string]any)
+			if todo["parentId"] != vacuumTodoID {
+				t.Fatalf("expected subtask of %s but was: %v", vacuumTodoID, todo)
+			}
+
+			id, _ := todo["id"].(string)
+			subtaskIDs = append(subtaskIDs, id)
+		}
+	})
+
+	t.Run("Create Subtask of non-existing Todo", func(t *testing.T) { //nolint: paralleltest
+		reader := strings.NewReader(`{"description":"Empty the bin"}`)
+		req := httptest.NewRequest(
+			http.MethodPost,
+			apiURLPrefix+"/todos/be95c29a-c4dd-4d31-a5c4-d229f3374ab7/subtasks",
+			reader,
+		)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		compareResponseCodes(t, res, http.StatusNotFound)
+
+		expectedBodyBytes := []byte(`{"error":"Not Found"}`)
+		compareResponseBodies(t, res, expectedBodyBytes)
+	})
+
+	t.Run("Get Todo progress", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodPost, apiURLPrefix+"/todos/"+subtaskIDs[0]+"/complete", http.NoBody)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		compareResponseCodes(t, rr.Result(), http.StatusOK)
+
+		req = httptest.NewRequest(http.MethodGet, apiURLPrefix+"/todos/"+vacuumTodoID, http.NoBody)
+		rr = httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		compareResponseCodes(t, res, http.StatusOK)
+
+		todo, _ := decodeResponseBody(t, res).Data["todo"].(map[string]any)
+
+		expectedProgress := map[string]any{"completed": float64(1), "total": float64(2)}
+		if !cmp.Equal(expectedProgress, todo["progress"]) {
+			t.Errorf("progress does not match: %s", cmp.Diff(expectedProgress, todo["progress"]))
+		}
+	})
+
+	t.Run("Reorder Subtasks", func(t *testing.T) { //nolint: paralleltest
+		reader := strings.NewReader(`{"order":["` + subtaskIDs[1] + `","` + subtaskIDs[0] + `"]}`)
+		req := httptest.NewRequest(http.MethodPut, subtasksURL+"/order", reader)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		compareResponseCodes(t, res, http.StatusOK)
+
+		expectedIDs := []string{subtaskIDs[1], subtaskIDs[0]}
+
+		actualIDs := todoIDs(t, decodeResponseBody(t, res))
+		if !cmp.Equal(expectedIDs, actualIDs) {
+			t.Errorf("subtasks do not match: %s", cmp.Diff(expectedIDs, actualIDs))
+		}
+
+		req = httptest.NewRequest(http.MethodGet, subtasksURL, http.NoBody)
+		rr = httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res = rr.Result()
+		compareResponseCodes(t, res, http.StatusOK)
+
+		actualIDs = todoIDs(t, decodeResponseBody(t, res))
+		if !cmp.Equal(expectedIDs, actualIDs) {
+			t.Errorf("subtasks do not match: %s", cmp.Diff(expectedIDs, actualIDs))
+		}
+	})
+
+	t.Run("Reorder Subtasks partially", func(t *testing.T) { //nolint: paralleltest
+		reader := strings.NewReader(`{"order":["` + subtaskIDs[1] + `"]}`)
+		req := httptest.NewRequest(http.MethodPut, subtasksURL+"/order", reader)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		compareResponseCodes(t, res, http.StatusBadRequest)
+
+		expectedBodyBytes := []byte(`{"error":"Bad Request: order has to contain every subtask exactly once"}`)
+		compareResponseBodies(t, res, expectedBodyBytes)
+	})
+
+	t.Run("Re-parent Todo to its Subtask", func(t *testing.T) { //nolint: paralleltest
+		reader := strings.NewReader(`{"parentId":"` + subtaskIDs[0] + `"}`)
+		req := httptest.NewRequest(http.MethodPatch, apiURLPrefix+"/todos/"+vacuumTodoID, reader)
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		compareResponseCodes(t, res, http.StatusBadRequest)
+
+		expectedBodyBytes := []byte(`{"error":"Bad Request: parent todo cannot be a subtask of the todo"}`)
+		compareResponseBodies(t, res, expectedBodyBytes)
+	})
+
+	t.Run("Delete Todo with Subtasks", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodDelete, apiURLPrefix+"/todos/"+vacuumTodoID, http.NoBody)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		compareResponseCodes(t, rr.Result(), http.StatusNoContent)
+
+		for _, id := range subtaskIDs {
+			req = httptest.NewRequest(http.MethodGet, apiURLPrefix+"/todos/"+id, http.NoBody)
+			rr = httptest.NewRecorder()
+
+			r.ServeHTTP(rr, req)
+
+			compareResponseCodes(t, rr.Result(), http.StatusNotFound)
+		}
+	})
+}
@@ -1,14 +1,16 @@
 package todos
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 	"io"
 	"log/slog"
 	"net/http"
+	stdtime "time"
 
 	"github.com/course-go/todos/internal/http/dto/request"
 	"github.com/course-go/todos/internal/http/dto/response"
+	"github.com/course-go/todos/internal/http/exchange"
 	"github.com/course-go/todos/internal/repository"
 	"github.com/course-go/todos/internal/time"
 	"github.com/course-go/todos/internal/todos"
@@ -19,14 +21,14 @@ import (
 type Controller struct {
 	logger     *slog.Logger
 	validator  *validator.Validate
-	repository *repository.Repository
+	repository repository.TodoRepository
 	time       time.Factory
 }
 
 func NewController(
 	logger *slog.Logger,
 	validator *validator.Validate,
-	repository *repository.Repository,
+	repository repository.TodoRepository,
 	time time.Factory,
 ) *Controller {
 	return &Controller{
@@ -38,9 +40,133 @@ func NewController(
 }
 
 func (c *Controller) GetTodosController(w http.ResponseWriter, r *http.Request) {
-	todos, err := c.repository.GetTodos(r.Context())
+	query, err := parseTodosQuery(r.URL.Query(), c.time())
 	if err != nil {
-		c.logger.Error("failed retrieving todos",
+		c.logger.Warn("failed parsing query parameters",
+			"error", err,
+		)
+
+		code := http.StatusBadRequest
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorMessageBytes(code, err.Error()))
+
+		return
+	}
+
+	c.writeTodos(w, r, query)
+}
+
+// GetListTodosController lists todos of the list. It accepts the same query parameters as [GetTodosController].
+func (c *Controller) GetListTodosController(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		c.logger.Error("failed parsing uuid",
+			"uuid", r.PathValue("id"),
+			"error", err,
+		)
+
+		code := http.StatusBadRequest
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	query, err := parseTodosQuery(r.URL.Query(), c.time())
+	if err != nil {
+		c.logger.Warn("failed parsing query parameters",
+			"error", err,
+		)
+
+		code := http.StatusBadRequest
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorMessageBytes(code, err.Error()))
+
+		return
+	}
+
+	_, err = c.repository.GetList(r.Context(), id)
+	if errors.Is(err, repository.ErrListNotFound) {
+		c.logger.Debug("no matching id for list",
+			"id", id,
+		)
+
+		code := http.StatusNotFound
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	if err != nil {
+		c.logger.Error("failed retrieving list",
+			"error", err,
+			"id", id,
+		)
+
+		code := http.StatusInternalServerError
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	query.Filter.ListID = &id
+	c.writeTodos(w, r, query)
+}
+
+func (c *Controller) SearchTodosController(w http.ResponseWriter, r *http.Request) {
+	query, err := parseSearchQuery(r.URL.Query())
+	if err != nil {
+		c.logger.Warn("failed parsing query parameters",
+			"error", err,
+		)
+
+		code := http.StatusBadRequest
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorMessageBytes(code, err.Error()))
+
+		return
+	}
+
+	results, next, err := c.repository.SearchTodos(r.Context(), query)
+	if err != nil {
+		c.logger.Error("failed searching todos",
+			"error", err,
+		)
+
+		code := http.StatusInternalServerError
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	var nextCursor string
+	if next != nil {
+		nextCursor = next.Encode()
+	}
+
+	bytes, err := response.PageBytes("todos", results, nextCursor)
+	if err != nil {
+		c.logger.Error("failed constructing response",
+			"error", err,
+		)
+
+		code := http.StatusInternalServerError
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	_, _ = w.Write(bytes)
+}
+
+func (c *Controller) GetDeletedTodosController(w http.ResponseWriter, r *http.Request) {
+	todos, err := c.repository.GetDeletedTodos(r.Context())
+	if err != nil {
+		c.logger.Error("failed retrieving deleted todos",
 			"error", err,
 		)
 
@@ -67,6 +193,7 @@ func (c *Controller) GetTodosController(w http.ResponseWriter, r *http.Request)
 	_, _ = w.Write(bytes)
 }
 
+// GetTodoController retrieves the todo or the todo as it was at the time given by asOf query parameter.
 func (c *Controller) GetTodoController(w http.ResponseWriter, r *http.Request) {
 	id, err := uuid.Parse(r.PathValue("id"))
 	if err != nil {
@@ -82,6 +209,11 @@ func (c *Controller) GetTodoController(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if r.URL.Query().Has("asOf") {
+		c.writeTodoAsOf(w, r, id)
+		return
+	}
+
 	todo, err := c.repository.GetTodo(r.Context(), id)
 	if errors.Is(err, repository.ErrTodoNotFound) {
 		c.logger.Error("todo with given id does not exist",
@@ -121,13 +253,95 @@ func (c *Controller) GetTodoController(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, _ = w.Write(bytes)
+	writeCacheable(w, r, bytes, entityTag(todo), lastModified(todo))
 }
 
 func (c *Controller) CreateTodoController(w http.ResponseWriter, r *http.Request) {
+	var req request.CreateTodoRequest
+
+	ok := exchange.BindRequest(w, r, c.logger, c.validator, &req)
+	if !ok {
+		return
+	}
+
+	c.createTodo(w, r, req)
+}
+
+func (c *Controller) UpdateTodoController(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		c.logger.Error("failed parsing uuid",
+			"uuid", r.PathValue("id"),
+			"error", err,
+		)
+
+		code := http.StatusBadRequest
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	var req request.UpdateTodoRequest
+
+	ok := exchange.BindRequest(w, r, c.logger, c.validator, &req)
+	if !ok {
+		return
+	}
+
+	version, ok := c.ifMatchVersion(w, r, id)
+	if !ok {
+		return
+	}
+
+	now := c.time()
+	todo := todos.Todo{
+		ID:          id,
+		Description: req.Description,
+		ListID:      req.ListID,
+		ParentID:    req.ParentID,
+		Priority:    req.Priority,
+		DueAt:       req.DueAt,
+		Recurrence:  req.Recurrence,
+		Tags:        req.Tags,
+		CompletedAt: req.CompletedAt,
+		UpdatedAt:   &now,
+		Version:     version,
+	}
+
+	c.saveTodo(w, r, todo, http.StatusPreconditionFailed)
+}
+
+func (c *Controller) PatchTodoController(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		c.logger.Error("failed parsing uuid",
+			"uuid", r.PathValue("id"),
+			"error", err,
+		)
+
+		code := http.StatusBadRequest
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	if !isMergePatch(r.Header.Get("Content-Type")) {
+		c.logger.Warn("unsupported patch content type",
+			"contentType", r.Header.Get("Content-Type"),
+		)
+
+		code := http.StatusUnsupportedMediaType
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorMessageBytes(code, "expected "+mergePatchContentType))
+
+		return
+	}
+
 	body := r.Body
 
-	bodyBytes, err := io.ReadAll(body)
+	patchBytes, err := io.ReadAll(body)
 	if err != nil {
 		c.logger.Error("failed reading request body",
 			"error", err,
@@ -144,11 +358,14 @@ func (c *Controller) CreateTodoController(w http.ResponseWriter, r *http.Request
 		_ = body.Close()
 	}()
 
-	var req request.CreateTodoRequest
+	todo, ok := c.currentTodo(w, r, id)
+	if !ok {
+		return
+	}
 
-	err = json.Unmarshal(bodyBytes, &req)
+	req, err := c.patchTodo(todo, patchBytes)
 	if err != nil {
-		c.logger.Error("failed binding request body",
+		c.logger.Warn("failed patching todo",
 			"error", err,
 		)
 
@@ -159,28 +376,80 @@ func (c *Controller) CreateTodoController(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	err = c.validator.Struct(req)
-	if err != nil {
-		c.logger.Warn("failed validating request body",
+	now := c.time()
+	todo.Description = req.Description
+	todo.ListID = req.ListID
+	todo.ParentID = req.ParentID
+	todo.Priority = req.Priority
+	todo.DueAt = req.DueAt
+	todo.Recurrence = req.Recurrence
+	todo.Tags = req.Tags
+	todo.CompletedAt = req.CompletedAt
+	todo.UpdatedAt = &now
+
+	// The patch was applied to the retrieved todo so it must not be saved over concurrent changes.
+	conflictCode := http.StatusConflict
+	if r.Header.Get("If-Match") != "" {
+		conflictCode = http.StatusPreconditionFailed
+	}
+
+	c.saveTodo(w, r, todo, conflictCode)
+}
+
+func (c *Controller) CompleteTodoController(w http.ResponseWriter, r *http.Request) {
+	c.changeTodo(w, r, c.repository.CompleteTodo)
+}
+
+// MoveTodoController places the todo between its neighbors in the manual order of todos.
+func (c *Controller) MoveTodoController(w http.ResponseWriter, r *http.Request) {
+	id, ok := exchange.ParseID(w, r, c.logger, "id")
+	if !ok {
+		return
+	}
+
+	var req request.MoveTodoRequest
+
+	ok = exchange.BindRequest(w, r, c.logger, c.validator, &req)
+	if !ok {
+		return
+	}
+
+	move := repository.TodoMove{
+		Before: req.Before,
+		After:  req.After,
+	}
+
+	todo, err := c.repository.MoveTodo(r.Context(), id, move, c.time())
+	if errors.Is(err, repository.ErrTodoNotFound) {
+		c.logger.Warn("todo with given id does not exist",
 			"error", err,
+			"id", id,
 		)
 
-		code := http.StatusBadRequest
+		code := http.StatusNotFound
 		w.WriteHeader(code)
 		_, _ = w.Write(response.ErrorBytes(code))
 
 		return
 	}
 
-	todo := todos.Todo{
-		Description: req.Description,
-		CreatedAt:   c.time(),
+	if message, ok := invalidMoveMessage(err); ok {
+		c.logger.Warn("failed moving todo",
+			"error", err,
+			"id", id,
+		)
+
+		code := http.StatusBadRequest
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorMessageBytes(code, message))
+
+		return
 	}
 
-	todo, err = c.repository.CreateTodo(r.Context(), todo)
 	if err != nil {
-		c.logger.Error("failed creating todo",
+		c.logger.Error("failed moving todo",
 			"error", err,
+			"id", id,
 		)
 
 		code := http.StatusInternalServerError
@@ -203,16 +472,24 @@ func (c *Controller) CreateTodoController(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
+	w.Header().Set("ETag", entityTag(todo))
 	_, _ = w.Write(bytes)
 }
 
-func (c *Controller) UpdateTodoController(w http.ResponseWriter, r *http.Request) {
+func (c *Controller) ReopenTodoController(w http.ResponseWriter, r *http.Request) {
+	c.changeTodo(w, r, c.repository.ReopenTodo)
+}
+
+func (c *Controller) RestoreTodoController(w http.ResponseWriter, r *http.Request) {
+	c.changeTodo(w, r, c.repository.RestoreTodo)
+}
+
+func (c *Controller) DeleteTodoController(w http.ResponseWriter, r *http.Request) {
 	id, err := uuid.Parse(r.PathValue("id"))
 	if err != nil {
 		c.logger.Error("failed parsing uuid",
-			"uuid", r.PathValue("id"),
 			"error", err,
+			"uuid", r.PathValue("id"),
 		)
 
 		code := http.StatusBadRequest
@@ -222,12 +499,41 @@ func (c *Controller) UpdateTodoController(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	body := r.Body
+	version, ok := c.ifMatchVersion(w, r, id)
+	if !ok {
+		return
+	}
+
+	err = c.repository.DeleteTodo(r.Context(), id, version, c.time())
+	if errors.Is(err, repository.ErrVersionConflict) {
+		c.logger.Warn("todo was modified concurrently",
+			"error", err,
+			"id", id,
+		)
+
+		code := http.StatusPreconditionFailed
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	if errors.Is(err, repository.ErrTodoNotFound) {
+		c.logger.Debug("no matching id for todo",
+			"id", id,
+		)
+
+		code := http.StatusNotFound
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
 
-	bodyBytes, err := io.ReadAll(body)
 	if err != nil {
-		c.logger.Error("failed reading request body",
+		c.logger.Error("failed deleting todo",
 			"error", err,
+			"id", id,
 		)
 
 		code := http.StatusInternalServerError
@@ -237,51 +543,158 @@ func (c *Controller) UpdateTodoController(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	defer func() {
-		_ = body.Close()
-	}()
+	w.WriteHeader(http.StatusNoContent)
+}
 
-	var req request.UpdateTodoRequest
+// createTodo creates the requested todo and writes it to the response.
+func (c *Controller) createTodo(w http.ResponseWriter, r *http.Request, req request.CreateTodoRequest) {
+	todo := todos.Todo{
+		Description: req.Description,
+		ListID:      req.ListID,
+		ParentID:    req.ParentID,
+		Priority:    req.Priority,
+		DueAt:       req.DueAt,
+		Recurrence:  req.Recurrence,
+		Tags:        req.Tags,
+		CreatedAt:   c.time(),
+	}
 
-	err = json.Unmarshal(bodyBytes, &req)
-	if err != nil {
-		c.logger.Error("failed binding request body",
+	todo, err := c.repository.CreateTodo(r.Context(), todo)
+	if message, ok := invalidReferenceMessage(err); ok {
+		c.logger.Warn("failed creating todo",
 			"error", err,
 		)
 
 		code := http.StatusBadRequest
 		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorMessageBytes(code, message))
+
+		return
+	}
+
+	if err != nil {
+		c.logger.Error("failed creating todo",
+			"error", err,
+		)
+
+		code := http.StatusInternalServerError
+		w.WriteHeader(code)
 		_, _ = w.Write(response.ErrorBytes(code))
 
 		return
 	}
 
-	err = c.validator.Struct(req)
+	bytes, err := response.DataBytes("todo", todo)
 	if err != nil {
-		c.logger.Warn("failed validating request body",
+		c.logger.Error("failed constructing response",
 			"error", err,
 		)
 
-		code := http.StatusBadRequest
+		code := http.StatusInternalServerError
 		w.WriteHeader(code)
 		_, _ = w.Write(response.ErrorBytes(code))
 
 		return
 	}
 
-	now := c.time()
-	todo := todos.Todo{
-		ID:          id,
-		Description: req.Description,
-		CompletedAt: req.CompletedAt,
-		UpdatedAt:   &now,
+	w.Header().Set("ETag", entityTag(todo))
+	w.WriteHeader(http.StatusCreated)
+	_, _ = w.Write(bytes)
+}
+
+// writeTodos writes the page of todos matching the query to the response.
+// The modification time is retrieved before the todos so that it never claims changes
+// made after the todos were retrieved. It is left out when the todos matching the query
+// change over time even if no todo is modified.
+func (c *Controller) writeTodos(w http.ResponseWriter, r *http.Request, query repository.TodosQuery) {
+	var modified *stdtime.Time
+
+	if !dependsOnTime(query) {
+		var err error
+
+		modified, err = c.repository.GetTodosLastModified(r.Context())
+		if err != nil {
+			c.logger.Error("failed retrieving todos modification time",
+				"error", err,
+			)
+
+			code := http.StatusInternalServerError
+			w.WriteHeader(code)
+			_, _ = w.Write(response.ErrorBytes(code))
+
+			return
+		}
+	}
+
+	todos, next, err := c.repository.GetTodos(r.Context(), query)
+	if err != nil {
+		c.logger.Error("failed retrieving todos",
+			"error", err,
+		)
+
+		code := http.StatusInternalServerError
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	var nextCursor string
+	if next != nil {
+		nextCursor = next.Encode()
+	}
+
+	bytes, err := response.PageBytes("todos", todos, nextCursor)
+	if err != nil {
+		c.logger.Error("failed constructing response",
+			"error", err,
+		)
+
+		code := http.StatusInternalServerError
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	var lastModified stdtime.Time
+	if modified != nil {
+		lastModified = *modified
 	}
 
-	todo, err = c.repository.SaveTodo(r.Context(), todo)
+	writeCacheable(w, r, bytes, bodyEntityTag(bytes), lastModified)
+}
+
+// dependsOnTime reports whether the todos matching the query depend on the current time.
+func dependsOnTime(query repository.TodosQuery) bool {
+	return query.Filter.Overdue != nil
+}
+
+// changeTodo stamps todo with the current time using the given change.
+func (c *Controller) changeTodo(
+	w http.ResponseWriter,
+	r *http.Request,
+	change func(ctx context.Context, id uuid.UUID, now stdtime.Time) (todos.Todo, error),
+) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		c.logger.Error("failed parsing uuid",
+			"uuid", r.PathValue("id"),
+			"error", err,
+		)
+
+		code := http.StatusBadRequest
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	todo, err := change(r.Context(), id, c.time())
 	if errors.Is(err, repository.ErrTodoNotFound) {
-		c.logger.Warn("failed saving todo",
+		c.logger.Warn("todo with given id does not exist",
 			"error", err,
-			"id", todo.ID,
+			"id", id,
 		)
 
 		code := http.StatusNotFound
@@ -292,9 +705,9 @@ func (c *Controller) UpdateTodoController(w http.ResponseWriter, r *http.Request
 	}
 
 	if err != nil {
-		c.logger.Error("failed saving todo",
+		c.logger.Error("failed changing todo",
 			"error", err,
-			"id", todo.ID,
+			"id", id,
 		)
 
 		code := http.StatusInternalServerError
@@ -317,29 +730,91 @@ func (c *Controller) UpdateTodoController(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
+	w.Header().Set("ETag", entityTag(todo))
 	_, _ = w.Write(bytes)
 }
 
-func (c *Controller) DeleteTodoController(w http.ResponseWriter, r *http.Request) {
-	id, err := uuid.Parse(r.PathValue("id"))
+// ifMatchVersion evaluates If-Match header of the request against the current todo.
+// It returns version the change has to be conditioned on, zero meaning unconditional change.
+// If the precondition fails, the error response is written and false is returned.
+func (c *Controller) ifMatchVersion(w http.ResponseWriter, r *http.Request, id uuid.UUID) (version int, ok bool) {
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" || ifMatch == "*" {
+		return 0, true
+	}
+
+	todo, ok := c.currentTodo(w, r, id)
+
+	return todo.Version, ok
+}
+
+// currentTodo retrieves the todo and checks it against If-Match header of the request.
+// If the todo cannot be retrieved or does not match, the error response is written and false is returned.
+func (c *Controller) currentTodo(w http.ResponseWriter, r *http.Request, id uuid.UUID) (todo todos.Todo, ok bool) {
+	todo, err := c.repository.GetTodo(r.Context(), id)
+	if errors.Is(err, repository.ErrTodoNotFound) {
+		c.logger.Warn("todo with given id does not exist",
+			"error", err,
+			"id", id,
+		)
+
+		code := http.StatusNotFound
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return todos.Todo{}, false
+	}
+
 	if err != nil {
-		c.logger.Error("failed parsing uuid",
+		c.logger.Error("failed retrieving todo",
 			"error", err,
-			"uuid", r.PathValue("id"),
+			"id", id,
 		)
 
-		code := http.StatusBadRequest
+		code := http.StatusInternalServerError
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return todos.Todo{}, false
+	}
+
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch != "" && !matchesIfMatch(ifMatch, entityTag(todo)) {
+		c.logger.Warn("todo does not match entity tag",
+			"id", id,
+			"ifMatch", ifMatch,
+		)
+
+		code := http.StatusPreconditionFailed
 		w.WriteHeader(code)
 		_, _ = w.Write(response.ErrorBytes(code))
 
+		return todos.Todo{}, false
+	}
+
+	return todo, true
+}
+
+// saveTodo saves the todo and writes it to the response.
+// Conflicting concurrent change of the todo is reported with the given status code.
+func (c *Controller) saveTodo(w http.ResponseWriter, r *http.Request, todo todos.Todo, conflictCode int) {
+	todo, err := c.repository.SaveTodo(r.Context(), todo)
+	if errors.Is(err, repository.ErrVersionConflict) {
+		c.logger.Warn("todo was modified concurrently",
+			"error", err,
+			"id", todo.ID,
+		)
+
+		w.WriteHeader(conflictCode)
+		_, _ = w.Write(response.ErrorBytes(conflictCode))
+
 		return
 	}
 
-	err = c.repository.DeleteTodo(r.Context(), id, c.time())
 	if errors.Is(err, repository.ErrTodoNotFound) {
-		c.logger.Debug("no matching id for todo",
-			"id", id,
+		c.logger.Warn("failed saving todo",
+			"error", err,
+			"id", todo.ID,
 		)
 
 		code := http.StatusNotFound
@@ -349,10 +824,23 @@ func (c *Controller) DeleteTodoController(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	if message, ok := invalidReferenceMessage(err); ok {
+		c.logger.Warn("failed saving todo",
+			"error", err,
+			"id", todo.ID,
+		)
+
+		code := http.StatusBadRequest
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorMessageBytes(code, message))
+
+		return
+	}
+
 	if err != nil {
-		c.logger.Error("failed deleting todo",
+		c.logger.Error("failed saving todo",
 			"error", err,
-			"id", id,
+			"id", todo.ID,
 		)
 
 		code := http.StatusInternalServerError
@@ -362,5 +850,46 @@ func (c *Controller) DeleteTodoController(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	w.WriteHeader(http.StatusNoContent)
+	bytes, err := response.DataBytes("todo", todo)
+	if err != nil {
+		c.logger.Error("failed constructing response",
+			"error", err,
+		)
+
+		code := http.StatusInternalServerError
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	w.Header().Set("ETag", entityTag(todo))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(bytes)
+}
+
+// invalidReferenceMessage describes why the list or parent referenced by the todo cannot be used.
+func invalidReferenceMessage(err error) (message string, ok bool) {
+	switch {
+	case errors.Is(err, repository.ErrListNotFound):
+		return "list does not exist", true
+	case errors.Is(err, repository.ErrParentNotFound):
+		return "parent todo does not exist", true
+	case errors.Is(err, repository.ErrParentCycle):
+		return "parent todo cannot be a subtask of the todo", true
+	default:
+		return "", false
+	}
+}
+
+// invalidMoveMessage describes why the todo cannot be moved next to the given neighbors.
+func invalidMoveMessage(err error) (message string, ok bool) {
+	switch {
+	case errors.Is(err, repository.ErrNeighborNotFound):
+		return "neighbor todo does not exist", true
+	case errors.Is(err, repository.ErrInvalidMove):
+		return "todo has to be moved next to other todos and after has to precede before", true
+	default:
+		return "", false
+	}
 }
@@ -6,19 +6,24 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/course-go/todos/internal/http/dto/request"
 	"github.com/course-go/todos/internal/http/dto/response"
+	"github.com/course-go/todos/internal/todos"
 	"github.com/course-go/todos/internal/utils/test"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 )
 
-const apiURLPrefix = "/api/v1"
+const (
+	apiURLPrefix = "/api/v1"
+	mopTodoID    = "62446c85-3798-471f-abb8-75c1cdd7153b"
+)
 
-func TestTodosControllers(t *testing.T) { //nolint: cyclop, maintidx, tparallel
+func TestTodosControllers(t *testing.T) { //nolint: gocognit, gocyclo, cyclop, maintidx, tparallel
 	t.Parallel()
 
 	ctx := t.Context()
@@ -42,11 +47,18 @@ func TestTodosControllers(t *testing.T) { //nolint: cyclop, maintidx, tparallel
 				 {
 					"id":"62446c85-3798-471f-abb8-75c1cdd7153b",
 					"description":"Mop the floor",
+					"dueAt":"2024-08-01T12:00:00Z",
+					"tags":["home"],
+					"position":"3",
 					"createdAt":"2024-07-26T22:48:21.090537Z"
 				 },
 				 {
 					"id":"f52bad23-c201-414e-9bdb-af4327c42aa7",
 					"description":"Vacuum",
+					"priority":"high",
+					"dueAt":"2024-07-28T12:00:00Z",
+					"tags":["home","urgent"],
+					"position":"4",
 					"createdAt":"2024-07-26T22:49:47.366006Z",
 					"completedAt":"2024-07-27T22:50:19.594495Z",
 					"updatedAt":"2024-07-27T22:50:19.594495Z"
@@ -58,6 +70,354 @@ func TestTodosControllers(t *testing.T) { //nolint: cyclop, maintidx, tparallel
 		assertJSONContentType(t, res)
 	})
 
+	t.Run("Get not modified Todos", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodGet, apiURLPrefix+"/todos", http.NoBody)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		compareResponseCodes(t, res, http.StatusOK)
+
+		expectedLastModified := "Sat, 27 Jul 2024 22:50:19 GMT"
+		if lastModified := res.Header.Get("Last-Modified"); lastModified != expectedLastModified {
+			t.Errorf("last modified do not match: expected: %s != actual: %s", expectedLastModified, lastModified)
+		}
+
+		etag := res.Header.Get("ETag")
+		if etag == "" {
+			t.Fatalf("expected entity tag to be set")
+		}
+
+		headers := []http.Header{
+			{"If-None-Match": {`"stale", ` + etag}},
+			{"If-None-Match": {"W/" + etag}},
+			{"If-Modified-Since": {expectedLastModified}},
+			{"If-Modified-Since": {"Sun, 28 Jul 2024 00:00:00 GMT"}},
+		}
+		for _, header := range headers {
+			req := httptest.NewRequest(http.MethodGet, apiURLPrefix+"/todos", http.NoBody)
+			req.Header = header
+			rr := httptest.NewRecorder()
+
+			r.ServeHTTP(rr, req)
+
+			res := rr.Result()
+			compareResponseCodes(t, res, http.StatusNotModified)
+
+			if rr.Body.Len() != 0 {
+				t.Errorf("expected no bytes in body but was %d", rr.Body.Len())
+			}
+		}
+	})
+
+	t.Run("Get overdue Todos without Last-Modified", func(t *testing.T) { //nolint: paralleltest
+		// Todos become overdue without being modified.
+		req := httptest.NewRequest(http.MethodGet, apiURLPrefix+"/todos?overdue=true", http.NoBody)
+		req.Header.Set("If-Modified-Since", "Sun, 28 Jul 2024 00:00:00 GMT")
+
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		compareResponseCodes(t, res, http.StatusOK)
+
+		if lastModified := res.Header.Get("Last-Modified"); lastModified != "" {
+			t.Errorf("last modified should not be set: %s", lastModified)
+		}
+	})
+
+	t.Run("Get modified Todos", func(t *testing.T) { //nolint: paralleltest
+		headers := []http.Header{
+			{"If-None-Match": {`"stale"`}},
+			{"If-Modified-Since": {"Sat, 27 Jul 2024 22:50:18 GMT"}},
+			// If-Modified-Since is ignored when If-None-Match is present.
+			{"If-None-Match": {`"stale"`}, "If-Modified-Since": {"Sun, 28 Jul 2024 00:00:00 GMT"}},
+		}
+		for _, header := range headers {
+			req := httptest.NewRequest(http.MethodGet, apiURLPrefix+"/todos", http.NoBody)
+			req.Header = header
+			rr := httptest.NewRecorder()
+
+			r.ServeHTTP(rr, req)
+
+			res := rr.Result()
+			compareResponseCodes(t, res, http.StatusOK)
+			assertJSONContentType(t, res)
+		}
+	})
+
+	t.Run("Get Todos page by page", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodGet, apiURLPrefix+"/todos?limit=1", http.NoBody)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		compareResponseCodes(t, res, http.StatusOK)
+
+		firstPage := decodeResponseBody(t, res)
+		if firstPage.NextCursor == "" {
+			t.Fatalf("expected cursor pointing to the next page")
+		}
+
+		expectedBody := response.Response{
+			Data: map[string]any{
+				"todos": []any{
+					map[string]any{
+						"id":          "62446c85-3798-471f-abb8-75c1cdd7153b",
+						"description": "Mop the floor",
+						"dueAt":       "2024-08-01T12:00:00Z",
+						"tags":        []any{"home"},
+						"position":    "3",
+						"createdAt":   "2024-07-26T22:48:21.090537Z",
+					},
+				},
+			},
+			NextCursor: firstPage.NextCursor,
+		}
+		if !cmp.Equal(expectedBody, firstPage) {
+			t.Errorf("expected and actual response bodies do not match: %s", cmp.Diff(expectedBody, firstPage))
+		}
+
+		nextPageURL := apiURLPrefix + "/todos?limit=1&cursor=" + firstPage.NextCursor
+		req = httptest.NewRequest(http.MethodGet, nextPageURL, http.NoBody)
+		rr = httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res = rr.Result()
+		compareResponseCodes(t, res, http.StatusOK)
+
+		expectedBodyBytes := []byte(`{
+		   "data":{
+			  "todos":[
+				 {
+					"id":"f52bad23-c201-414e-9bdb-af4327c42aa7",
+					"description":"Vacuum",
+					"priority":"high",
+					"dueAt":"2024-07-28T12:00:00Z",
+					"tags":["home","urgent"],
+					"position":"4",
+					"createdAt":"2024-07-26T22:49:47.366006Z",
+					"completedAt":"2024-07-27T22:50:19.594495Z",
+					"updatedAt":"2024-07-27T22:50:19.594495Z"
+				 }
+			  ]
+		   }
+		}`)
+		compareResponseBodies(t, res, expectedBodyBytes)
+		assertJSONContentType(t, res)
+	})
+
+	t.Run("Get Todos with invalid pagination", func(t *testing.T) { //nolint: paralleltest
+		for _, query := range []string{"limit=0", "limit=1000", "limit=ten", "cursor=invalid"} {
+			req := httptest.NewRequest(http.MethodGet, apiURLPrefix+"/todos?"+query, http.NoBody)
+			rr := httptest.NewRecorder()
+
+			r.ServeHTTP(rr, req)
+
+			res := rr.Result()
+			compareResponseCodes(t, res, http.StatusBadRequest)
+			assertJSONContentType(t, res)
+		}
+	})
+
+	t.Run("Get filtered Todos", func(t *testing.T) { //nolint: paralleltest
+		filters := map[string]string{
+			"completed=true":                                  "f52bad23-c201-414e-9bdb-af4327c42aa7",
+			"completed=false":                                 "62446c85-3798-471f-abb8-75c1cdd7153b",
+			"createdAfter=2024-07-26T22:49:00Z":               "f52bad23-c201-414e-9bdb-af4327c42aa7",
+			"createdBefore=2024-07-26T22:49:00Z":              "62446c85-3798-471f-abb8-75c1cdd7153b",
+			"completedAfter=2024-07-27T00:00:00Z":             "f52bad23-c201-414e-9bdb-af4327c42aa7",
+			"q=MOP":                                           "62446c85-3798-471f-abb8-75c1cdd7153b",
+			"q=the%20floor&createdAfter=2024-07-01T00:00:00Z": "62446c85-3798-471f-abb8-75c1cdd7153b",
+			"priority=high":                                   "f52bad23-c201-414e-9bdb-af4327c42aa7",
+			"priority=none,%20low":                            "62446c85-3798-471f-abb8-75c1cdd7153b",
+			"overdue=true":                                    "62446c85-3798-471f-abb8-75c1cdd7153b",
+			"overdue=false":                                   "f52bad23-c201-414e-9bdb-af4327c42aa7",
+			"dueBefore=2024-07-30T00:00:00Z":                  "f52bad23-c201-414e-9bdb-af4327c42aa7",
+			"dueAfter=2024-07-30T00:00:00Z":                   "62446c85-3798-471f-abb8-75c1cdd7153b",
+			"tag=urgent":                                      "f52bad23-c201-414e-9bdb-af4327c42aa7",
+			"tag=work,urgent":                                 "f52bad23-c201-414e-9bdb-af4327c42aa7",
+			"tag=home&tag=urgent&tagMode=all":                 "f52bad23-c201-414e-9bdb-af4327c42aa7",
+		}
+		for query, expectedID := range filters {
+			req := httptest.NewRequest(http.MethodGet, apiURLPrefix+"/todos?"+query, http.NoBody)
+			rr := httptest.NewRecorder()
+
+			r.ServeHTTP(rr, req)
+
+			res := rr.Result()
+			compareResponseCodes(t, res, http.StatusOK)
+
+			body := decodeResponseBody(t, res)
+			actualIDs := todoIDs(t, body)
+
+			expectedIDs := []string{expectedID}
+			if !cmp.Equal(expectedIDs, actualIDs) {
+				t.Errorf("filtered todos do not match for %s: %s", query, cmp.Diff(expectedIDs, actualIDs))
+			}
+		}
+	})
+
+	t.Run("Get Todos with invalid filter", func(t *testing.T) { //nolint: paralleltest
+		invalidQueries := []string{
+			"completed=maybe",
+			"createdAfter=yesterday",
+			"completedAfter=2024-07-27",
+			"priority=critical",
+			"priority=high,",
+			"overdue=maybe",
+			"dueBefore=tomorrow",
+			"tag=home,",
+			"tag=home&tagMode=some",
+		}
+		for _, query := range invalidQueries {
+			req := httptest.NewRequest(http.MethodGet, apiURLPrefix+"/todos?"+query, http.NoBody)
+			rr := httptest.NewRecorder()
+
+			r.ServeHTTP(rr, req)
+
+			res := rr.Result()
+			compareResponseCodes(t, res, http.StatusBadRequest)
+			assertJSONContentType(t, res)
+		}
+	})
+
+	t.Run("Get sorted Todos", func(t *testing.T) { //nolint: paralleltest
+		sorts := map[string][]string{
+			"-createdAt":          {"f52bad23-c201-414e-9bdb-af4327c42aa7", "62446c85-3798-471f-abb8-75c1cdd7153b"},
+			"description":         {"62446c85-3798-471f-abb8-75c1cdd7153b", "f52bad23-c201-414e-9bdb-af4327c42aa7"},
+			"-completedAt,-id":    {"f52bad23-c201-414e-9bdb-af4327c42aa7", "62446c85-3798-471f-abb8-75c1cdd7153b"},
+			"updatedAt,createdAt": {"f52bad23-c201-414e-9bdb-af4327c42aa7", "62446c85-3798-471f-abb8-75c1cdd7153b"},
+			"-priority":           {"f52bad23-c201-414e-9bdb-af4327c42aa7", "62446c85-3798-471f-abb8-75c1cdd7153b"},
+			"priority,-createdAt": {"62446c85-3798-471f-abb8-75c1cdd7153b", "f52bad23-c201-414e-9bdb-af4327c42aa7"},
+			"dueAt":               {"f52bad23-c201-414e-9bdb-af4327c42aa7", "62446c85-3798-471f-abb8-75c1cdd7153b"},
+		}
+		for sort, expectedIDs := range sorts {
+			req := httptest.NewRequest(http.MethodGet, apiURLPrefix+"/todos?sort="+sort, http.NoBody)
+			rr := httptest.NewRecorder()
+
+			r.ServeHTTP(rr, req)
+
+			res := rr.Result()
+			compareResponseCodes(t, res, http.StatusOK)
+
+			actualIDs := todoIDs(t, decodeResponseBody(t, res))
+			if !cmp.Equal(expectedIDs, actualIDs) {
+				t.Errorf("sorted todos do not match for %s: %s", sort, cmp.Diff(expectedIDs, actualIDs))
+			}
+		}
+	})
+
+	t.Run("Get sorted Todos page by page", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodGet, apiURLPrefix+"/todos?sort=-completedAt&limit=1", http.NoBody)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		compareResponseCodes(t, res, http.StatusOK)
+
+		firstPage := decodeResponseBody(t, res)
+		expectedIDs := []string{"f52bad23-c201-414e-9bdb-af4327c42aa7"}
+
+		actualIDs := todoIDs(t, firstPage)
+		if !cmp.Equal(expectedIDs, actualIDs) {
+			t.Errorf("first page todos do not match: %s", cmp.Diff(expectedIDs, actualIDs))
+		}
+
+		nextPageURL := apiURLPrefix + "/todos?sort=-completedAt&limit=1&cursor=" + firstPage.NextCursor
+		req = httptest.NewRequest(http.MethodGet, nextPageURL, http.NoBody)
+		rr = httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res = rr.Result()
+		compareResponseCodes(t, res, http.StatusOK)
+
+		secondPage := decodeResponseBody(t, res)
+		expectedIDs = []string{"62446c85-3798-471f-abb8-75c1cdd7153b"}
+
+		actualIDs = todoIDs(t, secondPage)
+		if !cmp.Equal(expectedIDs, actualIDs) {
+			t.Errorf("second page todos do not match: %s", cmp.Diff(expectedIDs, actualIDs))
+		}
+
+		if secondPage.NextCursor != "" {
+			t.Errorf("expected no cursor on the last page but was %s", secondPage.NextCursor)
+		}
+
+		mismatchedURL := apiURLPrefix + "/todos?sort=description&limit=1&cursor=" + firstPage.NextCursor
+		req = httptest.NewRequest(http.MethodGet, mismatchedURL, http.NoBody)
+		rr = httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res = rr.Result()
+		compareResponseCodes(t, res, http.StatusBadRequest)
+	})
+
+	t.Run("Get Todos with unknown sort field", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodGet, apiURLPrefix+"/todos?sort=-createdAt,owner", http.NoBody)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		compareResponseCodes(t, res, http.StatusBadRequest)
+
+		expectedBodyBytes := []byte(`{"error":"Bad Request: unknown sort field: \"owner\""}`)
+		compareResponseBodies(t, res, expectedBodyBytes)
+		assertJSONContentType(t, res)
+	})
+
+	t.Run("Search Todos", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodGet, apiURLPrefix+"/todos/search?q=floor", http.NoBody)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		compareResponseCodes(t, res, http.StatusOK)
+		assertJSONContentType(t, res)
+
+		body := decodeResponseBody(t, res)
+		expectedIDs := []string{"62446c85-3798-471f-abb8-75c1cdd7153b"}
+
+		actualIDs := todoIDs(t, body)
+		if !cmp.Equal(expectedIDs, actualIDs) {
+			t.Fatalf("found todos do not match: %s", cmp.Diff(expectedIDs, actualIDs))
+		}
+
+		result, _ := body.Data["todos"].([]any)[0].(map[string]any)
+
+		expectedSnippet := "Mop the <mark>floor</mark>"
+		if result["snippet"] != expectedSnippet {
+			t.Errorf("expected %s snippet but was %v", expectedSnippet, result["snippet"])
+		}
+
+		if rank, _ := result["rank"].(float64); rank <= 0 {
+			t.Errorf("expected positive rank but was %v", result["rank"])
+		}
+	})
+
+	t.Run("Search Todos without text", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodGet, apiURLPrefix+"/todos/search?q=%20", http.NoBody)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		compareResponseCodes(t, res, http.StatusBadRequest)
+
+		expectedBodyBytes := []byte(`{"error":"Bad Request: missing search text: q must not be empty"}`)
+		compareResponseBodies(t, res, expectedBodyBytes)
+		assertJSONContentType(t, res)
+	})
+
 	t.Run("Get existing Todo", func(t *testing.T) { //nolint: paralleltest
 		req := httptest.NewRequest(http.MethodGet, apiURLPrefix+"/todos/"+playGamesTodoID, http.NoBody)
 		req.SetPathValue("id", playGamesTodoID)
@@ -74,6 +434,9 @@ func TestTodosControllers(t *testing.T) { //nolint: cyclop, maintidx, tparallel
 			  "todo":{
 				 "id":"62446c85-3798-471f-abb8-75c1cdd7153b",
 				 "description":"Mop the floor",
+				 "dueAt":"2024-08-01T12:00:00Z",
+				 "tags":["home"],
+				 "position":"3",
 				 "createdAt":"2024-07-26T22:48:21.090537Z"
 			  }
 		   }
@@ -82,6 +445,50 @@ func TestTodosControllers(t *testing.T) { //nolint: cyclop, maintidx, tparallel
 		assertJSONContentType(t, res)
 	})
 
+	t.Run("Get not modified Todo", func(t *testing.T) { //nolint: paralleltest
+		headers := []http.Header{
+			{"If-None-Match": {`"1"`}},
+			{"If-None-Match": {"*"}},
+			{"If-Modified-Since": {"Fri, 26 Jul 2024 22:48:21 GMT"}},
+		}
+		for _, header := range headers {
+			req := httptest.NewRequest(http.MethodGet, apiURLPrefix+"/todos/"+playGamesTodoID, http.NoBody)
+			req.SetPathValue("id", playGamesTodoID)
+			req.Header = header
+
+			rr := httptest.NewRecorder()
+
+			r.ServeHTTP(rr, req)
+
+			res := rr.Result()
+			compareResponseCodes(t, res, http.StatusNotModified)
+
+			expectedETag := `"1"`
+			if etag := res.Header.Get("ETag"); etag != expectedETag {
+				t.Errorf("entity tags do not match: expected: %s != actual: %s", expectedETag, etag)
+			}
+
+			expectedLastModified := "Fri, 26 Jul 2024 22:48:21 GMT"
+			if lastModified := res.Header.Get("Last-Modified"); lastModified != expectedLastModified {
+				t.Errorf("last modified do not match: expected: %s != actual: %s", expectedLastModified, lastModified)
+			}
+		}
+	})
+
+	t.Run("Get modified Todo", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodGet, apiURLPrefix+"/todos/"+playGamesTodoID, http.NoBody)
+		req.SetPathValue("id", playGamesTodoID)
+		req.Header.Set("If-Modified-Since", "Fri, 26 Jul 2024 22:48:20 GMT")
+
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		compareResponseCodes(t, res, http.StatusOK)
+		assertJSONContentType(t, res)
+	})
+
 	t.Run("Get non-existing Todo", func(t *testing.T) { //nolint: paralleltest
 		todoID := "d8d5141a-ad8c-486a-9d4d-6bda9c7cb33c"
 		req := httptest.NewRequest(http.MethodGet, apiURLPrefix+"/todos/"+todoID, http.NoBody)
@@ -102,6 +509,8 @@ func TestTodosControllers(t *testing.T) { //nolint: cyclop, maintidx, tparallel
 	t.Run("Create Todo", func(t *testing.T) { //nolint: paralleltest
 		body := request.CreateTodoRequest{
 			Description: "Play some games",
+			Priority:    todos.PriorityLow,
+			Tags:        []string{"leisure", "home", "leisure"},
 		}
 
 		actualBodyBytes, err := json.Marshal(&body)
@@ -123,6 +532,9 @@ func TestTodosControllers(t *testing.T) { //nolint: cyclop, maintidx, tparallel
 			  "todo":{
 				 "id":"bc931469-bb84-4fd0-aa6d-acfef864580d",
 				 "description":"Play some games",
+				 "priority":"low",
+				 "tags":["home","leisure"],
+				 "position":"5",
 				 "createdAt":"2024-08-18T12:14:45.847679Z"
 			  }
 		   }
@@ -185,6 +597,51 @@ func TestTodosControllers(t *testing.T) { //nolint: cyclop, maintidx, tparallel
 		assertJSONContentType(t, res)
 	})
 
+	t.Run("Create Todo with unknown priority", func(t *testing.T) { //nolint: paralleltest
+		reader := strings.NewReader(`{"description":"Play some games","priority":"critical"}`)
+		req := httptest.NewRequest(http.MethodPost, apiURLPrefix+"/todos", reader)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		compareResponseCodes(t, res, http.StatusBadRequest)
+
+		expectedBodyBytes := []byte(`{"error":"Bad Request"}`)
+		compareResponseBodies(t, res, expectedBodyBytes)
+		assertJSONContentType(t, res)
+	})
+
+	t.Run("Create Todo with invalid tag", func(t *testing.T) { //nolint: paralleltest
+		reader := strings.NewReader(`{"description":"Play some games","tags":["home,work"]}`)
+		req := httptest.NewRequest(http.MethodPost, apiURLPrefix+"/todos", reader)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		compareResponseCodes(t, res, http.StatusBadRequest)
+
+		expectedBodyBytes := []byte(`{"error":"Bad Request"}`)
+		compareResponseBodies(t, res, expectedBodyBytes)
+		assertJSONContentType(t, res)
+	})
+
+	t.Run("Create Todo in non-existing list", func(t *testing.T) { //nolint: paralleltest
+		reader := strings.NewReader(`{"description":"Play some games","listId":"be95c29a-c4dd-4d31-a5c4-d229f3374ab7"}`)
+		req := httptest.NewRequest(http.MethodPost, apiURLPrefix+"/todos", reader)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		compareResponseCodes(t, res, http.StatusBadRequest)
+
+		expectedBodyBytes := []byte(`{"error":"Bad Request: list does not exist"}`)
+		compareResponseBodies(t, res, expectedBodyBytes)
+		assertJSONContentType(t, res)
+	})
+
 	t.Run("Edit existing Todo", func(t *testing.T) { //nolint: paralleltest
 		completedAt, err := time.Parse(time.DateTime, "2024-07-28 22:51:00")
 		if err != nil {
@@ -217,6 +674,7 @@ func TestTodosControllers(t *testing.T) { //nolint: cyclop, maintidx, tparallel
 			  "todo":{
 				 "id":"62446c85-3798-471f-abb8-75c1cdd7153b",
 				 "description":"Play some games",
+				 "position":"3",
 				 "createdAt":"2024-07-26T22:48:21.090537Z",
 				 "completedAt":"2024-07-28T22:51:00Z",
 				 "updatedAt":"2024-08-18T12:14:45.847679Z"
@@ -291,6 +749,286 @@ func TestTodosControllers(t *testing.T) { //nolint: cyclop, maintidx, tparallel
 		assertJSONContentType(t, res)
 	})
 
+	t.Run("Patch existing Todo", func(t *testing.T) { //nolint: paralleltest
+		reader := bytes.NewReader([]byte(`{"completedAt":null}`))
+		req := httptest.NewRequest(http.MethodPatch, apiURLPrefix+"/todos/"+playGamesTodoID, reader)
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+		req.SetPathValue("id", playGamesTodoID)
+
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		compareResponseCodes(t, res, http.StatusOK)
+
+		expectedBodyBytes := []byte(`{
+		   "data":{
+			  "todo":{
+				 "id":"62446c85-3798-471f-abb8-75c1cdd7153b",
+				 "description":"Play some games",
+				 "position":"3",
+				 "createdAt":"2024-07-26T22:48:21.090537Z",
+				 "updatedAt":"2024-08-18T12:14:45.847679Z"
+			  }
+		   }
+		}`)
+		compareResponseBodies(t, res, expectedBodyBytes)
+		assertJSONContentType(t, res)
+
+		reader = bytes.NewReader([]byte(`{"description":"Play chess"}`))
+		req = httptest.NewRequest(http.MethodPatch, apiURLPrefix+"/todos/"+playGamesTodoID, reader)
+		req.Header.Set("Content-Type", "application/merge-patch+json; charset=utf-8")
+		req.SetPathValue("id", playGamesTodoID)
+
+		rr = httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res = rr.Result()
+		compareResponseCodes(t, res, http.StatusOK)
+
+		expectedBodyBytes = []byte(`{
+		   "data":{
+			  "todo":{
+				 "id":"62446c85-3798-471f-abb8-75c1cdd7153b",
+				 "description":"Play chess",
+				 "position":"3",
+				 "createdAt":"2024-07-26T22:48:21.090537Z",
+				 "updatedAt":"2024-08-18T12:14:45.847679Z"
+			  }
+		   }
+		}`)
+		compareResponseBodies(t, res, expectedBodyBytes)
+		assertJSONContentType(t, res)
+	})
+
+	t.Run("Patch Todo with invalid body", func(t *testing.T) { //nolint: paralleltest
+		for _, patch := range []string{`{"description":null}`, `{"description":""}`, `{"id":"x"}`, `[]`, `{`} {
+			reader := bytes.NewReader([]byte(patch))
+			req := httptest.NewRequest(http.MethodPatch, apiURLPrefix+"/todos/"+playGamesTodoID, reader)
+			req.Header.Set("Content-Type", "application/merge-patch+json")
+			req.SetPathValue("id", playGamesTodoID)
+
+			rr := httptest.NewRecorder()
+
+			r.ServeHTTP(rr, req)
+
+			res := rr.Result()
+			compareResponseCodes(t, res, http.StatusBadRequest)
+
+			expectedBodyBytes := []byte(`{"error":"Bad Request"}`)
+			compareResponseBodies(t, res, expectedBodyBytes)
+			assertJSONContentType(t, res)
+		}
+	})
+
+	t.Run("Patch Todo with unsupported content type", func(t *testing.T) { //nolint: paralleltest
+		reader := bytes.NewReader([]byte(`{"description":"Play chess"}`))
+		req := httptest.NewRequest(http.MethodPatch, apiURLPrefix+"/todos/"+playGamesTodoID, reader)
+		req.Header.Set("Content-Type", "application/json")
+		req.SetPathValue("id", playGamesTodoID)
+
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		compareResponseCodes(t, res, http.StatusUnsupportedMediaType)
+
+		expectedBodyBytes := []byte(`{"error":"Unsupported Media Type: expected application/merge-patch+json"}`)
+		compareResponseBodies(t, res, expectedBodyBytes)
+		assertJSONContentType(t, res)
+	})
+
+	t.Run("Patch non-existing Todo", func(t *testing.T) { //nolint: paralleltest
+		todoID := "cba6b1a9-3533-4eff-8649-a075229b1c3d"
+		reader := bytes.NewReader([]byte(`{"description":"Play chess"}`))
+		req := httptest.NewRequest(http.MethodPatch, apiURLPrefix+"/todos/"+todoID, reader)
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+		req.SetPathValue("id", todoID)
+
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		compareResponseCodes(t, res, http.StatusNotFound)
+
+		expectedBodyBytes := []byte(`{"error":"Not Found"}`)
+		compareResponseBodies(t, res, expectedBodyBytes)
+		assertJSONContentType(t, res)
+	})
+
+	t.Run("Complete existing Todo", func(t *testing.T) { //nolint: paralleltest
+		// Completing the todo repeatedly must not change it.
+		for range 2 {
+			req := httptest.NewRequest(http.MethodPost, apiURLPrefix+"/todos/"+playGamesTodoID+"/complete", http.NoBody)
+			req.SetPathValue("id", playGamesTodoID)
+
+			rr := httptest.NewRecorder()
+
+			r.ServeHTTP(rr, req)
+
+			res := rr.Result()
+			compareResponseCodes(t, res, http.StatusOK)
+
+			expectedBodyBytes := []byte(`{
+			   "data":{
+				  "todo":{
+					 "id":"62446c85-3798-471f-abb8-75c1cdd7153b",
+					 "description":"Play chess",
+					 "position":"3",
+					 "createdAt":"2024-07-26T22:48:21.090537Z",
+					 "completedAt":"2024-08-18T12:14:45.847679Z",
+					 "updatedAt":"2024-08-18T12:14:45.847679Z"
+				  }
+			   }
+			}`)
+			compareResponseBodies(t, res, expectedBodyBytes)
+			assertJSONContentType(t, res)
+		}
+	})
+
+	t.Run("Reopen existing Todo", func(t *testing.T) { //nolint: paralleltest
+		for range 2 {
+			req := httptest.NewRequest(http.MethodPost, apiURLPrefix+"/todos/"+playGamesTodoID+"/reopen", http.NoBody)
+			req.SetPathValue("id", playGamesTodoID)
+
+			rr := httptest.NewRecorder()
+
+			r.ServeHTTP(rr, req)
+
+			res := rr.Result()
+			compareResponseCodes(t, res, http.StatusOK)
+
+			expectedBodyBytes := []byte(`{
+			   "data":{
+				  "todo":{
+					 "id":"62446c85-3798-471f-abb8-75c1cdd7153b",
+					 "description":"Play chess",
+					 "position":"3",
+					 "createdAt":"2024-07-26T22:48:21.090537Z",
+					 "updatedAt":"2024-08-18T12:14:45.847679Z"
+				  }
+			   }
+			}`)
+			compareResponseBodies(t, res, expectedBodyBytes)
+			assertJSONContentType(t, res)
+		}
+	})
+
+	t.Run("Complete non-existing Todo", func(t *testing.T) { //nolint: paralleltest
+		todoID := "0bd0b1b6-0d4e-4a57-a4a5-37fa44c6a61f"
+		req := httptest.NewRequest(http.MethodPost, apiURLPrefix+"/todos/"+todoID+"/complete", http.NoBody)
+		req.SetPathValue("id", todoID)
+
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		compareResponseCodes(t, res, http.StatusNotFound)
+
+		expectedBodyBytes := []byte(`{"error":"Not Found"}`)
+		compareResponseBodies(t, res, expectedBodyBytes)
+		assertJSONContentType(t, res)
+	})
+
+	var etag string
+
+	t.Run("Get Todo entity tag", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodGet, apiURLPrefix+"/todos/"+playGamesTodoID, http.NoBody)
+		req.SetPathValue("id", playGamesTodoID)
+
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		compareResponseCodes(t, res, http.StatusOK)
+
+		etag = res.Header.Get("ETag")
+		if etag == "" {
+			t.Fatalf("expected entity tag to be set")
+		}
+	})
+
+	t.Run("Edit Todo with matching entity tag", func(t *testing.T) { //nolint: paralleltest
+		reader := strings.NewReader(`{"description":"Play chess"}`)
+		req := httptest.NewRequest(http.MethodPut, apiURLPrefix+"/todos/"+playGamesTodoID, reader)
+		req.SetPathValue("id", playGamesTodoID)
+		req.Header.Set("If-Match", etag)
+
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		compareResponseCodes(t, res, http.StatusOK)
+
+		newEtag := res.Header.Get("ETag")
+		if newEtag == "" || newEtag == etag {
+			t.Errorf("expected entity tag to change: previous: %s, actual: %s", etag, newEtag)
+		}
+
+		assertJSONContentType(t, res)
+	})
+
+	t.Run("Edit Todo with stale entity tag", func(t *testing.T) { //nolint: paralleltest
+		reader := strings.NewReader(`{"description":"Play go"}`)
+		req := httptest.NewRequest(http.MethodPut, apiURLPrefix+"/todos/"+playGamesTodoID, reader)
+		req.SetPathValue("id", playGamesTodoID)
+		req.Header.Set("If-Match", etag)
+
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		compareResponseCodes(t, res, http.StatusPreconditionFailed)
+
+		expectedBodyBytes := []byte(`{"error":"Precondition Failed"}`)
+		compareResponseBodies(t, res, expectedBodyBytes)
+		assertJSONContentType(t, res)
+	})
+
+	t.Run("Patch Todo with stale entity tag", func(t *testing.T) { //nolint: paralleltest
+		reader := strings.NewReader(`{"description":"Play go"}`)
+		req := httptest.NewRequest(http.MethodPatch, apiURLPrefix+"/todos/"+playGamesTodoID, reader)
+		req.SetPathValue("id", playGamesTodoID)
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+		req.Header.Set("If-Match", etag)
+
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		compareResponseCodes(t, res, http.StatusPreconditionFailed)
+
+		expectedBodyBytes := []byte(`{"error":"Precondition Failed"}`)
+		compareResponseBodies(t, res, expectedBodyBytes)
+		assertJSONContentType(t, res)
+	})
+
+	t.Run("Delete Todo with stale entity tag", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodDelete, apiURLPrefix+"/todos/"+playGamesTodoID, http.NoBody)
+		req.SetPathValue("id", playGamesTodoID)
+		req.Header.Set("If-Match", etag)
+
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		compareResponseCodes(t, res, http.StatusPreconditionFailed)
+
+		expectedBodyBytes := []byte(`{"error":"Precondition Failed"}`)
+		compareResponseBodies(t, res, expectedBodyBytes)
+		assertJSONContentType(t, res)
+	})
+
 	t.Run("Delete existing Todo", func(t *testing.T) { //nolint: paralleltest
 		req := httptest.NewRequest(http.MethodDelete, apiURLPrefix+"/todos/"+playGamesTodoID, http.NoBody)
 		req.SetPathValue("id", playGamesTodoID)
@@ -330,44 +1068,229 @@ func TestTodosControllers(t *testing.T) { //nolint: cyclop, maintidx, tparallel
 		compareResponseBodies(t, res, expectedBodyBytes)
 		assertJSONContentType(t, res)
 	})
+
+	t.Run("Batch Todos atomically", func(t *testing.T) { //nolint: paralleltest
+		reader := strings.NewReader(`{
+			"operations":[
+				{"op":"create","description":"Water the plants"},
+				{"op":"update","id":"f52bad23-c201-414e-9bdb-af4327c42aa7","description":"Vacuum the stairs"},
+				{"op":"delete","id":"d06c0dd1-d7ae-4ca7-8df4-86a6b62f349d"}
+			]
+		}`)
+		req := httptest.NewRequest(http.MethodPost, apiURLPrefix+"/todos:batch", reader)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		compareResponseCodes(t, res, http.StatusUnprocessableEntity)
+
+		expectedBodyBytes := []byte(`{
+		   "error":"Unprocessable Entity: operation 2 failed",
+		   "data":{
+			  "failedIndex":2,
+			  "results":[
+				 {"status":424,"error":"Failed Dependency"},
+				 {"status":424,"error":"Failed Dependency"},
+				 {"status":404,"error":"Not Found"}
+			  ]
+		   }
+		}`)
+		compareResponseBodies(t, res, expectedBodyBytes)
+		assertJSONContentType(t, res)
+
+		req = httptest.NewRequest(http.MethodGet, apiURLPrefix+"/todos?q=Water", http.NoBody)
+		rr = httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		body := decodeResponseBody(t, rr.Result())
+		if ids := todoIDs(t, body); len(ids) != 0 {
+			t.Errorf("expected created todo to be rolled back but got: %v", ids)
+		}
+	})
+
+	t.Run("Batch Todos with best effort", func(t *testing.T) { //nolint: paralleltest
+		reader := strings.NewReader(`{
+			"mode":"bestEffort",
+			"operations":[
+				{"op":"create","description":"Water the plants"},
+				{"op":"update","id":"f52bad23-c201-414e-9bdb-af4327c42aa7","description":"Vacuum the stairs"},
+				{"op":"delete","id":"d06c0dd1-d7ae-4ca7-8df4-86a6b62f349d"}
+			]
+		}`)
+		req := httptest.NewRequest(http.MethodPost, apiURLPrefix+"/todos:batch", reader)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		compareResponseCodes(t, res, http.StatusOK)
+		assertJSONContentType(t, res)
+
+		expectedStatuses := []int{http.StatusCreated, http.StatusOK, http.StatusNotFound}
+
+		actualStatuses := batchStatuses(t, decodeResponseBody(t, res))
+		if !cmp.Equal(expectedStatuses, actualStatuses) {
+			t.Errorf("batch statuses do not match: %s", cmp.Diff(expectedStatuses, actualStatuses))
+		}
+	})
+
+	t.Run("Batch invalid Todos", func(t *testing.T) { //nolint: paralleltest
+		bodies := []string{
+			`{"operations":[]}`,
+			`{"operations":[{"op":"archive","id":"f52bad23-c201-414e-9bdb-af4327c42aa7"}]}`,
+			`{"operations":[{"op":"update","description":"Vacuum the stairs"}]}`,
+			`{"mode":"eventually","operations":[{"op":"create","description":"Water the plants"}]}`,
+		}
+		for _, body := range bodies {
+			req := httptest.NewRequest(http.MethodPost, apiURLPrefix+"/todos:batch", strings.NewReader(body))
+			rr := httptest.NewRecorder()
+
+			r.ServeHTTP(rr, req)
+
+			res := rr.Result()
+			compareResponseCodes(t, res, http.StatusBadRequest)
+
+			expectedBodyBytes := []byte(`{"error":"Bad Request"}`)
+			compareResponseBodies(t, res, expectedBodyBytes)
+		}
+	})
+
+	t.Run("Get deleted Todos", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodGet, apiURLPrefix+"/todos/trash", http.NoBody)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		compareResponseCodes(t, res, http.StatusOK)
+		assertJSONContentType(t, res)
+
+		expectedIDs := []string{
+			playGamesTodoID,
+			"1221a4fb-34cb-43cd-bc94-88e720ae8511",
+			"aeec043e-05ea-4271-9772-ddefe87628d6",
+		}
+
+		actualIDs := todoIDs(t, decodeResponseBody(t, res))
+		if !cmp.Equal(expectedIDs, actualIDs) {
+			t.Errorf("deleted todos do not match: %s", cmp.Diff(expectedIDs, actualIDs))
+		}
+	})
+
+	t.Run("Restore deleted Todo", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodPost, apiURLPrefix+"/todos/"+playGamesTodoID+"/restore", http.NoBody)
+		req.SetPathValue("id", playGamesTodoID)
+
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		compareResponseCodes(t, res, http.StatusOK)
+
+		expectedBodyBytes := []byte(`{
+		   "data":{
+			  "todo":{
+				 "id":"62446c85-3798-471f-abb8-75c1cdd7153b",
+				 "description":"Play chess",
+				 "position":"3",
+				 "createdAt":"2024-07-26T22:48:21.090537Z",
+				 "updatedAt":"2024-08-18T12:14:45.847679Z"
+			  }
+		   }
+		}`)
+		compareResponseBodies(t, res, expectedBodyBytes)
+		assertJSONContentType(t, res)
+
+		req = httptest.NewRequest(http.MethodGet, apiURLPrefix+"/todos/"+playGamesTodoID, http.NoBody)
+		req.SetPathValue("id", playGamesTodoID)
+
+		rr = httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		compareResponseCodes(t, rr.Result(), http.StatusOK)
+	})
+
+	t.Run("Restore not deleted Todo", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodPost, apiURLPrefix+"/todos/"+playGamesTodoID+"/restore", http.NoBody)
+		req.SetPathValue("id", playGamesTodoID)
+
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		compareResponseCodes(t, res, http.StatusNotFound)
+
+		expectedBodyBytes := []byte(`{"error":"Not Found"}`)
+		compareResponseBodies(t, res, expectedBodyBytes)
+		assertJSONContentType(t, res)
+	})
 }
 
 func compareResponseCodes(t *testing.T, res *http.Response, expectedCode int) {
 	t.Helper()
 
-	actualCode := res.StatusCode
-	if expectedCode != actualCode {
-		t.Errorf("expected %d content type but was %d", expectedCode, actualCode)
-	}
+	test.CompareResponseCodes(t, res, expectedCode)
 }
 
 func compareResponseBodies(t *testing.T, res *http.Response, expectedBody []byte) {
 	t.Helper()
 
-	actualBody, err := io.ReadAll(res.Body)
-	if err != nil {
-		t.Errorf("could not read body bytes: %v", err)
+	test.CompareResponseBodies(t, res, expectedBody)
+}
+
+func decodeResponseBody(t *testing.T, res *http.Response) response.Response {
+	t.Helper()
+
+	return test.DecodeResponseBody(t, res)
+}
+
+func todoIDs(t *testing.T, body response.Response) (ids []string) {
+	t.Helper()
+
+	todos, ok := body.Data["todos"].([]any)
+	if !ok {
+		t.Fatalf("response does not contain todos: %v", body)
 	}
 
-	var expectedResponseBody response.Response
+	ids = make([]string, 0, len(todos))
+	for _, todo := range todos {
+		todo, ok := todo.(map[string]any)
+		if !ok {
+			t.Fatalf("todo is not an object: %v", todo)
+		}
 
-	err = json.Unmarshal(expectedBody, &expectedResponseBody)
-	if err != nil {
-		t.Errorf("could not unmarshal expected body bytes: %v", err)
+		id, _ := todo["id"].(string)
+		ids = append(ids, id)
 	}
 
-	var actualResponseBody response.Response
+	return ids
+}
+
+func batchStatuses(t *testing.T, body response.Response) (statuses []int) {
+	t.Helper()
 
-	err = json.Unmarshal(actualBody, &actualResponseBody)
-	if err != nil {
-		t.Errorf("could not unmarshal actual body bytes: %v", err)
+	results, ok := body.Data["results"].([]any)
+	if !ok {
+		t.Fatalf("response does not contain results: %v", body)
 	}
 
-	if !cmp.Equal(expectedResponseBody, actualResponseBody) {
-		t.Errorf("expected and actual response bodies do not match: %s",
-			cmp.Diff(expectedResponseBody, actualResponseBody),
-		)
+	statuses = make([]int, 0, len(results))
+	for _, result := range results {
+		result, ok := result.(map[string]any)
+		if !ok {
+			t.Fatalf("result is not an object: %v", result)
+		}
+
+		status, _ := result["status"].(float64)
+		statuses = append(statuses, int(status))
 	}
+
+	return statuses
 }
 
 func assertJSONContentType(t *testing.T, res *http.Response) {
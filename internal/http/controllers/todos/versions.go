@@ -0,0 +1,187 @@
+package todos
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	stdtime "time"
+
+	"github.com/course-go/todos/internal/http/dto/response"
+	"github.com/course-go/todos/internal/http/exchange"
+	"github.com/course-go/todos/internal/repository"
+	"github.com/course-go/todos/internal/todos"
+	"github.com/google/uuid"
+)
+
+// GetTodoVersionsController lists versions of the todo from the oldest one.
+func (c *Controller) GetTodoVersionsController(w http.ResponseWriter, r *http.Request) {
+	id, ok := exchange.ParseID(w, r, c.logger, "id")
+	if !ok {
+		return
+	}
+
+	versions, ok := c.todoVersions(w, r, id)
+	if !ok {
+		return
+	}
+
+	bytes, err := response.DataBytes("versions", versions)
+	if err != nil {
+		c.logger.Error("failed constructing response",
+			"error", err,
+		)
+
+		code := http.StatusInternalServerError
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	_, _ = w.Write(bytes)
+}
+
+// RevertTodoController restores the todo as it was in the given version.
+// The restored todo is saved as a new version unless the todo was changed in the meantime.
+func (c *Controller) RevertTodoController(w http.ResponseWriter, r *http.Request) {
+	id, ok := exchange.ParseID(w, r, c.logger, "id")
+	if !ok {
+		return
+	}
+
+	number, err := strconv.Atoi(r.PathValue("version"))
+	if err != nil || number < 1 {
+		c.logger.Warn("failed parsing version",
+			"version", r.PathValue("version"),
+		)
+
+		code := http.StatusBadRequest
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorMessageBytes(code, "version must be positive integer"))
+
+		return
+	}
+
+	versions, ok := c.todoVersions(w, r, id)
+	if !ok {
+		return
+	}
+
+	i := slices.IndexFunc(versions, func(version todos.Version) bool {
+		return version.Number == number
+	})
+	if i < 0 {
+		c.logger.Warn("todo version does not exist",
+			"id", id,
+			"version", number,
+		)
+
+		code := http.StatusNotFound
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	version := versions[i].Todo
+	now := c.time()
+	todo := todos.Todo{
+		ID:          id,
+		Description: version.Description,
+		ListID:      version.ListID,
+		ParentID:    version.ParentID,
+		Priority:    version.Priority,
+		DueAt:       version.DueAt,
+		Recurrence:  version.Recurrence,
+		Tags:        version.Tags,
+		CompletedAt: version.CompletedAt,
+		UpdatedAt:   &now,
+		Version:     versions[len(versions)-1].Number,
+	}
+
+	c.saveTodo(w, r, todo, http.StatusConflict)
+}
+
+// writeTodoAsOf writes the todo as it was at the time given by the asOf query parameter.
+func (c *Controller) writeTodoAsOf(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	asOf, err := stdtime.Parse(stdtime.RFC3339Nano, r.URL.Query().Get("asOf"))
+	if err != nil {
+		c.logger.Warn("failed parsing query parameters",
+			"error", err,
+		)
+
+		code := http.StatusBadRequest
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorMessageBytes(code, "asOf must be RFC 3339 timestamp"))
+
+		return
+	}
+
+	versions, ok := c.todoVersions(w, r, id)
+	if !ok {
+		return
+	}
+
+	version, ok := todos.VersionAt(versions, asOf)
+	if !ok || version.Todo.DeletedAt != nil {
+		c.logger.Warn("todo did not exist at the time",
+			"id", id,
+			"asOf", asOf,
+		)
+
+		code := http.StatusNotFound
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	bytes, err := response.DataBytes("todo", version.Todo)
+	if err != nil {
+		c.logger.Error("failed constructing response",
+			"error", err,
+		)
+
+		code := http.StatusInternalServerError
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	_, _ = w.Write(bytes)
+}
+
+// todoVersions retrieves versions of the todo.
+// If they cannot be retrieved, the error response is written and false is returned.
+func (c *Controller) todoVersions(w http.ResponseWriter, r *http.Request, id uuid.UUID) ([]todos.Version, bool) {
+	versions, err := c.repository.GetTodoVersions(r.Context(), id)
+	if errors.Is(err, repository.ErrTodoNotFound) {
+		c.logger.Warn("todo with given id does not exist",
+			"error", err,
+			"id", id,
+		)
+
+		code := http.StatusNotFound
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return nil, false
+	}
+
+	if err != nil {
+		c.logger.Error("failed retrieving todo versions",
+			"error", err,
+			"id", id,
+		)
+
+		code := http.StatusInternalServerError
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return nil, false
+	}
+
+	return versions, true
+}
@@ -0,0 +1,179 @@
+package todos_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/course-go/todos/internal/utils/test"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTodoVersionsControllers(t *testing.T) { //nolint: tparallel
+	t.Parallel()
+
+	ctx := t.Context()
+	logger := test.NewTestLogger(t)
+	r := test.NewTestRouter(ctx, t, logger)
+
+	todoURL := apiURLPrefix + "/todos/62446c85-3798-471f-abb8-75c1cdd7153b"
+
+	t.Run("Update Todo", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodPut, todoURL, strings.NewReader(`{"description":"Mop the kitchen"}`))
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		compareResponseCodes(t, rr.Result(), http.StatusOK)
+	})
+
+	t.Run("Get Todo versions", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodGet, todoURL+"/versions", http.NoBody)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		compareResponseCodes(t, res, http.StatusOK)
+
+		expectedVersions := []string{
+			"1: Mop the floor from 2024-07-26T22:48:21.090537Z to 2024-08-18T12:14:45.847679Z",
+			"2: Mop the kitchen from 2024-08-18T12:14:45.847679Z to ",
+		}
+		actualVersions := describeVersions(t, decodeResponseBody(t, res).Data["versions"])
+
+		if !cmp.Equal(expectedVersions, actualVersions) {
+			t.Errorf("versions do not match: %s", cmp.Diff(expectedVersions, actualVersions))
+		}
+	})
+
+	t.Run("Get Todo as of time", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodGet, todoURL+"?asOf=2024-08-01T00:00:00Z", http.NoBody)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		compareResponseCodes(t, res, http.StatusOK)
+
+		todo, _ := decodeResponseBody(t, res).Data["todo"].(map[string]any)
+		if todo["description"] != "Mop the floor" {
+			t.Errorf("todo does not match: expected: Mop the floor != actual: %v", todo["description"])
+		}
+	})
+
+	t.Run("Get Todo before its creation", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodGet, todoURL+"?asOf=2024-07-01T00:00:00Z", http.NoBody)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		compareResponseCodes(t, rr.Result(), http.StatusNotFound)
+	})
+
+	t.Run("Get Todo as of invalid time", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodGet, todoURL+"?asOf=yesterday", http.NoBody)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		compareResponseCodes(t, res, http.StatusBadRequest)
+
+		expectedBodyBytes := []byte(`{"error":"Bad Request: asOf must be RFC 3339 timestamp"}`)
+		compareResponseBodies(t, res, expectedBodyBytes)
+	})
+
+	t.Run("Revert Todo", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodPost, todoURL+"/versions/1/revert", http.NoBody)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		compareResponseCodes(t, res, http.StatusOK)
+
+		expectedBodyBytes := []byte(`{
+		   "data":{
+			  "todo":{
+				 "id":"62446c85-3798-471f-abb8-75c1cdd7153b",
+				 "description":"Mop the floor",
+				 "dueAt":"2024-08-01T12:00:00Z",
+				 "tags":["home"],
+				 "position":"3",
+				 "createdAt":"2024-07-26T22:48:21.090537Z",
+				 "updatedAt":"2024-08-18T12:14:45.847679Z"
+			  }
+		   }
+		}`)
+		compareResponseBodies(t, res, expectedBodyBytes)
+
+		req = httptest.NewRequest(http.MethodGet, todoURL+"/versions", http.NoBody)
+		rr = httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		versions, _ := decodeResponseBody(t, rr.Result()).Data["versions"].([]any)
+		if len(versions) != 3 {
+			t.Errorf("revert should be a new version: expected: 3 != actual: %d", len(versions))
+		}
+	})
+
+	t.Run("Revert Todo to non-existing version", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodPost, todoURL+"/versions/9/revert", http.NoBody)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		compareResponseCodes(t, rr.Result(), http.StatusNotFound)
+	})
+
+	t.Run("Revert Todo to invalid version", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(http.MethodPost, todoURL+"/versions/latest/revert", http.NoBody)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		res := rr.Result()
+		compareResponseCodes(t, res, http.StatusBadRequest)
+
+		expectedBodyBytes := []byte(`{"error":"Bad Request: version must be positive integer"}`)
+		compareResponseBodies(t, res, expectedBodyBytes)
+	})
+
+	t.Run("Get versions of non-existing Todo", func(t *testing.T) { //nolint: paralleltest
+		req := httptest.NewRequest(
+			http.MethodGet,
+			apiURLPrefix+"/todos/be95c29a-c4dd-4d31-a5c4-d229f3374ab7/versions",
+			http.NoBody,
+		)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		compareResponseCodes(t, rr.Result(), http.StatusNotFound)
+	})
+}
+
+// describeVersions describes versions by their number, description and validity.
+func describeVersions(t *testing.T, data any) (descriptions []string) {
+	t.Helper()
+
+	versions, _ := data.([]any)
+	for _, version := range versions {
+		version, _ := version.(map[string]any)
+		todo, _ := version["todo"].(map[string]any)
+		number, _ := version["version"].(float64)
+		description, _ := todo["description"].(string)
+		validFrom, _ := version["validFrom"].(string)
+		validTo, _ := version["validTo"].(string)
+
+		descriptions = append(descriptions,
+			strconv.Itoa(int(number))+": "+description+" from "+validFrom+" to "+validTo,
+		)
+	}
+
+	return descriptions
+}
@@ -1,12 +1,102 @@
 package request
 
-import "time"
+import (
+	"time"
 
+	"github.com/course-go/todos/internal/todos"
+	"github.com/google/uuid"
+)
+
+// CreateTodoRequest creates todo. Unknown priority names are rejected already when binding the request.
+// Tags which do not exist yet are created. Invalid recurrence rules are rejected when binding the request too.
 type CreateTodoRequest struct {
-	Description string `json:"description" validate:"required"`
+	Description string            `json:"description" validate:"required"`
+	ListID      *uuid.UUID        `json:"listId"`
+	ParentID    *uuid.UUID        `json:"parentId"`
+	Priority    todos.Priority    `json:"priority"    validate:"gte=0,lte=4"`
+	DueAt       *time.Time        `json:"dueAt"`
+	Recurrence  *todos.Recurrence `json:"recurrence"`
+	Tags        []string          `json:"tags"        validate:"max=20,dive,required,max=64,excludesall=0x2C"`
 }
 
 type UpdateTodoRequest struct {
-	Description string     `json:"description" validate:"required"`
-	CompletedAt *time.Time `json:"completedAt"`
+	Description string            `json:"description" validate:"required"`
+	ListID      *uuid.UUID        `json:"listId"`
+	ParentID    *uuid.UUID        `json:"parentId"`
+	Priority    todos.Priority    `json:"priority"    validate:"gte=0,lte=4"`
+	DueAt       *time.Time        `json:"dueAt"`
+	Recurrence  *todos.Recurrence `json:"recurrence"`
+	Tags        []string          `json:"tags"        validate:"max=20,dive,required,max=64,excludesall=0x2C"`
+	CompletedAt *time.Time        `json:"completedAt"`
+}
+
+// ReorderSubtasksRequest orders subtasks of a todo. It has to contain IDs of all the subtasks.
+type ReorderSubtasksRequest struct {
+	Order []uuid.UUID `json:"order" validate:"required"`
+}
+
+// MoveTodoRequest places todo between its neighbors given by their IDs.
+// If only one of them is given, the todo is placed right next to it.
+type MoveTodoRequest struct {
+	Before *uuid.UUID `json:"before" validate:"required_without=After"`
+	After  *uuid.UUID `json:"after"  validate:"required_without=Before"`
+}
+
+// CreateTagRequest creates tag. Tag names cannot contain commas as they separate tags in query parameters.
+type CreateTagRequest struct {
+	Name string `json:"name" validate:"required,max=64,excludesall=0x2C"`
+}
+
+type UpdateTagRequest struct {
+	Name string `json:"name" validate:"required,max=64,excludesall=0x2C"`
+}
+
+// CreateListRequest creates list. Color is optional hex color like #1e90ff.
+type CreateListRequest struct {
+	Name     string `json:"name"     validate:"required,max=100"`
+	Color    string `json:"color"    validate:"omitempty,hexcolor"`
+	Archived bool   `json:"archived"`
+}
+
+type UpdateListRequest struct {
+	Name     string `json:"name"     validate:"required,max=100"`
+	Color    string `json:"color"    validate:"omitempty,hexcolor"`
+	Archived bool   `json:"archived"`
+}
+
+// CreateCommentRequest creates comment of a todo.
+type CreateCommentRequest struct {
+	Author string `json:"author" validate:"required,max=100"`
+	Body   string `json:"body"   validate:"required,max=10000"`
+}
+
+// UpdateCommentRequest edits body of a comment. The author cannot be changed.
+type UpdateCommentRequest struct {
+	Body string `json:"body" validate:"required,max=10000"`
+}
+
+type BatchRequest struct {
+	// Mode is either "atomic" (default) or "bestEffort".
+	Mode       string                  `json:"mode"       validate:"omitempty,oneof=atomic bestEffort"`
+	Operations []BatchOperationRequest `json:"operations" validate:"required,min=1,max=100,dive"`
+}
+
+type BatchOperationRequest struct {
+	Op          string            `json:"op"          validate:"required,oneof=create update delete"`
+	ID          *uuid.UUID        `json:"id"          validate:"required_unless=Op create,excluded_if=Op create"`
+	Description string            `json:"description" validate:"required_unless=Op delete"`
+	ListID      *uuid.UUID        `json:"listId"`
+	ParentID    *uuid.UUID        `json:"parentId"`
+	Priority    todos.Priority    `json:"priority"    validate:"gte=0,lte=4"`
+	DueAt       *time.Time        `json:"dueAt"`
+	Recurrence  *todos.Recurrence `json:"recurrence"`
+	Tags        []string          `json:"tags"        validate:"max=20,dive,required,max=64,excludesall=0x2C"`
+	CompletedAt *time.Time        `json:"completedAt"`
+}
+
+// CreateAPIKeyRequest mints API key. Keys without expiration are valid until they are revoked.
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name"      validate:"required,max=100"`
+	Scopes    []string   `json:"scopes"    validate:"required,min=1,dive,oneof=read write admin"`
+	ExpiresAt *time.Time `json:"expiresAt"`
 }
@@ -0,0 +1,10 @@
+package response
+
+import "github.com/course-go/todos/internal/todos"
+
+// BatchResult describes outcome of a single batch operation using HTTP status codes.
+type BatchResult struct {
+	Status int         `json:"status"`
+	Todo   *todos.Todo `json:"todo,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
@@ -6,8 +6,9 @@ import (
 )
 
 type Response struct {
-	Data  map[string]any `json:"data,omitempty"`
-	Error string         `json:"error,omitempty"`
+	Data       map[string]any `json:"data,omitempty"`
+	NextCursor string         `json:"nextCursor,omitempty"`
+	Error      string         `json:"error,omitempty"`
 }
 
 func ErrorBytes(httpCode int) []byte {
@@ -23,6 +24,32 @@ func ErrorBytes(httpCode int) []byte {
 	return bytes
 }
 
+// ErrorMessageBytes works like [ErrorBytes] but also describes
+// what exactly went wrong so the client can fix the request.
+func ErrorMessageBytes(httpCode int, message string) []byte {
+	response := Response{
+		Error: http.StatusText(httpCode) + ": " + message,
+	}
+
+	bytes, err := json.Marshal(response)
+	if err != nil {
+		return nil
+	}
+
+	return bytes
+}
+
+// ErrorDataBytes works like [ErrorMessageBytes] but also includes
+// data describing the failure in more detail.
+func ErrorDataBytes(httpCode int, message string, data map[string]any) (bytes []byte, err error) {
+	response := Response{
+		Data:  data,
+		Error: http.StatusText(httpCode) + ": " + message,
+	}
+
+	return json.Marshal(response) //nolint: wrapcheck
+}
+
 func DataBytes(name string, data any) (bytes []byte, err error) {
 	response := Response{
 		Data: map[string]any{
@@ -32,3 +59,16 @@ func DataBytes(name string, data any) (bytes []byte, err error) {
 
 	return json.Marshal(response) //nolint: wrapcheck
 }
+
+// PageBytes works like [DataBytes] but also includes
+// cursor pointing to the next page of data.
+func PageBytes(name string, data any, nextCursor string) (bytes []byte, err error) {
+	response := Response{
+		Data: map[string]any{
+			name: data,
+		},
+		NextCursor: nextCursor,
+	}
+
+	return json.Marshal(response) //nolint: wrapcheck
+}
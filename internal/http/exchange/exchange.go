@@ -0,0 +1,107 @@
+// Package exchange reads requests and writes responses the same way across all controllers.
+package exchange
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/course-go/todos/internal/http/dto/response"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+// ParseID parses UUID from the named path parameter.
+func ParseID(w http.ResponseWriter, r *http.Request, logger *slog.Logger, name string) (id uuid.UUID, ok bool) {
+	id, err := uuid.Parse(r.PathValue(name))
+	if err != nil {
+		logger.Error("failed parsing uuid",
+			"uuid", r.PathValue(name),
+			"error", err,
+		)
+
+		code := http.StatusBadRequest
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return uuid.UUID{}, false
+	}
+
+	return id, true
+}
+
+// BindRequest reads, binds and validates the request body.
+func BindRequest(
+	w http.ResponseWriter,
+	r *http.Request,
+	logger *slog.Logger,
+	validator *validator.Validate,
+	req any,
+) (ok bool) {
+	body := r.Body
+
+	bodyBytes, err := io.ReadAll(body)
+	if err != nil {
+		logger.Error("failed reading request body",
+			"error", err,
+		)
+
+		code := http.StatusInternalServerError
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return false
+	}
+
+	defer func() {
+		_ = body.Close()
+	}()
+
+	err = json.Unmarshal(bodyBytes, req)
+	if err != nil {
+		logger.Error("failed binding request body",
+			"error", err,
+		)
+
+		code := http.StatusBadRequest
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return false
+	}
+
+	err = validator.Struct(req)
+	if err != nil {
+		logger.Warn("failed validating request body",
+			"error", err,
+		)
+
+		code := http.StatusBadRequest
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return false
+	}
+
+	return true
+}
+
+// WriteData writes the named data with the given status code.
+func WriteData(w http.ResponseWriter, logger *slog.Logger, code int, name string, data any) {
+	bytes, err := response.DataBytes(name, data)
+	if err != nil {
+		logger.Error("failed constructing response",
+			"error", err,
+		)
+
+		code := http.StatusInternalServerError
+		w.WriteHeader(code)
+		_, _ = w.Write(response.ErrorBytes(code))
+
+		return
+	}
+
+	w.WriteHeader(code)
+	_, _ = w.Write(bytes)
+}
@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/course-go/todos/internal/actor"
+)
+
+// ActorHeader names whoever makes the request so that the changes can be attributed to them.
+const ActorHeader = "X-Actor"
+
+func Actor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.Header.Get(ActorHeader)
+		if name != "" {
+			r = r.WithContext(actor.With(r.Context(), name))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/course-go/todos/internal/actor"
+	"github.com/course-go/todos/internal/auth"
+	"github.com/course-go/todos/internal/config"
+	"github.com/course-go/todos/internal/http/dto/response"
+	"github.com/course-go/todos/internal/repository"
+	ttime "github.com/course-go/todos/internal/time"
+)
+
+const (
+	// bearerPrefix precedes the token in the Authorization header.
+	bearerPrefix = "Bearer "
+	// adminKeyName is the name of the key configured as the admin key.
+	adminKeyName = "admin"
+)
+
+// Authenticate rejects requests without valid API key sent as bearer token in the Authorization header.
+// Safe requests need the read scope and the other requests need the write scope. The key is passed
+// through the context and its name is used as the actor of changes instead of the actor header.
+func Authenticate(
+	logger *slog.Logger,
+	keys repository.APIKeyRepository,
+	config *config.Auth,
+	time ttime.Factory,
+) Middleware {
+	logger = logger.With("component", "http.middleware.authentication")
+	adminKeyHash := []byte(auth.Hash(config.AdminKey))
+	useKey := func(ctx context.Context, token string) (auth.Key, error) {
+		hash := auth.Hash(token)
+		if config.AdminKey != "" && subtle.ConstantTimeCompare([]byte(hash), adminKeyHash) == 1 {
+			return auth.Key{Name: adminKeyName, Scopes: []string{auth.ScopeAdmin}}, nil
+		}
+
+		return keys.UseAPIKey(ctx, hash, time())
+	}
+
+	return func(next http.Handler) http.Handler {
+		if config.Disabled {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), bearerPrefix)
+			if !ok || token == "" {
+				logger.Debug("missing bearer token",
+					"uri", r.RequestURI,
+				)
+
+				unauthorized(w)
+
+				return
+			}
+
+			key, err := useKey(r.Context(), token)
+			if errors.Is(err, repository.ErrInvalidAPIKey) {
+				logger.Debug("invalid api key",
+					"uri", r.RequestURI,
+				)
+
+				unauthorized(w)
+
+				return
+			}
+
+			if err != nil {
+				logger.Error("failed retrieving api key",
+					"error", err,
+				)
+
+				code := http.StatusInternalServerError
+				w.WriteHeader(code)
+				_, _ = w.Write(response.ErrorBytes(code))
+
+				return
+			}
+
+			scope := methodScope(r.Method)
+			if !key.HasScope(scope) {
+				logger.Debug("api key lacks scope",
+					"key", key.Name,
+					"scope", scope,
+				)
+
+				forbidden(w)
+
+				return
+			}
+
+			ctx := auth.With(r.Context(), key)
+			ctx = actor.With(ctx, key.Name)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequireScope rejects requests whose API key lacks the scope. It has to be used after [Authenticate].
+// Requests without any key are let through as they are only possible when authentication is disabled.
+func RequireScope(scope string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key, ok := auth.From(r.Context())
+			if ok && !key.HasScope(scope) {
+				forbidden(w)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// methodScope returns the scope needed for requests with the method.
+func methodScope(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return auth.ScopeRead
+	default:
+		return auth.ScopeWrite
+	}
+}
+
+func unauthorized(w http.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", "Bearer")
+
+	code := http.StatusUnauthorized
+	w.WriteHeader(code)
+	_, _ = w.Write(response.ErrorBytes(code))
+}
+
+func forbidden(w http.ResponseWriter) {
+	code := http.StatusForbidden
+	w.WriteHeader(code)
+	_, _ = w.Write(response.ErrorBytes(code))
+}
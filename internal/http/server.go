@@ -5,7 +5,13 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/course-go/todos/internal/auth"
+	"github.com/course-go/todos/internal/http/controllers/attachments"
+	"github.com/course-go/todos/internal/http/controllers/comments"
 	"github.com/course-go/todos/internal/http/controllers/health"
+	"github.com/course-go/todos/internal/http/controllers/keys"
+	"github.com/course-go/todos/internal/http/controllers/lists"
+	"github.com/course-go/todos/internal/http/controllers/tags"
 	"github.com/course-go/todos/internal/http/controllers/todos"
 	"github.com/course-go/todos/internal/http/dto/response"
 	"github.com/course-go/todos/internal/http/metrics"
@@ -23,8 +29,14 @@ func NewServer(
 	logger *slog.Logger,
 	metrics *metrics.Metrics,
 	hostname string,
+	authenticate middleware.Middleware,
 	hc *health.Controller,
 	tc *todos.Controller,
+	tgc *tags.Controller,
+	lc *lists.Controller,
+	cc *comments.Controller,
+	ac *attachments.Controller,
+	kc *keys.Controller,
 ) (server *http.Server, err error) {
 	commonMiddleware := []middleware.Middleware{
 		middleware.Logging(logger),
@@ -34,6 +46,7 @@ func NewServer(
 		middleware.Logging(logger),
 		middleware.Metrics(metrics),
 		middleware.ContentType,
+		middleware.Actor,
 	}
 
 	mux := chi.NewRouter()
@@ -43,15 +56,62 @@ func NewServer(
 	mux.With(commonMiddleware...).Handle("/metrics", promhttp.Handler())
 	mux.Route("/api/v1", func(r chi.Router) {
 		r.Use(jsonMiddleware...)
+		// Health is left open for liveness and readiness probes.
 		r.Route("/healthz", func(r chi.Router) {
 			r.Get("/", hc.GetHealthController)
 		})
-		r.Route("/todos", func(r chi.Router) {
-			r.Get("/", tc.GetTodosController)
-			r.Get("/{id}", tc.GetTodoController)
-			r.Post("/", tc.CreateTodoController)
-			r.Put("/{id}", tc.UpdateTodoController)
-			r.Delete("/{id}", tc.DeleteTodoController)
+		r.Group(func(r chi.Router) {
+			r.Use(authenticate)
+			r.Post("/todos:batch", tc.BatchTodosController)
+			r.Route("/todos", func(r chi.Router) {
+				r.Get("/", tc.GetTodosController)
+				r.Get("/search", tc.SearchTodosController)
+				r.Get("/trash", tc.GetDeletedTodosController)
+				r.Get("/{id}", tc.GetTodoController)
+				r.Post("/", tc.CreateTodoController)
+				r.Put("/{id}", tc.UpdateTodoController)
+				r.Patch("/{id}", tc.PatchTodoController)
+				r.Delete("/{id}", tc.DeleteTodoController)
+				r.Post("/{id}/complete", tc.CompleteTodoController)
+				r.Post("/{id}/move", tc.MoveTodoController)
+				r.Post("/{id}/reopen", tc.ReopenTodoController)
+				r.Post("/{id}/restore", tc.RestoreTodoController)
+				r.Get("/{id}/history", tc.GetTodoHistoryController)
+				r.Get("/{id}/versions", tc.GetTodoVersionsController)
+				r.Post("/{id}/versions/{version}/revert", tc.RevertTodoController)
+				r.Get("/{id}/subtasks", tc.GetSubtasksController)
+				r.Post("/{id}/subtasks", tc.CreateSubtaskController)
+				r.Put("/{id}/subtasks/order", tc.ReorderSubtasksController)
+				r.Get("/{id}/comments", cc.GetCommentsController)
+				r.Post("/{id}/comments", cc.CreateCommentController)
+				r.Put("/{id}/comments/{commentId}", cc.UpdateCommentController)
+				r.Delete("/{id}/comments/{commentId}", cc.DeleteCommentController)
+				r.Get("/{id}/attachments", ac.GetAttachmentsController)
+				r.Get("/{id}/attachments/{attachmentId}", ac.GetAttachmentController)
+				r.Post("/{id}/attachments", ac.CreateAttachmentController)
+				r.Delete("/{id}/attachments/{attachmentId}", ac.DeleteAttachmentController)
+			})
+			r.Route("/tags", func(r chi.Router) {
+				r.Get("/", tgc.GetTagsController)
+				r.Get("/{id}", tgc.GetTagController)
+				r.Post("/", tgc.CreateTagController)
+				r.Put("/{id}", tgc.UpdateTagController)
+				r.Delete("/{id}", tgc.DeleteTagController)
+			})
+			r.Route("/lists", func(r chi.Router) {
+				r.Get("/", lc.GetListsController)
+				r.Get("/{id}", lc.GetListController)
+				r.Post("/", lc.CreateListController)
+				r.Put("/{id}", lc.UpdateListController)
+				r.Delete("/{id}", lc.DeleteListController)
+				r.Get("/{id}/todos", tc.GetListTodosController)
+			})
+			r.Route("/keys", func(r chi.Router) {
+				r.Use(middleware.RequireScope(auth.ScopeAdmin))
+				r.Get("/", kc.GetKeysController)
+				r.Post("/", kc.CreateKeyController)
+				r.Delete("/{id}", kc.RevokeKeyController)
+			})
 		})
 	})
 
@@ -0,0 +1,12 @@
+package purge
+
+import (
+	"context"
+
+	"github.com/course-go/todos/internal/health"
+)
+
+// Check runs the health check of the worker.
+func (w *Worker) Check(ctx context.Context, c *health.Component) {
+	w.check(ctx, c)
+}
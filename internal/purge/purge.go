@@ -0,0 +1,180 @@
+package purge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	stdtime "time"
+
+	"github.com/course-go/todos/internal/blob"
+	"github.com/course-go/todos/internal/config"
+	"github.com/course-go/todos/internal/health"
+	"github.com/course-go/todos/internal/repository"
+	"github.com/course-go/todos/internal/time"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+const purgeHealthCheckPeriod = 30 * stdtime.Second
+
+var ErrInvalidConfig = errors.New("invalid purge config")
+
+// Worker periodically removes todos which have been deleted for longer than the retention period
+// together with contents of their attachments.
+type Worker struct {
+	logger     *slog.Logger
+	repository repository.TodoRepository
+	store      blob.Store
+	config     *config.Purge
+	time       time.Factory
+	purged     metric.Int64Counter
+
+	mu      sync.Mutex
+	lastRun stdtime.Time
+	lastErr error
+}
+
+func New(
+	ctx context.Context,
+	logger *slog.Logger,
+	registry *health.Registry,
+	provider *sdkmetric.MeterProvider,
+	repository repository.TodoRepository,
+	store blob.Store,
+	config *config.Purge,
+	time time.Factory,
+) (worker *Worker, err error) {
+	if config.Interval <= 0 {
+		return nil, fmt.Errorf("%w: interval must be positive: %s", ErrInvalidConfig, config.Interval)
+	}
+
+	if config.BatchSize <= 0 {
+		return nil, fmt.Errorf("%w: batch size must be positive: %d", ErrInvalidConfig, config.BatchSize)
+	}
+
+	meter := provider.Meter("todos.purge")
+
+	purged, err := meter.Int64Counter("purged.total")
+	if err != nil {
+		return nil, fmt.Errorf("failed creating purged todos counter metric: %w", err)
+	}
+
+	worker = &Worker{
+		logger:     logger.With("component", "purge.worker"),
+		repository: repository,
+		store:      store,
+		config:     config,
+		time:       time,
+		purged:     purged,
+	}
+
+	checks := []health.Check{
+		{
+			Period:  purgeHealthCheckPeriod,
+			CheckFn: worker.check,
+		},
+	}
+	registry.RegisterComponent(ctx, health.NewComponent("purge", checks...))
+
+	return worker, nil
+}
+
+// Run purges deleted todos every configured interval until the context is done.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := stdtime.NewTicker(w.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		err := w.Purge(ctx)
+		if err != nil {
+			w.logger.Error("failed purging deleted todos",
+				"error", err,
+			)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Purge removes todos deleted before the retention period in batches.
+func (w *Worker) Purge(ctx context.Context) (err error) {
+	now := w.time()
+	deletedBefore := now.Add(-w.config.Retention)
+
+	defer func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+
+		w.lastRun = now
+		w.lastErr = err
+	}()
+
+	var total int64
+
+	for {
+		var (
+			purged      int64
+			attachments []uuid.UUID
+		)
+
+		purged, attachments, err = w.repository.PurgeTodos(ctx, deletedBefore, w.config.BatchSize)
+		if err != nil {
+			return fmt.Errorf("failed purging todos: %w", err)
+		}
+
+		w.purged.Add(ctx, purged)
+		w.deleteContents(ctx, attachments)
+
+		total += purged
+		if purged < int64(w.config.BatchSize) {
+			break
+		}
+	}
+
+	w.logger.Info("purged deleted todos",
+		"count", total,
+		"deletedBefore", deletedBefore,
+	)
+
+	return nil
+}
+
+// deleteContents removes contents of the purged attachments from the blob store.
+// The attachments no longer exist, so failures are only logged and leave orphaned contents behind.
+func (w *Worker) deleteContents(ctx context.Context, attachments []uuid.UUID) {
+	for _, id := range attachments {
+		err := w.store.Delete(ctx, id.String())
+		if err != nil {
+			w.logger.Error("failed deleting purged attachment content",
+				"error", err,
+				"id", id,
+			)
+		}
+	}
+}
+
+func (w *Worker) check(_ context.Context, c *health.Component) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	c.UpdatedAt = w.lastRun
+
+	switch {
+	case w.lastRun.IsZero():
+		c.Health = health.OK
+		c.Message = "purge has not run yet"
+	case w.lastErr != nil:
+		c.Health = health.ERROR
+		c.Message = w.lastErr.Error()
+	default:
+		c.Health = health.OK
+		c.Message = ""
+	}
+}
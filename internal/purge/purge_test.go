@@ -0,0 +1,200 @@
+package purge_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/course-go/todos/internal/blob"
+	"github.com/course-go/todos/internal/config"
+	"github.com/course-go/todos/internal/health"
+	"github.com/course-go/todos/internal/purge"
+	"github.com/course-go/todos/internal/repository"
+	"github.com/course-go/todos/internal/todos"
+	"github.com/course-go/todos/internal/utils/test"
+	"github.com/google/uuid"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+var errPurge = errors.New("purge failed")
+
+// failingRepository is repository whose purges always fail.
+type failingRepository struct {
+	*repository.MemoryRepository
+}
+
+func (failingRepository) PurgeTodos(context.Context, time.Time, int) (int64, []uuid.UUID, error) {
+	return 0, nil, errPurge
+}
+
+func TestWorker(t *testing.T) { //nolint: gocognit, cyclop
+	t.Parallel()
+
+	ctx := t.Context()
+	logger := test.NewTestLogger(t)
+	now := test.NewTimeNow(t)
+
+	registry, err := health.NewRegistry(ctx)
+	if err != nil {
+		t.Fatalf("could not create health registry: %v", err)
+	}
+
+	t.Run("Reject invalid config", func(t *testing.T) {
+		t.Parallel()
+
+		configs := map[string]config.Purge{
+			"zero interval":       {Interval: 0, BatchSize: 10},
+			"negative interval":   {Interval: -time.Hour, BatchSize: 10},
+			"zero batch size":     {Interval: time.Hour, BatchSize: 0},
+			"negative batch size": {Interval: time.Hour, BatchSize: -1},
+		}
+		for name, cfg := range configs {
+			r := test.NewTestMemoryRepository(t)
+			provider := sdkmetric.NewMeterProvider()
+
+			_, err := purge.New(ctx, logger, registry, provider, r, newStore(t), &cfg, now)
+			if !errors.Is(err, purge.ErrInvalidConfig) {
+				t.Errorf("%s should be rejected: expected: %v != actual: %v", name, purge.ErrInvalidConfig, err)
+			}
+		}
+	})
+
+	t.Run("Purge deleted todos in batches", func(t *testing.T) {
+		t.Parallel()
+
+		r := test.NewTestMemoryRepository(t)
+		store := newStore(t)
+		reader := sdkmetric.NewManualReader()
+		provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+		todoID := uuid.MustParse("62446c85-3798-471f-abb8-75c1cdd7153b")
+
+		attachment, err := r.CreateAttachment(
+			ctx,
+			todos.Attachment{TodoID: todoID, Name: "receipt.txt", CreatedAt: now()},
+		)
+		if err != nil {
+			t.Fatalf("could not create attachment: %v", err)
+		}
+
+		err = store.Put(ctx, attachment.ID.String(), strings.NewReader("Mop"), 3, "text/plain")
+		if err != nil {
+			t.Fatalf("could not store attachment content: %v", err)
+		}
+
+		err = r.DeleteTodo(ctx, todoID, 0, now().Add(-time.Hour))
+		if err != nil {
+			t.Fatalf("could not delete todo: %v", err)
+		}
+
+		cfg := config.Purge{Retention: time.Minute, Interval: time.Hour, BatchSize: 1}
+
+		worker, err := purge.New(ctx, logger, registry, provider, r, store, &cfg, now)
+		if err != nil {
+			t.Fatalf("could not create purge worker: %v", err)
+		}
+
+		err = worker.Purge(ctx)
+		if err != nil {
+			t.Fatalf("could not purge todos: %v", err)
+		}
+
+		deletedTodos, err := r.GetDeletedTodos(ctx)
+		if err != nil {
+			t.Fatalf("could not get deleted todos: %v", err)
+		}
+
+		if len(deletedTodos) != 0 {
+			t.Errorf("all deleted todos should be purged: %v", deletedTodos)
+		}
+
+		_, err = store.Get(ctx, attachment.ID.String())
+		if !errors.Is(err, blob.ErrBlobNotFound) {
+			t.Errorf("attachment content should be deleted: expected: %v != actual: %v", blob.ErrBlobNotFound, err)
+		}
+
+		var expectedPurged int64 = 3
+		if purged := collectPurged(ctx, t, reader); purged != expectedPurged {
+			t.Errorf("purged todos metric does not match: expected: %d != actual: %d", expectedPurged, purged)
+		}
+
+		c := health.NewComponent("purge")
+		worker.Check(ctx, c)
+
+		report := c.Report()
+		if report.Health != health.OK || !report.UpdatedAt.Equal(now()) {
+			t.Errorf("purge should be healthy: %+v", report)
+		}
+	})
+
+	t.Run("Report failed purge", func(t *testing.T) {
+		t.Parallel()
+
+		r := failingRepository{test.NewTestMemoryRepository(t)}
+		cfg := config.Purge{Retention: time.Minute, Interval: time.Hour, BatchSize: 1}
+
+		worker, err := purge.New(ctx, logger, registry, sdkmetric.NewMeterProvider(), r, newStore(t), &cfg, now)
+		if err != nil {
+			t.Fatalf("could not create purge worker: %v", err)
+		}
+
+		c := health.NewComponent("purge")
+		worker.Check(ctx, c)
+
+		if report := c.Report(); report.Health != health.OK {
+			t.Errorf("purge should be healthy before its first run: %+v", report)
+		}
+
+		err = worker.Purge(ctx)
+		if !errors.Is(err, errPurge) {
+			t.Fatalf("purge should fail: expected: %v != actual: %v", errPurge, err)
+		}
+
+		worker.Check(ctx, c)
+
+		if report := c.Report(); report.Health != health.ERROR {
+			t.Errorf("purge should be unhealthy after failed run: %+v", report)
+		}
+	})
+}
+
+func newStore(t *testing.T) blob.LocalStore {
+	t.Helper()
+
+	store, err := blob.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("could not create blob store: %v", err)
+	}
+
+	return store
+}
+
+// collectPurged returns the value of the purged todos counter.
+func collectPurged(ctx context.Context, t *testing.T, reader sdkmetric.Reader) (purged int64) {
+	t.Helper()
+
+	var rm metricdata.ResourceMetrics
+
+	err := reader.Collect(ctx, &rm)
+	if err != nil {
+		t.Fatalf("could not collect metrics: %v", err)
+	}
+
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			sum, ok := m.Data.(metricdata.Sum[int64])
+			if m.Name != "purged.total" || !ok {
+				continue
+			}
+
+			for _, point := range sum.DataPoints {
+				purged += point.Value
+			}
+		}
+	}
+
+	return purged
+}
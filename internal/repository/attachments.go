@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/course-go/todos/internal/todos"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// attachmentColumns are the columns mapped to [todos.Attachment] fields.
+const attachmentColumns = "id, todo_id, name, content_type, size, created_at"
+
+var ErrAttachmentNotFound = errors.New("attachment with given UUID does not exist")
+
+// AttachmentRepository stores metadata of files attached to todos.
+// Attachments of deleted todos are treated as not existing until the todo is restored.
+// Deleted attachments are removed immediately and so are the attachments of purged todos.
+// Contents of attachments are kept in blob storage and are never touched by the repository.
+type AttachmentRepository interface {
+	GetAttachments(ctx context.Context, todoID uuid.UUID) ([]todos.Attachment, error)
+	GetAttachment(ctx context.Context, todoID uuid.UUID, id uuid.UUID) (todos.Attachment, error)
+	CreateAttachment(ctx context.Context, attachment todos.Attachment) (todos.Attachment, error)
+	DeleteAttachment(ctx context.Context, todoID uuid.UUID, id uuid.UUID) error
+}
+
+// GetAttachments returns attachments of the todo in the order they were created.
+func (r Repository) GetAttachments(ctx context.Context, todoID uuid.UUID) (a []todos.Attachment, err error) {
+	_, err = getTodo(ctx, r.pool, todoID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.pool.Query(ctx,
+		`
+		SELECT `+attachmentColumns+`
+		FROM attachments
+		WHERE todo_id=$1
+		ORDER BY created_at, id
+		`,
+		todoID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed querying database: %w", err)
+	}
+
+	// Use append to avoid returning nil slice
+	a = make([]todos.Attachment, 0)
+
+	a, err = pgx.AppendRows(a, rows, pgx.RowToStructByName[todos.Attachment])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return a, nil
+}
+
+func (r Repository) GetAttachment(
+	ctx context.Context,
+	todoID uuid.UUID,
+	id uuid.UUID,
+) (attachment todos.Attachment, err error) {
+	_, err = getTodo(ctx, r.pool, todoID)
+	if err != nil {
+		return todos.Attachment{}, err
+	}
+
+	rows, err := r.pool.Query(ctx,
+		`
+		SELECT `+attachmentColumns+`
+		FROM attachments
+		WHERE id=$1 AND todo_id=$2
+		`,
+		id,
+		todoID,
+	)
+	if err != nil {
+		return todos.Attachment{}, fmt.Errorf("failed querying database: %w", err)
+	}
+
+	attachment, err = pgx.CollectOneRow(rows, pgx.RowToStructByName[todos.Attachment])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return todos.Attachment{}, ErrAttachmentNotFound
+	}
+
+	if err != nil {
+		return todos.Attachment{}, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return attachment, nil
+}
+
+func (r Repository) CreateAttachment(
+	ctx context.Context,
+	attachment todos.Attachment,
+) (createdAttachment todos.Attachment, err error) {
+	err = pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		_, err = getTodo(ctx, tx, attachment.TodoID)
+		if err != nil {
+			return err
+		}
+
+		rows, err := tx.Query(ctx,
+			`
+			INSERT INTO attachments (todo_id, name, content_type, size, created_at)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING `+attachmentColumns,
+			attachment.TodoID,
+			attachment.Name,
+			attachment.ContentType,
+			attachment.Size,
+			attachment.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed querying database: %w", err)
+		}
+
+		createdAttachment, err = pgx.CollectOneRow(rows, pgx.RowToStructByName[todos.Attachment])
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrDatabase, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return todos.Attachment{}, fmt.Errorf("failed creating attachment: %w", err)
+	}
+
+	return createdAttachment, nil
+}
+
+// DeleteAttachment removes the attachment.
+func (r Repository) DeleteAttachment(ctx context.Context, todoID uuid.UUID, id uuid.UUID) error {
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		_, err := getTodo(ctx, tx, todoID)
+		if err != nil {
+			return err
+		}
+
+		c, err := tx.Exec(ctx,
+			`
+			DELETE FROM attachments
+			WHERE id=$1 AND todo_id=$2
+			`,
+			id,
+			todoID,
+		)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrDatabase, err)
+		}
+
+		if c.RowsAffected() == 0 {
+			return ErrAttachmentNotFound
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed deleting attachment: %w", err)
+	}
+
+	return nil
+}
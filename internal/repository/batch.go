@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/course-go/todos/internal/todos"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrUnknownBatchOperation = errors.New("unknown batch operation")
+	ErrBatchAborted          = errors.New("batch was aborted due to failure of another operation")
+)
+
+type BatchOperationKind string
+
+const (
+	BatchCreate BatchOperationKind = "create"
+	BatchUpdate BatchOperationKind = "update"
+	BatchDelete BatchOperationKind = "delete"
+)
+
+// BatchOperation creates, updates or deletes a single todo.
+// Deletions only use ID and Version of the todo.
+type BatchOperation struct {
+	Kind BatchOperationKind
+	Todo todos.Todo
+}
+
+// BatchResult holds the todo affected by the operation or the reason the operation failed.
+type BatchResult struct {
+	Todo todos.Todo
+	Err  error
+}
+
+// ExecuteBatch executes the operations in a single transaction.
+// In atomic mode the first failed operation rolls back the whole batch
+// and the results of all other operations are set to [ErrBatchAborted].
+// Otherwise, only the failed operations are rolled back.
+// The returned error is only set if the transaction itself fails.
+func (r Repository) ExecuteBatch(
+	ctx context.Context,
+	operations []BatchOperation,
+	atomic bool,
+	now time.Time,
+) (results []BatchResult, err error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	results = make([]BatchResult, len(operations))
+	for i, operation := range operations {
+		// Each operation runs in its own savepoint so it can be rolled back alone.
+		savepoint, err := tx.Begin(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+		}
+
+		results[i] = executeOperation(ctx, savepoint, operation, now)
+		if results[i].Err != nil {
+			_ = savepoint.Rollback(ctx)
+
+			if atomic {
+				return abortBatch(results, i), nil
+			}
+
+			continue
+		}
+
+		err = savepoint.Commit(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+		}
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return results, nil
+}
+
+func executeOperation(ctx context.Context, tx pgx.Tx, operation BatchOperation, now time.Time) BatchResult {
+	var (
+		todo todos.Todo
+		err  error
+	)
+
+	switch operation.Kind {
+	case BatchCreate:
+		operation.Todo.CreatedAt = now
+		todo, err = createTodo(ctx, tx, operation.Todo)
+	case BatchUpdate:
+		operation.Todo.UpdatedAt = &now
+		todo, err = saveTodo(ctx, tx, operation.Todo)
+	case BatchDelete:
+		todo = todos.Todo{ID: operation.Todo.ID}
+		err = deleteTodo(ctx, tx, operation.Todo.ID, operation.Todo.Version, now)
+	default:
+		err = fmt.Errorf("%w: %q", ErrUnknownBatchOperation, operation.Kind)
+	}
+
+	return BatchResult{
+		Todo: todo,
+		Err:  err,
+	}
+}
+
+// abortBatch marks results of all operations except the failed one as aborted.
+func abortBatch(results []BatchResult, failed int) []BatchResult {
+	for i := range results {
+		if i != failed {
+			results[i] = BatchResult{Err: ErrBatchAborted}
+		}
+	}
+
+	return results
+}
@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/course-go/todos/internal/todos"
+	"github.com/google/uuid"
+)
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// dialect is the SQL dialect the queries are built for.
+type dialect int
+
+const (
+	postgresDialect dialect = iota
+	sqliteDialect
+)
+
+// queryBuilder collects conditions and arguments of dynamically built queries.
+type queryBuilder struct {
+	dialect    dialect
+	conditions []string
+	args       []any
+}
+
+// arg registers query argument and returns its placeholder.
+func (b *queryBuilder) arg(value any) string {
+	if b.dialect == sqliteDialect {
+		value = sqliteValue(value)
+	}
+
+	b.args = append(b.args, value)
+
+	return "$" + strconv.Itoa(len(b.args))
+}
+
+// typedArg registers query argument and returns its placeholder cast to the given Postgres type.
+// SQLite does not need the casts since its columns are not strictly typed.
+func (b *queryBuilder) typedArg(value any, cast string) string {
+	placeholder := b.arg(value)
+	if b.dialect == sqliteDialect {
+		return placeholder
+	}
+
+	return placeholder + "::" + cast
+}
+
+func (b *queryBuilder) where(condition string) {
+	b.conditions = append(b.conditions, condition)
+}
+
+func (b *queryBuilder) whereClause() string {
+	if len(b.conditions) == 0 {
+		return ""
+	}
+
+	return "WHERE " + strings.Join(b.conditions, " AND ")
+}
+
+// containsText adds condition matching column containing the text regardless of case.
+func (b *queryBuilder) containsText(column, text string) {
+	pattern := "'%' || " + b.typedArg(likeEscaper.Replace(text), "text") + " || '%'"
+	if b.dialect == sqliteDialect {
+		// SQLite LIKE is case-insensitive by default, but has no default escape character.
+		b.where(column + " LIKE " + pattern + ` ESCAPE '\'`)
+		return
+	}
+
+	b.where(column + " ILIKE " + pattern)
+}
+
+func filterTodos(b *queryBuilder, filter TodosFilter) {
+	if filter.ListID != nil {
+		b.where("list_id = " + b.arg(*filter.ListID))
+	}
+
+	if filter.Completed != nil {
+		if *filter.Completed {
+			b.where("completed_at IS NOT NULL")
+		} else {
+			b.where("completed_at IS NULL")
+		}
+	}
+
+	if filter.Overdue != nil {
+		if *filter.Overdue {
+			b.where("completed_at IS NULL AND due_at < " + b.arg(filter.Now))
+		} else {
+			b.where("(completed_at IS NOT NULL OR due_at IS NULL OR due_at >= " + b.arg(filter.Now) + ")")
+		}
+	}
+
+	filterTimeRange(b, "created_at", filter.CreatedAfter, filter.CreatedBefore)
+	filterTimeRange(b, "completed_at", filter.CompletedAfter, nil)
+	filterTimeRange(b, "due_at", filter.DueAfter, filter.DueBefore)
+
+	if len(filter.Priorities) > 0 {
+		placeholders := make([]string, 0, len(filter.Priorities))
+		for _, priority := range filter.Priorities {
+			placeholders = append(placeholders, b.arg(priority))
+		}
+
+		b.where("priority IN (" + strings.Join(placeholders, ", ") + ")")
+	}
+
+	if len(filter.Tags) > 0 {
+		filterTags(b, normalizeTags(filter.Tags), filter.AllTags)
+	}
+
+	if filter.Text != "" {
+		b.containsText("description", filter.Text)
+	}
+}
+
+// filterTags adds condition matching todos with any or all of the distinct tags.
+func filterTags(b *queryBuilder, tags []string, all bool) {
+	placeholders := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		placeholders = append(placeholders, b.arg(tag))
+	}
+
+	matching := `(
+		SELECT COUNT(*)
+		FROM todo_tags JOIN tags ON tags.id = todo_tags.tag_id
+		WHERE todo_tags.todo_id = todos.id AND tags.name IN (` + strings.Join(placeholders, ", ") + `)
+	)`
+	if all {
+		b.where(matching + " = " + b.arg(len(tags)))
+		return
+	}
+
+	b.where(matching + " > 0")
+}
+
+// filterTimeRange adds conditions matching column within the optional inclusive lower and exclusive upper bound.
+func filterTimeRange(b *queryBuilder, column string, after, before *time.Time) {
+	if after != nil {
+		b.where(column + " >= " + b.arg(*after))
+	}
+
+	if before != nil {
+		b.where(column + " < " + b.arg(*before))
+	}
+}
+
+// sqliteValue converts value to the representation stored by SQLite.
+func sqliteValue(value any) any {
+	switch v := value.(type) {
+	case time.Time:
+		return formatSQLiteTime(v)
+	case *time.Time:
+		if v == nil {
+			return nil
+		}
+
+		return formatSQLiteTime(*v)
+	case uuid.UUID:
+		return v.String()
+	case *uuid.UUID:
+		if v == nil {
+			return nil
+		}
+
+		return v.String()
+	case *todos.Todo:
+		return sqliteTodoValue(v)
+	default:
+		return value
+	}
+}
+
+// sqliteTodoValue converts todo to JSON text stored by SQLite.
+func sqliteTodoValue(todo *todos.Todo) any {
+	if todo == nil {
+		return nil
+	}
+
+	// Unencodable todo is passed on to be rejected by the driver.
+	text, err := json.Marshal(todo)
+	if err != nil {
+		return todo
+	}
+
+	return string(text)
+}
@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/course-go/todos/internal/todos"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// commentColumns are the columns mapped to [todos.Comment] fields.
+const commentColumns = "id, todo_id, author, body, created_at, updated_at"
+
+var ErrCommentNotFound = errors.New("comment with given UUID does not exist")
+
+// CommentRepository stores comments of todos. Deleted comments are treated as not existing.
+// Comments of deleted todos are treated as not existing as well until the todo is restored.
+type CommentRepository interface {
+	GetComments(ctx context.Context, todoID uuid.UUID) ([]todos.Comment, error)
+	CreateComment(ctx context.Context, comment todos.Comment) (todos.Comment, error)
+	SaveComment(ctx context.Context, comment todos.Comment) (todos.Comment, error)
+	DeleteComment(ctx context.Context, todoID uuid.UUID, id uuid.UUID, deletedAt time.Time) error
+}
+
+// GetComments returns comments of the todo in the order they were created.
+func (r Repository) GetComments(ctx context.Context, todoID uuid.UUID) (c []todos.Comment, err error) {
+	_, err = getTodo(ctx, r.pool, todoID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.pool.Query(ctx,
+		`
+		SELECT `+commentColumns+`
+		FROM comments
+		WHERE todo_id=$1 AND deleted_at IS NULL
+		ORDER BY created_at, id
+		`,
+		todoID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed querying database: %w", err)
+	}
+
+	// Use append to avoid returning nil slice
+	c = make([]todos.Comment, 0)
+
+	c, err = pgx.AppendRows(c, rows, pgx.RowToStructByName[todos.Comment])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return c, nil
+}
+
+func (r Repository) CreateComment(
+	ctx context.Context,
+	comment todos.Comment,
+) (createdComment todos.Comment, err error) {
+	err = pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		_, err = getTodo(ctx, tx, comment.TodoID)
+		if err != nil {
+			return err
+		}
+
+		rows, err := tx.Query(ctx,
+			`
+			INSERT INTO comments (todo_id, author, body, created_at)
+			VALUES ($1, $2, $3, $4)
+			RETURNING `+commentColumns,
+			comment.TodoID,
+			comment.Author,
+			comment.Body,
+			comment.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed querying database: %w", err)
+		}
+
+		createdComment, err = pgx.CollectOneRow(rows, pgx.RowToStructByName[todos.Comment])
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrDatabase, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return todos.Comment{}, fmt.Errorf("failed creating comment: %w", err)
+	}
+
+	return createdComment, nil
+}
+
+// SaveComment changes body of the comment. The author of the comment cannot be changed.
+func (r Repository) SaveComment(ctx context.Context, comment todos.Comment) (savedComment todos.Comment, err error) {
+	err = pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		_, err = getTodo(ctx, tx, comment.TodoID)
+		if err != nil {
+			return err
+		}
+
+		rows, err := tx.Query(ctx,
+			`
+			UPDATE comments
+			SET body = $3, updated_at = $4
+			WHERE id=$1 AND todo_id=$2 AND deleted_at IS NULL
+			RETURNING `+commentColumns,
+			comment.ID,
+			comment.TodoID,
+			comment.Body,
+			comment.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed querying database: %w", err)
+		}
+
+		savedComment, err = pgx.CollectOneRow(rows, pgx.RowToStructByName[todos.Comment])
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrCommentNotFound
+		}
+
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrDatabase, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return todos.Comment{}, fmt.Errorf("failed saving comment: %w", err)
+	}
+
+	return savedComment, nil
+}
+
+// DeleteComment marks the comment as deleted.
+func (r Repository) DeleteComment(ctx context.Context, todoID uuid.UUID, id uuid.UUID, deletedAt time.Time) error {
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		_, err := getTodo(ctx, tx, todoID)
+		if err != nil {
+			return err
+		}
+
+		c, err := tx.Exec(ctx,
+			`
+			UPDATE comments
+			SET deleted_at = $3
+			WHERE id=$1 AND todo_id=$2 AND deleted_at IS NULL
+			`,
+			id,
+			todoID,
+			deletedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrDatabase, err)
+		}
+
+		if c.RowsAffected() == 0 {
+			return ErrCommentNotFound
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed deleting comment: %w", err)
+	}
+
+	return nil
+}
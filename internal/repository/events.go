@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/course-go/todos/internal/actor"
+	"github.com/course-go/todos/internal/todos"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// eventColumns are the columns mapped to [todos.Event] fields.
+const eventColumns = "id, todo_id, type, actor, before, after, occurred_at"
+
+// EventRepository provides history of todos. Events are recorded in the same transaction
+// as the creation, update, completion, reopening, move, deletion or restoration of the todo
+// by the actor from the context. Subtasks deleted together with their parent and todos deleted
+// together with their list have their own deletion events.
+type EventRepository interface {
+	GetTodoEvents(ctx context.Context, todoID uuid.UUID) ([]todos.Event, error)
+}
+
+// GetTodoEvents returns history of the todo, the oldest event first.
+// History of deleted todos is kept until they are purged.
+func (r Repository) GetTodoEvents(ctx context.Context, todoID uuid.UUID) (e []todos.Event, err error) {
+	var exists bool
+
+	err = r.pool.QueryRow(ctx,
+		`
+		SELECT EXISTS (SELECT 1 FROM todos WHERE id=$1)
+		`,
+		todoID,
+	).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	if !exists {
+		return nil, ErrTodoNotFound
+	}
+
+	rows, err := r.pool.Query(ctx,
+		`
+		SELECT `+eventColumns+`
+		FROM todo_events
+		WHERE todo_id=$1
+		ORDER BY occurred_at, seq
+		`,
+		todoID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed querying database: %w", err)
+	}
+
+	// Use append to avoid returning nil slice
+	e = make([]todos.Event, 0)
+
+	e, err = pgx.AppendRows(e, rows, pgx.RowToStructByName[todos.Event])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return e, nil
+}
+
+// newEvent describes the change of the todo made by the actor from the context.
+func newEvent(
+	ctx context.Context,
+	eventType todos.EventType,
+	before *todos.Todo,
+	after *todos.Todo,
+	occurredAt time.Time,
+) todos.Event {
+	event := todos.Event{
+		ID:         uuid.New(),
+		Type:       eventType,
+		Actor:      actor.From(ctx),
+		Before:     before,
+		After:      after,
+		OccurredAt: occurredAt,
+	}
+
+	if after != nil {
+		event.TodoID = after.ID
+	} else if before != nil {
+		event.TodoID = before.ID
+	}
+
+	return event
+}
+
+// recordEvent stores the event. The querier should be the transaction
+// changing the todo so that the change is never made without its event.
+func recordEvent(ctx context.Context, q querier, event todos.Event) error {
+	_, err := q.Exec(ctx,
+		`
+		INSERT INTO todo_events (id, todo_id, type, actor, before, after, occurred_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`,
+		event.ID,
+		event.TodoID,
+		event.Type,
+		event.Actor,
+		event.Before,
+		event.After,
+		event.OccurredAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed recording event: %w: %w", ErrDatabase, err)
+	}
+
+	return nil
+}
+
+// lockTodo returns the todo locking it until the end of the transaction
+// so that the todo recorded before the change is the one being changed.
+func lockTodo(ctx context.Context, q querier, id uuid.UUID) (todos.Todo, error) {
+	_, err := q.Exec(ctx,
+		`
+		SELECT 1 FROM todos WHERE id=$1 FOR UPDATE
+		`,
+		id,
+	)
+	if err != nil {
+		return todos.Todo{}, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return getTodo(ctx, q, id)
+}
+
+// lockDeletedTodo returns the deleted todo locking it like [lockTodo].
+func lockDeletedTodo(ctx context.Context, q querier, id uuid.UUID) (todos.Todo, error) {
+	_, err := q.Exec(ctx,
+		`
+		SELECT 1 FROM todos WHERE id=$1 FOR UPDATE
+		`,
+		id,
+	)
+	if err != nil {
+		return todos.Todo{}, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	rows, err := q.Query(ctx,
+		`
+		SELECT `+postgresTodoColumns+`
+		FROM todos
+		WHERE id=$1 AND deleted_at IS NOT NULL
+		`,
+		id,
+	)
+	if err != nil {
+		return todos.Todo{}, fmt.Errorf("failed querying database: %w", err)
+	}
+
+	return collectChangedTodo(rows)
+}
+
+// changedAt returns time of the change or zero time if it is not known.
+func changedAt(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+
+	return *t
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/course-go/todos/internal/auth"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// apiKeyColumns are the columns mapped to [auth.Key] fields.
+const apiKeyColumns = "id, name, hash, scopes, expires_at, last_used_at, created_at, revoked_at" //nolint: gosec
+
+var (
+	ErrAPIKeyNotFound = errors.New("api key with given UUID does not exist")
+	ErrInvalidAPIKey  = errors.New("api key does not exist, has expired or was revoked")
+)
+
+// APIKeyRepository stores API keys. Revoked keys are kept but cannot be used nor revoked again.
+type APIKeyRepository interface {
+	GetAPIKeys(ctx context.Context) ([]auth.Key, error)
+	CreateAPIKey(ctx context.Context, key auth.Key) (auth.Key, error)
+	RevokeAPIKey(ctx context.Context, id uuid.UUID, revokedAt time.Time) error
+	UseAPIKey(ctx context.Context, hash string, usedAt time.Time) (auth.Key, error)
+}
+
+// GetAPIKeys returns all keys including the revoked ones in the order they were created.
+func (r Repository) GetAPIKeys(ctx context.Context) (k []auth.Key, err error) {
+	rows, err := r.pool.Query(ctx,
+		`
+		SELECT `+apiKeyColumns+`
+		FROM api_keys
+		ORDER BY created_at, id
+		`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed querying database: %w", err)
+	}
+
+	// Use append to avoid returning nil slice
+	k = make([]auth.Key, 0)
+
+	k, err = pgx.AppendRows(k, rows, pgx.RowToStructByName[auth.Key])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return k, nil
+}
+
+func (r Repository) CreateAPIKey(ctx context.Context, key auth.Key) (createdKey auth.Key, err error) {
+	rows, err := r.pool.Query(ctx,
+		`
+		INSERT INTO api_keys (name, hash, scopes, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING `+apiKeyColumns,
+		key.Name,
+		key.Hash,
+		key.Scopes,
+		key.ExpiresAt,
+		key.CreatedAt,
+	)
+	if err != nil {
+		return auth.Key{}, fmt.Errorf("failed querying database: %w", err)
+	}
+
+	createdKey, err = pgx.CollectOneRow(rows, pgx.RowToStructByName[auth.Key])
+	if err != nil {
+		return auth.Key{}, fmt.Errorf("failed creating api key: %w: %w", ErrDatabase, err)
+	}
+
+	return createdKey, nil
+}
+
+// RevokeAPIKey marks the key as revoked so that it can no longer be used.
+func (r Repository) RevokeAPIKey(ctx context.Context, id uuid.UUID, revokedAt time.Time) error {
+	c, err := r.pool.Exec(ctx,
+		`
+		UPDATE api_keys
+		SET revoked_at = $2
+		WHERE id=$1 AND revoked_at IS NULL
+		`,
+		id,
+		revokedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed revoking api key: %w: %w", ErrDatabase, err)
+	}
+
+	if c.RowsAffected() == 0 {
+		return ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+// UseAPIKey returns the key with the given hash and records it was used.
+// Keys which have expired or were revoked are treated as not existing.
+func (r Repository) UseAPIKey(ctx context.Context, hash string, usedAt time.Time) (key auth.Key, err error) {
+	rows, err := r.pool.Query(ctx,
+		`
+		UPDATE api_keys
+		SET last_used_at = $2
+		WHERE hash=$1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > $2)
+		RETURNING `+apiKeyColumns,
+		hash,
+		usedAt,
+	)
+	if err != nil {
+		return auth.Key{}, fmt.Errorf("failed querying database: %w", err)
+	}
+
+	key, err = pgx.CollectOneRow(rows, pgx.RowToStructByName[auth.Key])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return auth.Key{}, ErrInvalidAPIKey
+	}
+
+	if err != nil {
+		return auth.Key{}, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return key, nil
+}
@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/course-go/todos/internal/todos"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// listColumns are the columns mapped to [todos.List] fields.
+const listColumns = "id, name, color, archived, created_at, updated_at"
+
+var ErrListNotFound = errors.New("list with given UUID does not exist")
+
+// ListRepository stores lists grouping todos. Deleted lists are treated as not existing.
+type ListRepository interface {
+	GetLists(ctx context.Context) ([]todos.List, error)
+	GetList(ctx context.Context, id uuid.UUID) (todos.List, error)
+	CreateList(ctx context.Context, list todos.List) (todos.List, error)
+	SaveList(ctx context.Context, list todos.List) (todos.List, error)
+	DeleteList(ctx context.Context, id uuid.UUID, deletedAt time.Time) error
+}
+
+// GetLists returns all lists in the order they were created.
+func (r Repository) GetLists(ctx context.Context) (l []todos.List, err error) {
+	rows, err := r.pool.Query(ctx,
+		`
+		SELECT `+listColumns+`
+		FROM lists
+		WHERE deleted_at IS NULL
+		ORDER BY created_at, id
+		`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed querying database: %w", err)
+	}
+
+	// Use append to avoid returning nil slice
+	l = make([]todos.List, 0)
+
+	l, err = pgx.AppendRows(l, rows, pgx.RowToStructByName[todos.List])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return l, nil
+}
+
+func (r Repository) GetList(ctx context.Context, id uuid.UUID) (l todos.List, err error) {
+	rows, err := r.pool.Query(ctx,
+		`
+		SELECT `+listColumns+`
+		FROM lists
+		WHERE id=$1 AND deleted_at IS NULL
+		`,
+		id,
+	)
+	if err != nil {
+		return todos.List{}, fmt.Errorf("failed querying database: %w", err)
+	}
+
+	l, err = pgx.CollectOneRow(rows, pgx.RowToStructByName[todos.List])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return todos.List{}, ErrListNotFound
+	}
+
+	if err != nil {
+		return todos.List{}, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return l, nil
+}
+
+func (r Repository) CreateList(ctx context.Context, list todos.List) (createdList todos.List, err error) {
+	rows, err := r.pool.Query(ctx,
+		`
+		INSERT INTO lists (name, color, archived, created_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING `+listColumns,
+		list.Name,
+		list.Color,
+		list.Archived,
+		list.CreatedAt,
+	)
+	if err != nil {
+		return todos.List{}, fmt.Errorf("failed querying database: %w", err)
+	}
+
+	createdList, err = pgx.CollectOneRow(rows, pgx.RowToStructByName[todos.List])
+	if err != nil {
+		return todos.List{}, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return createdList, nil
+}
+
+func (r Repository) SaveList(ctx context.Context, list todos.List) (savedList todos.List, err error) {
+	rows, err := r.pool.Query(ctx,
+		`
+		UPDATE lists
+		SET name = $2, color = $3, archived = $4, updated_at = $5
+		WHERE id=$1 AND deleted_at IS NULL
+		RETURNING `+listColumns,
+		list.ID,
+		list.Name,
+		list.Color,
+		list.Archived,
+		list.UpdatedAt,
+	)
+	if err != nil {
+		return todos.List{}, fmt.Errorf("failed querying database: %w", err)
+	}
+
+	savedList, err = pgx.CollectOneRow(rows, pgx.RowToStructByName[todos.List])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return todos.List{}, ErrListNotFound
+	}
+
+	if err != nil {
+		return todos.List{}, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return savedList, nil
+}
+
+// DeleteList marks the list and all of its todos including their subtasks as deleted.
+func (r Repository) DeleteList(ctx context.Context, id uuid.UUID, deletedAt time.Time) error {
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		c, err := tx.Exec(ctx,
+			`
+			UPDATE lists
+			SET deleted_at = $2
+			WHERE id=$1 AND deleted_at IS NULL
+			`,
+			id,
+			deletedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrDatabase, err)
+		}
+
+		if c.RowsAffected() == 0 {
+			return ErrListNotFound
+		}
+
+		rows, err := tx.Query(ctx,
+			`
+			WITH RECURSIVE deleted AS (
+				SELECT id FROM todos WHERE list_id=$1 AND deleted_at IS NULL
+				UNION
+				SELECT todos.id FROM todos JOIN deleted ON todos.parent_id = deleted.id
+				WHERE todos.deleted_at IS NULL
+			)
+			SELECT `+postgresTodoColumns+`
+			FROM todos
+			WHERE id IN (SELECT id FROM deleted)
+			ORDER BY id
+			`,
+			id,
+		)
+		if err != nil {
+			return fmt.Errorf("failed querying database: %w", err)
+		}
+
+		deletedTodos, err := pgx.CollectRows(rows, pgx.RowToStructByName[todos.Todo])
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrDatabase, err)
+		}
+
+		return deleteTodos(ctx, tx, deletedTodos, deletedAt)
+	})
+	if err != nil {
+		return fmt.Errorf("failed deleting list: %w", err)
+	}
+
+	return nil
+}
+
+// checkList verifies that the optional list of a todo exists.
+func checkList(ctx context.Context, q querier, id *uuid.UUID) error {
+	if id == nil {
+		return nil
+	}
+
+	var exists bool
+
+	err := q.QueryRow(ctx,
+		`
+		SELECT EXISTS (SELECT 1 FROM lists WHERE id=$1 AND deleted_at IS NULL)
+		`,
+		id,
+	).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	if !exists {
+		return ErrListNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,1843 @@
+package repository
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/course-go/todos/internal/auth"
+	"github.com/course-go/todos/internal/todos"
+	"github.com/google/uuid"
+)
+
+// MemoryRepository keeps todos in memory only. It is safe for concurrent use.
+// Unlike the database, it searches todos by plain case-insensitive substring matching.
+type MemoryRepository struct {
+	mu          sync.RWMutex
+	todos       memoryTodos
+	tags        memoryTags
+	lists       memoryLists
+	comments    memoryComments
+	attachments memoryAttachments
+	events      memoryEvents
+	versions    memoryVersions
+	apiKeys     memoryAPIKeys
+}
+
+var _ TodoRepository = (*MemoryRepository)(nil)
+
+// NewMemory creates in-memory repository containing the given todos.
+func NewMemory(initial ...todos.Todo) *MemoryRepository {
+	return NewMemoryWithTags(nil, initial...)
+}
+
+// NewMemoryWithTags creates in-memory repository containing the given tags and todos.
+// Tags of the todos which are not given are created.
+func NewMemoryWithTags(tags []todos.Tag, initial ...todos.Todo) *MemoryRepository {
+	m := &MemoryRepository{
+		todos:       make(memoryTodos, len(initial)),
+		tags:        make(memoryTags, len(tags)),
+		lists:       make(memoryLists),
+		comments:    make(memoryComments),
+		attachments: make(memoryAttachments),
+		events:      make(memoryEvents),
+		versions:    make(memoryVersions),
+		apiKeys:     make(memoryAPIKeys),
+	}
+	for _, tag := range tags {
+		m.tags[tag.ID] = tag
+	}
+
+	for _, todo := range initial {
+		if todo.Version == 0 {
+			todo.Version = 1
+		}
+
+		if todo.Position == "" {
+			todo.Position = m.todos.appendPosition(todo.CreatedAt)
+		}
+
+		m.tags.ensure(todo.Tags)
+		m.todos[todo.ID] = normalizeTodo(todo)
+	}
+
+	return m
+}
+
+func (m *MemoryRepository) GetTodos(
+	_ context.Context,
+	query TodosQuery,
+) (t []todos.Todo, next *Cursor, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	sort := query.Sort
+	if len(sort) == 0 {
+		sort = DefaultSort()
+	}
+
+	t = make([]todos.Todo, 0)
+
+	for _, todo := range m.todos {
+		if todo.DeletedAt != nil || !matchesFilter(todo, query.Filter) {
+			continue
+		}
+
+		if query.Cursor != nil && compareTodos(sort, todo, query.Cursor.Todo) <= 0 {
+			continue
+		}
+
+		t = append(t, m.todos.clone(todo))
+	}
+
+	slices.SortFunc(t, func(a, b todos.Todo) int {
+		return compareTodos(sort, a, b)
+	})
+
+	if query.Limit > 0 && len(t) > query.Limit {
+		t = t[:query.Limit]
+		next = NewCursor(sort, t[len(t)-1])
+	}
+
+	return t, next, nil
+}
+
+func (m *MemoryRepository) SearchTodos(
+	_ context.Context,
+	query SearchQuery,
+) (results []todos.SearchResult, next *SearchCursor, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	terms := strings.Fields(strings.ToLower(query.Text))
+	results = make([]todos.SearchResult, 0)
+
+	for _, todo := range m.todos {
+		if todo.DeletedAt != nil {
+			continue
+		}
+
+		result, ok := searchTodo(m.todos.clone(todo), terms)
+		if ok {
+			results = append(results, result)
+		}
+	}
+
+	results, next = pageSearchResults(results, query)
+
+	return results, next, nil
+}
+
+func (m *MemoryRepository) GetTodosLastModified(_ context.Context) (lastModified *time.Time, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, todo := range m.todos {
+		for _, t := range []*time.Time{&todo.CreatedAt, todo.UpdatedAt, todo.DeletedAt} {
+			if t != nil && (lastModified == nil || t.After(*lastModified)) {
+				lastModified = t
+			}
+		}
+	}
+
+	return lastModified, nil
+}
+
+func (m *MemoryRepository) GetDeletedTodos(_ context.Context) (t []todos.Todo, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	t = make([]todos.Todo, 0)
+
+	for _, todo := range m.todos {
+		if todo.DeletedAt != nil {
+			t = append(t, m.todos.clone(todo))
+		}
+	}
+
+	slices.SortFunc(t, func(a, b todos.Todo) int {
+		c := b.DeletedAt.Compare(*a.DeletedAt)
+		if c != 0 {
+			return c
+		}
+
+		return compareTodos([]Sort{{Field: SortByID}}, a, b)
+	})
+
+	return t, nil
+}
+
+func (m *MemoryRepository) GetTodo(_ context.Context, id uuid.UUID) (t todos.Todo, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	todo, ok := m.todos.get(id)
+	if !ok {
+		return todos.Todo{}, ErrTodoNotFound
+	}
+
+	return m.todos.clone(todo), nil
+}
+
+func (m *MemoryRepository) CreateTodo(ctx context.Context, todo todos.Todo) (createdTodo todos.Todo, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	createdTodo, err = m.todos.create(todo, m.lists)
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	m.tags.ensure(todo.Tags)
+	m.events.record(newEvent(ctx, todos.EventCreated, nil, &createdTodo, todo.CreatedAt))
+	m.versions.record(createdTodo)
+
+	return createdTodo, nil
+}
+
+func (m *MemoryRepository) SaveTodo(ctx context.Context, todo todos.Todo) (savedTodo todos.Todo, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	previousTodo, ok := m.todos.get(todo.ID)
+	if !ok {
+		return todos.Todo{}, ErrTodoNotFound
+	}
+
+	previousTodo = m.todos.clone(previousTodo)
+
+	savedTodo, err = m.todos.save(todo, m.lists)
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	m.tags.ensure(todo.Tags)
+	m.events.record(newEvent(ctx, todos.EventUpdated, &previousTodo, &savedTodo, changedAt(todo.UpdatedAt)))
+	m.versions.record(previousTodo, savedTodo)
+
+	next, ok := m.todos.createNextOccurrence(previousTodo, savedTodo, m.lists)
+	if ok {
+		m.events.record(newEvent(ctx, todos.EventCreated, nil, &next, *savedTodo.CompletedAt))
+		m.versions.record(next)
+	}
+
+	return savedTodo, nil
+}
+
+func (m *MemoryRepository) CompleteTodo(
+	ctx context.Context,
+	id uuid.UUID,
+	completedAt time.Time,
+) (completedTodo todos.Todo, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	todo, ok := m.todos.get(id)
+	if !ok {
+		return todos.Todo{}, ErrTodoNotFound
+	}
+
+	if todo.CompletedAt != nil {
+		return m.todos.clone(todo), nil
+	}
+
+	previousTodo := m.todos.clone(todo)
+
+	todo.CompletedAt = &completedAt
+	todo.UpdatedAt = &completedAt
+	todo.Version++
+	todo = normalizeTodo(todo)
+	m.todos[id] = todo
+
+	completedTodo = m.todos.clone(todo)
+	m.events.record(newEvent(ctx, todos.EventCompleted, &previousTodo, &completedTodo, completedAt))
+	m.versions.record(previousTodo, completedTodo)
+
+	next, ok := m.todos.createNextOccurrence(previousTodo, completedTodo, m.lists)
+	if ok {
+		m.events.record(newEvent(ctx, todos.EventCreated, nil, &next, completedAt))
+		m.versions.record(next)
+	}
+
+	return completedTodo, nil
+}
+
+// MoveTodo places the todo between its neighbors. If the new position of the todo
+// would be too long, positions of all todos which are not deleted are rebalanced first.
+func (m *MemoryRepository) MoveTodo(
+	ctx context.Context,
+	id uuid.UUID,
+	move TodoMove,
+	movedAt time.Time,
+) (movedTodo todos.Todo, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	previousTodo, ok := m.todos.get(id)
+	if !ok {
+		return todos.Todo{}, ErrTodoNotFound
+	}
+
+	previousTodo = m.todos.clone(previousTodo)
+
+	position, err := m.todos.movePosition(id, move)
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	if len(position) > maxPositionLength {
+		m.todos.rebalance(movedAt)
+
+		position, err = m.todos.movePosition(id, move)
+		if err != nil {
+			return todos.Todo{}, err
+		}
+	}
+
+	todo := m.todos[id]
+	todo.Position = position
+	todo.UpdatedAt = &movedAt
+	todo.Version++
+	todo = normalizeTodo(todo)
+	m.todos[id] = todo
+
+	movedTodo = m.todos.clone(todo)
+	m.events.record(newEvent(ctx, todos.EventMoved, &previousTodo, &movedTodo, movedAt))
+	m.versions.record(previousTodo, movedTodo)
+
+	return movedTodo, nil
+}
+
+func (m *MemoryRepository) ReopenTodo(
+	ctx context.Context,
+	id uuid.UUID,
+	reopenedAt time.Time,
+) (reopenedTodo todos.Todo, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	todo, ok := m.todos.get(id)
+	if !ok {
+		return todos.Todo{}, ErrTodoNotFound
+	}
+
+	if todo.CompletedAt == nil {
+		return m.todos.clone(todo), nil
+	}
+
+	previousTodo := m.todos.clone(todo)
+
+	todo.CompletedAt = nil
+	todo.UpdatedAt = &reopenedAt
+	todo.Version++
+	todo = normalizeTodo(todo)
+	m.todos[id] = todo
+
+	reopenedTodo = m.todos.clone(todo)
+	m.events.record(newEvent(ctx, todos.EventReopened, &previousTodo, &reopenedTodo, reopenedAt))
+	m.versions.record(previousTodo, reopenedTodo)
+
+	return reopenedTodo, nil
+}
+
+func (m *MemoryRepository) RestoreTodo(
+	ctx context.Context,
+	id uuid.UUID,
+	restoredAt time.Time,
+) (restoredTodo todos.Todo, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	todo, ok := m.todos[id]
+	if !ok || todo.DeletedAt == nil {
+		return todos.Todo{}, ErrTodoNotFound
+	}
+
+	previousTodo := m.todos.clone(todo)
+
+	todo.Position = m.todos.restoredPosition(todo, restoredAt)
+	todo.DeletedAt = nil
+	todo.UpdatedAt = &restoredAt
+	todo.Version++
+
+	if !m.lists.exists(todo.ListID) {
+		todo.ListID = nil
+	}
+
+	if todo.ParentID != nil {
+		if _, ok := m.todos.get(*todo.ParentID); !ok {
+			todo.ParentID = nil
+		}
+	}
+
+	todo = normalizeTodo(todo)
+	m.todos[id] = todo
+
+	restoredTodo = m.todos.clone(todo)
+	m.events.record(newEvent(ctx, todos.EventRestored, &previousTodo, &restoredTodo, restoredAt))
+	m.versions.record(previousTodo, restoredTodo)
+
+	return restoredTodo, nil
+}
+
+func (m *MemoryRepository) DeleteTodo(ctx context.Context, id uuid.UUID, version int, deletedAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	deletedTodos, err := m.todos.delete(id, version, deletedAt)
+	if err != nil {
+		return err
+	}
+
+	events, versions := m.todos.deletionChanges(ctx, deletedTodos, deletedAt)
+	m.events.record(events...)
+	m.versions.record(versions...)
+
+	return nil
+}
+
+func (m *MemoryRepository) PurgeTodos(
+	_ context.Context,
+	deletedBefore time.Time,
+	limit int,
+) (purged int64, attachments []uuid.UUID, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	deleted := make([]todos.Todo, 0)
+
+	for _, todo := range m.todos {
+		if todo.DeletedAt != nil && todo.DeletedAt.Before(deletedBefore) {
+			deleted = append(deleted, todo)
+		}
+	}
+
+	slices.SortFunc(deleted, func(a, b todos.Todo) int {
+		return a.DeletedAt.Compare(*b.DeletedAt)
+	})
+
+	for _, todo := range deleted[:max(0, min(limit, len(deleted)))] {
+		delete(m.todos, todo.ID)
+		m.comments.deleteTodo(todo.ID)
+		attachments = append(attachments, m.attachments.deleteTodo(todo.ID)...)
+		delete(m.events, todo.ID)
+		delete(m.versions, todo.ID)
+
+		purged++
+	}
+
+	return purged, attachments, nil
+}
+
+// ExecuteBatch works like [Repository.ExecuteBatch].
+// Atomic batches are executed on a copy of the todos and tags which replaces them only if all operations succeed.
+func (m *MemoryRepository) ExecuteBatch(
+	ctx context.Context,
+	operations []BatchOperation,
+	atomic bool,
+	now time.Time,
+) (results []BatchResult, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, tags := m.todos, m.tags
+	if atomic {
+		state, tags = maps.Clone(m.todos), maps.Clone(m.tags)
+	}
+
+	events := make([]todos.Event, 0, len(operations))
+	versions := make([]todos.Todo, 0, len(operations))
+
+	results = make([]BatchResult, len(operations))
+	for i, operation := range operations {
+		previousTodo, _ := state.get(operation.Todo.ID)
+		previousTodo = state.clone(previousTodo)
+
+		// Operations never change the todos when they fail so there is nothing to roll back.
+		var deletedTodos []todos.Todo
+
+		results[i], deletedTodos = state.execute(operation, m.lists, now)
+		if results[i].Err != nil {
+			if atomic {
+				return abortBatch(results, i), nil
+			}
+
+			continue
+		}
+
+		tags.ensure(operation.Todo.Tags)
+
+		operationEvents, operationVersions := state.batchChanges(
+			ctx,
+			operation.Kind,
+			previousTodo,
+			results[i].Todo,
+			deletedTodos,
+			now,
+		)
+		events = append(events, operationEvents...)
+		versions = append(versions, operationVersions...)
+
+		if operation.Kind != BatchUpdate {
+			continue
+		}
+
+		next, ok := state.createNextOccurrence(previousTodo, results[i].Todo, m.lists)
+		if ok {
+			events = append(events, newEvent(ctx, todos.EventCreated, nil, &next, now))
+			versions = append(versions, next)
+		}
+	}
+
+	m.todos, m.tags = state, tags
+	m.events.record(events...)
+	m.versions.record(versions...)
+
+	return results, nil
+}
+
+// GetTodoEvents returns history of the todo, the oldest event first.
+// History of deleted todos is kept until they are purged.
+func (m *MemoryRepository) GetTodoEvents(_ context.Context, todoID uuid.UUID) (e []todos.Event, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	_, ok := m.todos[todoID]
+	if !ok {
+		return nil, ErrTodoNotFound
+	}
+
+	// Use append to avoid returning nil slice
+	e = make([]todos.Event, 0, len(m.events[todoID]))
+
+	for _, event := range m.events[todoID] {
+		e = append(e, cloneEvent(event))
+	}
+
+	return e, nil
+}
+
+// GetTodoVersions returns versions of the todo, the oldest one first and the current one last.
+// Versions of deleted todos are kept until they are purged.
+func (m *MemoryRepository) GetTodoVersions(_ context.Context, todoID uuid.UUID) (v []todos.Version, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	todo, ok := m.todos[todoID]
+	if !ok {
+		return nil, ErrTodoNotFound
+	}
+
+	// Use append to avoid returning nil slice
+	v = make([]todos.Version, 0, len(m.versions[todoID])+1)
+
+	for _, version := range m.versions[todoID] {
+		version.Todo = cloneTodo(version.Todo)
+		v = append(v, version)
+	}
+
+	return completeVersions(v, m.todos.clone(todo)), nil
+}
+
+// GetTags returns all tags sorted by their names.
+func (m *MemoryRepository) GetTags(_ context.Context) (t []todos.Tag, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	// Use append to avoid returning nil slice
+	t = make([]todos.Tag, 0, len(m.tags))
+	t = slices.AppendSeq(t, maps.Values(m.tags))
+	slices.SortFunc(t, func(a, b todos.Tag) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return t, nil
+}
+
+func (m *MemoryRepository) GetTag(_ context.Context, id uuid.UUID) (t todos.Tag, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	tag, ok := m.tags[id]
+	if !ok {
+		return todos.Tag{}, ErrTagNotFound
+	}
+
+	return tag, nil
+}
+
+func (m *MemoryRepository) CreateTag(_ context.Context, tag todos.Tag) (createdTag todos.Tag, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.tags.find(tag.Name); ok {
+		return todos.Tag{}, ErrTagExists
+	}
+
+	createdTag = todos.Tag{
+		ID:   uuid.New(),
+		Name: tag.Name,
+	}
+	m.tags[createdTag.ID] = createdTag
+
+	return createdTag, nil
+}
+
+// SaveTag renames the tag and all its uses by the todos.
+func (m *MemoryRepository) SaveTag(
+	ctx context.Context,
+	tag todos.Tag,
+	savedAt time.Time,
+) (savedTag todos.Tag, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.tags[tag.ID]
+	if !ok {
+		return todos.Tag{}, ErrTagNotFound
+	}
+
+	if existing, ok := m.tags.find(tag.Name); ok && existing.ID != tag.ID {
+		return todos.Tag{}, ErrTagExists
+	}
+
+	events, versions := m.todos.replaceTag(ctx, stored.Name, tag.Name, savedAt)
+	m.events.record(events...)
+	m.versions.record(versions...)
+
+	stored.Name = tag.Name
+	m.tags[stored.ID] = stored
+
+	return stored, nil
+}
+
+// DeleteTag deletes the tag and removes it from all todos.
+func (m *MemoryRepository) DeleteTag(ctx context.Context, id uuid.UUID, deletedAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.tags[id]
+	if !ok {
+		return ErrTagNotFound
+	}
+
+	events, versions := m.todos.replaceTag(ctx, stored.Name, "", deletedAt)
+	m.events.record(events...)
+	m.versions.record(versions...)
+
+	delete(m.tags, id)
+
+	return nil
+}
+
+// GetLists returns all lists in the order they were created.
+func (m *MemoryRepository) GetLists(_ context.Context) (l []todos.List, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	// Use append to avoid returning nil slice
+	l = make([]todos.List, 0, len(m.lists))
+	for _, list := range m.lists {
+		l = append(l, cloneList(list))
+	}
+
+	slices.SortFunc(l, func(a, b todos.List) int {
+		c := a.CreatedAt.Compare(b.CreatedAt)
+		if c != 0 {
+			return c
+		}
+
+		return strings.Compare(a.ID.String(), b.ID.String())
+	})
+
+	return l, nil
+}
+
+func (m *MemoryRepository) GetList(_ context.Context, id uuid.UUID) (l todos.List, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	list, ok := m.lists[id]
+	if !ok {
+		return todos.List{}, ErrListNotFound
+	}
+
+	return cloneList(list), nil
+}
+
+func (m *MemoryRepository) CreateList(_ context.Context, list todos.List) (createdList todos.List, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	createdList = todos.List{
+		ID:        uuid.New(),
+		Name:      list.Name,
+		Color:     list.Color,
+		Archived:  list.Archived,
+		CreatedAt: normalizeTime(list.CreatedAt),
+	}
+	m.lists[createdList.ID] = createdList
+
+	return cloneList(createdList), nil
+}
+
+func (m *MemoryRepository) SaveList(_ context.Context, list todos.List) (savedList todos.List, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.lists[list.ID]
+	if !ok {
+		return todos.List{}, ErrListNotFound
+	}
+
+	stored.Name = list.Name
+	stored.Color = list.Color
+	stored.Archived = list.Archived
+	stored.UpdatedAt = normalizeTimePointer(list.UpdatedAt)
+	m.lists[stored.ID] = stored
+
+	return cloneList(stored), nil
+}
+
+// DeleteList deletes the list and marks all of its todos as deleted.
+// Unlike todos, deleted lists are not kept as they can never be retrieved again.
+func (m *MemoryRepository) DeleteList(ctx context.Context, id uuid.UUID, deletedAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, ok := m.lists[id]
+	if !ok {
+		return ErrListNotFound
+	}
+
+	delete(m.lists, id)
+
+	for _, todo := range m.todos {
+		if todo.DeletedAt == nil && todo.ListID != nil && *todo.ListID == id {
+			deletedTodos, _ := m.todos.delete(todo.ID, 0, deletedAt)
+			events, versions := m.todos.deletionChanges(ctx, deletedTodos, deletedAt)
+			m.events.record(events...)
+			m.versions.record(versions...)
+		}
+	}
+
+	return nil
+}
+
+// GetSubtasks returns subtasks of the todo in their order.
+func (m *MemoryRepository) GetSubtasks(_ context.Context, id uuid.UUID) (t []todos.Todo, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	_, ok := m.todos.get(id)
+	if !ok {
+		return nil, ErrTodoNotFound
+	}
+
+	t = m.todos.subtasks(id)
+	for i, subtask := range t {
+		t[i] = m.todos.clone(subtask)
+	}
+
+	return t, nil
+}
+
+// ReorderSubtasks works like [Repository.ReorderSubtasks].
+func (m *MemoryRepository) ReorderSubtasks(
+	_ context.Context,
+	id uuid.UUID,
+	order []uuid.UUID,
+	reorderedAt time.Time,
+) (subtasks []todos.Todo, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	todo, ok := m.todos.get(id)
+	if !ok {
+		return nil, ErrTodoNotFound
+	}
+
+	if !matchesSubtasks(m.todos.subtasks(id), order) {
+		return nil, ErrSubtasksMismatch
+	}
+
+	todo.UpdatedAt = &reorderedAt
+	todo.Version++
+	m.todos[id] = todo
+
+	subtasks = make([]todos.Todo, len(order))
+	for i, subtaskID := range order {
+		subtask := m.todos[subtaskID]
+		subtask.SubtaskOrder = i
+		subtask.UpdatedAt = &reorderedAt
+		subtask.Version++
+		m.todos[subtaskID] = subtask
+		subtasks[i] = m.todos.clone(subtask)
+	}
+
+	return subtasks, nil
+}
+
+// GetComments returns comments of the todo in the order they were created.
+func (m *MemoryRepository) GetComments(_ context.Context, todoID uuid.UUID) (c []todos.Comment, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	_, ok := m.todos.get(todoID)
+	if !ok {
+		return nil, ErrTodoNotFound
+	}
+
+	// Use append to avoid returning nil slice
+	c = make([]todos.Comment, 0)
+
+	for _, comment := range m.comments {
+		if comment.TodoID == todoID {
+			c = append(c, cloneComment(comment))
+		}
+	}
+
+	slices.SortFunc(c, func(a, b todos.Comment) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), strings.Compare(a.ID.String(), b.ID.String()))
+	})
+
+	return c, nil
+}
+
+func (m *MemoryRepository) CreateComment(
+	_ context.Context,
+	comment todos.Comment,
+) (createdComment todos.Comment, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, ok := m.todos.get(comment.TodoID)
+	if !ok {
+		return todos.Comment{}, ErrTodoNotFound
+	}
+
+	createdComment = todos.Comment{
+		ID:        uuid.New(),
+		TodoID:    comment.TodoID,
+		Author:    comment.Author,
+		Body:      comment.Body,
+		CreatedAt: normalizeTime(comment.CreatedAt),
+	}
+	m.comments[createdComment.ID] = createdComment
+
+	return cloneComment(createdComment), nil
+}
+
+// SaveComment changes body of the comment. The author of the comment cannot be changed.
+func (m *MemoryRepository) SaveComment(
+	_ context.Context,
+	comment todos.Comment,
+) (savedComment todos.Comment, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, err := m.comment(comment.TodoID, comment.ID)
+	if err != nil {
+		return todos.Comment{}, err
+	}
+
+	stored.Body = comment.Body
+	stored.UpdatedAt = normalizeTimePointer(comment.UpdatedAt)
+	m.comments[stored.ID] = stored
+
+	return cloneComment(stored), nil
+}
+
+// DeleteComment deletes the comment. Unlike todos, deleted comments
+// are not kept as they can never be retrieved again.
+func (m *MemoryRepository) DeleteComment(_ context.Context, todoID uuid.UUID, id uuid.UUID, _ time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, err := m.comment(todoID, id)
+	if err != nil {
+		return err
+	}
+
+	delete(m.comments, id)
+
+	return nil
+}
+
+// GetAttachments returns attachments of the todo in the order they were created.
+func (m *MemoryRepository) GetAttachments(
+	_ context.Context,
+	todoID uuid.UUID,
+) (attachments []todos.Attachment, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	_, ok := m.todos.get(todoID)
+	if !ok {
+		return nil, ErrTodoNotFound
+	}
+
+	// Use append to avoid returning nil slice
+	attachments = make([]todos.Attachment, 0)
+
+	for _, attachment := range m.attachments {
+		if attachment.TodoID == todoID {
+			attachments = append(attachments, attachment)
+		}
+	}
+
+	slices.SortFunc(attachments, func(a, b todos.Attachment) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), strings.Compare(a.ID.String(), b.ID.String()))
+	})
+
+	return attachments, nil
+}
+
+func (m *MemoryRepository) GetAttachment(
+	_ context.Context,
+	todoID uuid.UUID,
+	id uuid.UUID,
+) (attachment todos.Attachment, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.attachment(todoID, id)
+}
+
+func (m *MemoryRepository) CreateAttachment(
+	_ context.Context,
+	attachment todos.Attachment,
+) (createdAttachment todos.Attachment, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, ok := m.todos.get(attachment.TodoID)
+	if !ok {
+		return todos.Attachment{}, ErrTodoNotFound
+	}
+
+	createdAttachment = todos.Attachment{
+		ID:          uuid.New(),
+		TodoID:      attachment.TodoID,
+		Name:        attachment.Name,
+		ContentType: attachment.ContentType,
+		Size:        attachment.Size,
+		CreatedAt:   normalizeTime(attachment.CreatedAt),
+	}
+	m.attachments[createdAttachment.ID] = createdAttachment
+
+	return createdAttachment, nil
+}
+
+// DeleteAttachment removes the attachment.
+func (m *MemoryRepository) DeleteAttachment(_ context.Context, todoID uuid.UUID, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, err := m.attachment(todoID, id)
+	if err != nil {
+		return err
+	}
+
+	delete(m.attachments, id)
+
+	return nil
+}
+
+// GetAPIKeys returns all keys including the revoked ones in the order they were created.
+func (m *MemoryRepository) GetAPIKeys(_ context.Context) (k []auth.Key, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	// Use append to avoid returning nil slice
+	k = make([]auth.Key, 0, len(m.apiKeys))
+
+	for _, key := range m.apiKeys {
+		k = append(k, cloneAPIKey(key))
+	}
+
+	slices.SortFunc(k, func(a, b auth.Key) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), strings.Compare(a.ID.String(), b.ID.String()))
+	})
+
+	return k, nil
+}
+
+func (m *MemoryRepository) CreateAPIKey(_ context.Context, key auth.Key) (createdKey auth.Key, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	createdKey = auth.Key{
+		ID:        uuid.New(),
+		Name:      key.Name,
+		Hash:      key.Hash,
+		Scopes:    slices.Clone(key.Scopes),
+		ExpiresAt: normalizeTimePointer(key.ExpiresAt),
+		CreatedAt: normalizeTime(key.CreatedAt),
+	}
+	m.apiKeys[createdKey.ID] = createdKey
+
+	return cloneAPIKey(createdKey), nil
+}
+
+// RevokeAPIKey marks the key as revoked so that it can no longer be used.
+func (m *MemoryRepository) RevokeAPIKey(_ context.Context, id uuid.UUID, revokedAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key, ok := m.apiKeys[id]
+	if !ok || key.RevokedAt != nil {
+		return ErrAPIKeyNotFound
+	}
+
+	key.RevokedAt = normalizeTimePointer(&revokedAt)
+	m.apiKeys[id] = key
+
+	return nil
+}
+
+// UseAPIKey returns the key with the given hash and records it was used.
+// Keys which have expired or were revoked are treated as not existing.
+func (m *MemoryRepository) UseAPIKey(_ context.Context, hash string, usedAt time.Time) (auth.Key, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id, key := range m.apiKeys {
+		if key.Hash != hash {
+			continue
+		}
+
+		if key.RevokedAt != nil || (key.ExpiresAt != nil && !key.ExpiresAt.After(usedAt)) {
+			return auth.Key{}, ErrInvalidAPIKey
+		}
+
+		key.LastUsedAt = normalizeTimePointer(&usedAt)
+		m.apiKeys[id] = key
+
+		return cloneAPIKey(key), nil
+	}
+
+	return auth.Key{}, ErrInvalidAPIKey
+}
+
+// attachment returns the attachment of the todo unless the todo is deleted.
+func (m *MemoryRepository) attachment(todoID uuid.UUID, id uuid.UUID) (todos.Attachment, error) {
+	_, ok := m.todos.get(todoID)
+	if !ok {
+		return todos.Attachment{}, ErrTodoNotFound
+	}
+
+	attachment, ok := m.attachments[id]
+	if !ok || attachment.TodoID != todoID {
+		return todos.Attachment{}, ErrAttachmentNotFound
+	}
+
+	return attachment, nil
+}
+
+// comment returns the comment of the todo which is not deleted.
+func (m *MemoryRepository) comment(todoID uuid.UUID, id uuid.UUID) (todos.Comment, error) {
+	_, ok := m.todos.get(todoID)
+	if !ok {
+		return todos.Comment{}, ErrTodoNotFound
+	}
+
+	comment, ok := m.comments[id]
+	if !ok || comment.TodoID != todoID {
+		return todos.Comment{}, ErrCommentNotFound
+	}
+
+	return comment, nil
+}
+
+// memoryTodos holds all todos including the deleted ones by their ID.
+type memoryTodos map[uuid.UUID]todos.Todo
+
+// get returns todo which is not deleted.
+func (mt memoryTodos) get(id uuid.UUID) (todos.Todo, bool) {
+	todo, ok := mt[id]
+	if !ok || todo.DeletedAt != nil {
+		return todos.Todo{}, false
+	}
+
+	return todo, true
+}
+
+func (mt memoryTodos) create(todo todos.Todo, lists memoryLists) (todos.Todo, error) {
+	if !lists.exists(todo.ListID) {
+		return todos.Todo{}, ErrListNotFound
+	}
+
+	err := mt.checkParent(uuid.Nil, todo.ParentID)
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	created := normalizeTodo(todos.Todo{
+		ID:           uuid.New(),
+		Description:  todo.Description,
+		ListID:       todo.ListID,
+		ParentID:     todo.ParentID,
+		SubtaskOrder: mt.nextSubtaskOrder(todo.ParentID),
+		Priority:     todo.Priority,
+		DueAt:        todo.DueAt,
+		Recurrence:   todo.Recurrence,
+		Position:     mt.appendPosition(todo.CreatedAt),
+		Tags:         todo.Tags,
+		CreatedAt:    todo.CreatedAt,
+		Version:      1,
+	})
+	mt[created.ID] = created
+
+	return mt.clone(created), nil
+}
+
+func (mt memoryTodos) save(todo todos.Todo, lists memoryLists) (todos.Todo, error) {
+	stored, ok := mt.get(todo.ID)
+	if !ok {
+		return todos.Todo{}, ErrTodoNotFound
+	}
+
+	if todo.Version != 0 && todo.Version != stored.Version {
+		return todos.Todo{}, ErrVersionConflict
+	}
+
+	if !lists.exists(todo.ListID) {
+		return todos.Todo{}, ErrListNotFound
+	}
+
+	err := mt.checkParent(todo.ID, todo.ParentID)
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	if !sameParent(stored.ParentID, todo.ParentID) {
+		stored.SubtaskOrder = mt.nextSubtaskOrder(todo.ParentID)
+	}
+
+	stored.Description = todo.Description
+	stored.ListID = todo.ListID
+	stored.ParentID = todo.ParentID
+	stored.Priority = todo.Priority
+	stored.DueAt = todo.DueAt
+	stored.Recurrence = todo.Recurrence
+	stored.Tags = todo.Tags
+	stored.CompletedAt = todo.CompletedAt
+	stored.UpdatedAt = todo.UpdatedAt
+	stored.Version++
+	stored = normalizeTodo(stored)
+	mt[stored.ID] = stored
+
+	return mt.clone(stored), nil
+}
+
+// delete marks the todo and its subtasks as deleted.
+// It returns the deleted todos in the state before the deletion.
+func (mt memoryTodos) delete(id uuid.UUID, version int, deletedAt time.Time) (deletedTodos []todos.Todo, err error) {
+	stored, ok := mt.get(id)
+	if !ok {
+		return nil, ErrTodoNotFound
+	}
+
+	if version != 0 && version != stored.Version {
+		return nil, ErrVersionConflict
+	}
+
+	subtasks := mt.subtasks(id)
+	deletedTodos = append(deletedTodos, mt.clone(stored))
+
+	stored.DeletedAt = &deletedAt
+	stored.Version++
+	mt[id] = normalizeTodo(stored)
+
+	for _, subtask := range subtasks {
+		deletedSubtasks, _ := mt.delete(subtask.ID, 0, deletedAt)
+		deletedTodos = append(deletedTodos, deletedSubtasks...)
+	}
+
+	return deletedTodos, nil
+}
+
+// deletionChanges describes the deletion of the todos, which are in the state before it,
+// by their events and versions.
+func (mt memoryTodos) deletionChanges(
+	ctx context.Context,
+	previousTodos []todos.Todo,
+	deletedAt time.Time,
+) (events []todos.Event, versions []todos.Todo) {
+	for _, previousTodo := range previousTodos {
+		events = append(events, newEvent(ctx, todos.EventDeleted, &previousTodo, nil, deletedAt))
+		versions = append(versions, previousTodo, mt.clone(mt[previousTodo.ID]))
+	}
+
+	return events, versions
+}
+
+// clone copies the todo like [cloneTodo] does and summarizes its subtasks.
+func (mt memoryTodos) clone(todo todos.Todo) todos.Todo {
+	todo = cloneTodo(todo)
+	todo.Progress = nil
+
+	for _, subtask := range mt {
+		if !isSubtask(subtask, todo.ID) {
+			continue
+		}
+
+		if todo.Progress == nil {
+			todo.Progress = &todos.Progress{}
+		}
+
+		todo.Progress.Total++
+		if subtask.CompletedAt != nil {
+			todo.Progress.Completed++
+		}
+	}
+
+	return todo
+}
+
+// subtasks returns subtasks of the todo in their order.
+func (mt memoryTodos) subtasks(id uuid.UUID) []todos.Todo {
+	subtasks := make([]todos.Todo, 0)
+
+	for _, todo := range mt {
+		if isSubtask(todo, id) {
+			subtasks = append(subtasks, todo)
+		}
+	}
+
+	slices.SortFunc(subtasks, func(a, b todos.Todo) int {
+		return cmp.Or(
+			cmp.Compare(a.SubtaskOrder, b.SubtaskOrder),
+			a.CreatedAt.Compare(b.CreatedAt),
+			compareTodos([]Sort{{Field: SortByID}}, a, b),
+		)
+	})
+
+	return subtasks
+}
+
+// movePosition finds position between the neighbors of the moved todo.
+func (mt memoryTodos) movePosition(id uuid.UUID, move TodoMove) (position string, err error) {
+	err = checkMove(id, move)
+	if err != nil {
+		return "", err
+	}
+
+	var lower, upper string
+
+	if move.After != nil {
+		lower, err = mt.neighborPosition(*move.After)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if move.Before != nil {
+		upper, err = mt.neighborPosition(*move.Before)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	switch {
+	case move.After == nil:
+		lower = mt.previousPosition(id, upper)
+	case move.Before == nil:
+		upper = mt.nextPosition(id, lower)
+	case lower >= upper:
+		return "", ErrInvalidMove
+	}
+
+	return positionBetween(lower, upper), nil
+}
+
+// previousPosition returns the position right before the given one ignoring the moved todo.
+func (mt memoryTodos) previousPosition(id uuid.UUID, position string) (previous string) {
+	for _, todo := range mt {
+		if todo.ID != id && todo.DeletedAt == nil && todo.Position < position && todo.Position > previous {
+			previous = todo.Position
+		}
+	}
+
+	return previous
+}
+
+// nextPosition returns the position right after the given one ignoring the moved todo.
+// Empty position is returned when there is none.
+func (mt memoryTodos) nextPosition(id uuid.UUID, position string) (next string) {
+	for _, todo := range mt {
+		if todo.ID != id && todo.DeletedAt == nil && todo.Position > position && (next == "" || todo.Position < next) {
+			next = todo.Position
+		}
+	}
+
+	return next
+}
+
+func (mt memoryTodos) neighborPosition(id uuid.UUID) (position string, err error) {
+	neighbor, ok := mt.get(id)
+	if !ok {
+		return "", ErrNeighborNotFound
+	}
+
+	return neighbor.Position, nil
+}
+
+// appendPosition returns position following all todos.
+func (mt memoryTodos) appendPosition(appendedAt time.Time) string {
+	var last string
+
+	for _, todo := range mt {
+		if todo.DeletedAt == nil {
+			last = max(last, todo.Position)
+		}
+	}
+
+	position := positionAfter(last)
+	if len(position) > maxPositionLength {
+		mt.rebalance(appendedAt)
+		return mt.appendPosition(appendedAt)
+	}
+
+	return position
+}
+
+// restoredPosition returns position of the restored todo like [restoredPosition].
+func (mt memoryTodos) restoredPosition(todo todos.Todo, restoredAt time.Time) string {
+	for _, other := range mt {
+		if other.ID != todo.ID && other.DeletedAt == nil && other.Position == todo.Position {
+			return mt.appendPosition(restoredAt)
+		}
+	}
+
+	return todo.Position
+}
+
+// rebalance spreads the positions of todos which are not deleted evenly keeping their order.
+// Todos whose position changes get a new version.
+func (mt memoryTodos) rebalance(balancedAt time.Time) {
+	var ordered []todos.Todo
+
+	for _, todo := range mt {
+		if todo.DeletedAt == nil {
+			ordered = append(ordered, todo)
+		}
+	}
+
+	slices.SortFunc(ordered, func(a, b todos.Todo) int {
+		return cmp.Or(strings.Compare(a.Position, b.Position), bytes.Compare(a.ID[:], b.ID[:]))
+	})
+
+	for i, position := range balancedPositions(len(ordered)) {
+		todo := ordered[i]
+		if todo.Position == position {
+			continue
+		}
+
+		todo.Position = position
+		todo.UpdatedAt = &balancedAt
+		todo.Version++
+		mt[todo.ID] = todo
+	}
+}
+
+// nextSubtaskOrder returns order of a new subtask of the optional parent
+// following all of its subtasks including the deleted ones.
+func (mt memoryTodos) nextSubtaskOrder(parentID *uuid.UUID) int {
+	order := 0
+
+	if parentID == nil {
+		return 0
+	}
+
+	for _, todo := range mt {
+		if sameParent(todo.ParentID, parentID) {
+			order = max(order, todo.SubtaskOrder+1)
+		}
+	}
+
+	return order
+}
+
+// checkParent verifies that the optional parent of a todo exists
+// and that the todo is not one of its ancestors.
+func (mt memoryTodos) checkParent(id uuid.UUID, parentID *uuid.UUID) error {
+	if parentID == nil {
+		return nil
+	}
+
+	ancestor, ok := mt.get(*parentID)
+	if !ok {
+		return ErrParentNotFound
+	}
+
+	for {
+		if ancestor.ID == id {
+			return ErrParentCycle
+		}
+
+		if ancestor.ParentID == nil {
+			return nil
+		}
+
+		ancestor, ok = mt[*ancestor.ParentID]
+		if !ok {
+			return nil
+		}
+	}
+}
+
+// replaceTag renames the tag of all todos. Empty new name removes the tag.
+// The tags are replaced rather than changed in place as they may be shared with copies of the todos.
+// The changes of the todos are described by their events and versions.
+func (mt memoryTodos) replaceTag(
+	ctx context.Context,
+	oldName, newName string,
+	changedAt time.Time,
+) (events []todos.Event, versions []todos.Todo) {
+	for id, todo := range mt {
+		i := slices.Index(todo.Tags, oldName)
+		if i < 0 {
+			continue
+		}
+
+		previousTodo := mt.clone(todo)
+
+		tags := slices.Delete(slices.Clone(todo.Tags), i, i+1)
+		if newName != "" {
+			tags = append(tags, newName)
+		}
+
+		todo.Tags = normalizeTags(tags)
+		todo.UpdatedAt = &changedAt
+		todo.Version++
+		mt[id] = normalizeTodo(todo)
+
+		touchedTodo := mt.clone(mt[id])
+		events = append(events, newEvent(ctx, todos.EventUpdated, &previousTodo, &touchedTodo, changedAt))
+		versions = append(versions, previousTodo, touchedTodo)
+	}
+
+	return events, versions
+}
+
+// createNextOccurrence creates the next occurrence of the recurring todo like [createNextOccurrence].
+// The todo has to be stored already, so its list and parent exist and creating the occurrence cannot fail.
+func (mt memoryTodos) createNextOccurrence(
+	previousTodo todos.Todo,
+	todo todos.Todo,
+	lists memoryLists,
+) (next todos.Todo, ok bool) {
+	if previousTodo.CompletedAt != nil || todo.CompletedAt == nil {
+		return todos.Todo{}, false
+	}
+
+	next, ok = todo.NextOccurrence(*todo.CompletedAt)
+	if !ok {
+		return todos.Todo{}, false
+	}
+
+	next, err := mt.create(next, lists)
+
+	return next, err == nil
+}
+
+// execute applies the batch operation. Deletions return the deleted todos like [memoryTodos.delete].
+func (mt memoryTodos) execute(
+	operation BatchOperation,
+	lists memoryLists,
+	now time.Time,
+) (result BatchResult, deletedTodos []todos.Todo) {
+	var (
+		todo todos.Todo
+		err  error
+	)
+
+	switch operation.Kind {
+	case BatchCreate:
+		operation.Todo.CreatedAt = now
+		todo, err = mt.create(operation.Todo, lists)
+	case BatchUpdate:
+		operation.Todo.UpdatedAt = &now
+		todo, err = mt.save(operation.Todo, lists)
+	case BatchDelete:
+		todo = todos.Todo{ID: operation.Todo.ID}
+		deletedTodos, err = mt.delete(operation.Todo.ID, operation.Todo.Version, now)
+	default:
+		err = fmt.Errorf("%w: %q", ErrUnknownBatchOperation, operation.Kind)
+	}
+
+	return BatchResult{
+		Todo: todo,
+		Err:  err,
+	}, deletedTodos
+}
+
+// batchChanges describes the change made by the successful batch operation by its events and versions.
+// Deleted todos are the ones returned by the deletion.
+func (mt memoryTodos) batchChanges(
+	ctx context.Context,
+	kind BatchOperationKind,
+	previousTodo todos.Todo,
+	todo todos.Todo,
+	deletedTodos []todos.Todo,
+	now time.Time,
+) (events []todos.Event, versions []todos.Todo) {
+	switch kind {
+	case BatchCreate:
+		event := newEvent(ctx, todos.EventCreated, nil, &todo, now)
+		return []todos.Event{event}, []todos.Todo{mt.clone(mt[todo.ID])}
+	case BatchUpdate:
+		event := newEvent(ctx, todos.EventUpdated, &previousTodo, &todo, now)
+		return []todos.Event{event}, []todos.Todo{previousTodo, mt.clone(mt[todo.ID])}
+	case BatchDelete:
+		return mt.deletionChanges(ctx, deletedTodos, now)
+	default:
+		return nil, nil
+	}
+}
+
+// isSubtask reports whether the todo is a subtask of the parent which is not deleted.
+func isSubtask(todo todos.Todo, parentID uuid.UUID) bool {
+	return todo.DeletedAt == nil && todo.ParentID != nil && *todo.ParentID == parentID
+}
+
+func sameParent(a, b *uuid.UUID) bool {
+	return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+}
+
+// memoryTags holds all tags by their ID.
+type memoryTags map[uuid.UUID]todos.Tag
+
+func (mt memoryTags) find(name string) (todos.Tag, bool) {
+	for _, tag := range mt {
+		if tag.Name == name {
+			return tag, true
+		}
+	}
+
+	return todos.Tag{}, false
+}
+
+// ensure creates the named tags which do not exist yet.
+func (mt memoryTags) ensure(names []string) {
+	for _, name := range names {
+		if _, ok := mt.find(name); !ok {
+			tag := todos.Tag{
+				ID:   uuid.New(),
+				Name: name,
+			}
+			mt[tag.ID] = tag
+		}
+	}
+}
+
+// memoryLists holds all lists which are not deleted by their ID.
+type memoryLists map[uuid.UUID]todos.List
+
+// exists reports whether the optional list of a todo exists.
+func (ml memoryLists) exists(id *uuid.UUID) bool {
+	if id == nil {
+		return true
+	}
+
+	_, ok := ml[*id]
+
+	return ok
+}
+
+// memoryComments holds all comments which are not deleted by their ID.
+type memoryComments map[uuid.UUID]todos.Comment
+
+// deleteTodo deletes all comments of the todo.
+func (mc memoryComments) deleteTodo(todoID uuid.UUID) {
+	maps.DeleteFunc(mc, func(_ uuid.UUID, comment todos.Comment) bool {
+		return comment.TodoID == todoID
+	})
+}
+
+// memoryAttachments holds attachments of all todos by their ID.
+type memoryAttachments map[uuid.UUID]todos.Attachment
+
+// deleteTodo deletes all attachments of the todo and returns their IDs.
+func (ma memoryAttachments) deleteTodo(todoID uuid.UUID) (deleted []uuid.UUID) {
+	for id, attachment := range ma {
+		if attachment.TodoID == todoID {
+			delete(ma, id)
+			deleted = append(deleted, id)
+		}
+	}
+
+	return deleted
+}
+
+// memoryEvents holds history of todos by their ID, the oldest event first.
+type memoryEvents map[uuid.UUID][]todos.Event
+
+func (me memoryEvents) record(events ...todos.Event) {
+	for _, event := range events {
+		me[event.TodoID] = append(me[event.TodoID], cloneEvent(event))
+	}
+}
+
+// memoryAPIKeys holds all keys including the revoked ones by their ID.
+type memoryAPIKeys map[uuid.UUID]auth.Key
+
+// memoryVersions holds versions of todos by their ID, the oldest version first.
+type memoryVersions map[uuid.UUID][]todos.Version
+
+// record stores the todos as versions like [recordVersions].
+func (mv memoryVersions) record(versions ...todos.Todo) {
+	for _, todo := range versions {
+		recorded := mv[todo.ID]
+		if len(recorded) > 0 && recorded[len(recorded)-1].Number >= todo.Version {
+			continue
+		}
+
+		mv[todo.ID] = append(recorded, todos.Version{
+			Number:    todo.Version,
+			Todo:      cloneTodo(todo),
+			ValidFrom: validFrom(todo),
+		})
+	}
+}
+
+func matchesFilter(todo todos.Todo, filter TodosFilter) bool {
+	if filter.Completed != nil && *filter.Completed != (todo.CompletedAt != nil) {
+		return false
+	}
+
+	if filter.Overdue != nil && *filter.Overdue != isOverdue(todo, filter.Now) {
+		return false
+	}
+
+	if !matchesGrouping(todo, filter) {
+		return false
+	}
+
+	if !inTimeRange(&todo.CreatedAt, filter.CreatedAfter, filter.CreatedBefore) ||
+		!inTimeRange(todo.CompletedAt, filter.CompletedAfter, nil) ||
+		!inTimeRange(todo.DueAt, filter.DueAfter, filter.DueBefore) {
+		return false
+	}
+
+	return strings.Contains(strings.ToLower(todo.Description), strings.ToLower(filter.Text))
+}
+
+// matchesGrouping reports whether the todo is in the filtered list
+// and has any of the filtered priorities and any or all of the filtered tags.
+func matchesGrouping(todo todos.Todo, filter TodosFilter) bool {
+	if filter.ListID != nil && (todo.ListID == nil || *todo.ListID != *filter.ListID) {
+		return false
+	}
+
+	if len(filter.Priorities) > 0 && !slices.Contains(filter.Priorities, todo.Priority) {
+		return false
+	}
+
+	if len(filter.Tags) == 0 {
+		return true
+	}
+
+	hasTag := func(tag string) bool { return slices.Contains(todo.Tags, tag) }
+	if filter.AllTags {
+		return !slices.ContainsFunc(filter.Tags, func(tag string) bool { return !hasTag(tag) })
+	}
+
+	return slices.ContainsFunc(filter.Tags, hasTag)
+}
+
+func isOverdue(todo todos.Todo, now time.Time) bool {
+	return todo.CompletedAt == nil && todo.DueAt != nil && todo.DueAt.Before(now)
+}
+
+// inTimeRange reports whether the time is within the optional inclusive lower and exclusive upper bound.
+// Missing time is never within any bound.
+func inTimeRange(t, after, before *time.Time) bool {
+	if after == nil && before == nil {
+		return true
+	}
+
+	if t == nil {
+		return false
+	}
+
+	return (after == nil || !t.Before(*after)) && (before == nil || t.Before(*before))
+}
+
+// searchTodo matches the todo if its description contains all the terms.
+// Words containing any of the terms are enclosed in <mark> tags in the snippet.
+// The todo should be a copy of the stored one as it is included in the result.
+func searchTodo(todo todos.Todo, terms []string) (result todos.SearchResult, ok bool) {
+	if len(terms) == 0 {
+		return todos.SearchResult{}, false
+	}
+
+	description := strings.ToLower(todo.Description)
+	for _, term := range terms {
+		if !strings.Contains(description, term) {
+			return todos.SearchResult{}, false
+		}
+	}
+
+	words := strings.Fields(todo.Description)
+
+	var matched int
+
+	for i, word := range words {
+		word = strings.ToLower(word)
+
+		if slices.ContainsFunc(terms, func(term string) bool { return strings.Contains(word, term) }) {
+			words[i] = snippetStartSel + words[i] + snippetStopSel
+			matched++
+		}
+	}
+
+	return todos.SearchResult{
+		Todo:    todo,
+		Rank:    float32(matched) / float32(len(words)),
+		Snippet: markSnippet(strings.Join(words, " ")),
+	}, true
+}
+
+// pageSearchResults sorts all results matching the query and returns the page following its cursor.
+func pageSearchResults(
+	results []todos.SearchResult,
+	query SearchQuery,
+) (page []todos.SearchResult, next *SearchCursor) {
+	if query.Cursor != nil {
+		results = slices.DeleteFunc(results, func(result todos.SearchResult) bool {
+			return compareSearchResults(result, *query.Cursor) <= 0
+		})
+	}
+
+	slices.SortFunc(results, func(a, b todos.SearchResult) int {
+		return compareSearchResults(a, SearchCursor{Rank: b.Rank, ID: b.ID})
+	})
+
+	if query.Limit > 0 && len(results) > query.Limit {
+		results = results[:query.Limit]
+		next = NewSearchCursor(query.Text, results[len(results)-1])
+	}
+
+	return results, next
+}
+
+// compareSearchResults orders results by their rank in descending order and then by their ID.
+func compareSearchResults(result todos.SearchResult, cursor SearchCursor) int {
+	switch {
+	case result.Rank > cursor.Rank:
+		return -1
+	case result.Rank < cursor.Rank:
+		return 1
+	}
+
+	return sortFields[SortByID].compare(result.Todo, todos.Todo{ID: cursor.ID})
+}
+
+// normalizeTodo stores times and tags the same way as the database does.
+func normalizeTodo(todo todos.Todo) todos.Todo {
+	todo.Tags = normalizeTags(todo.Tags)
+	todo.DueAt = normalizeTimePointer(todo.DueAt)
+	todo.CreatedAt = normalizeTime(todo.CreatedAt)
+	todo.UpdatedAt = normalizeTimePointer(todo.UpdatedAt)
+	todo.CompletedAt = normalizeTimePointer(todo.CompletedAt)
+	todo.DeletedAt = normalizeTimePointer(todo.DeletedAt)
+
+	return todo
+}
+
+func normalizeTime(t time.Time) time.Time {
+	return t.UTC().Round(time.Microsecond)
+}
+
+func normalizeTimePointer(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+
+	normalized := normalizeTime(*t)
+
+	return &normalized
+}
+
+// cloneTodo copies the todo so the stored todo cannot be changed through the returned one.
+func cloneTodo(todo todos.Todo) todos.Todo {
+	if todo.ListID != nil {
+		listID := *todo.ListID
+		todo.ListID = &listID
+	}
+
+	if todo.ParentID != nil {
+		parentID := *todo.ParentID
+		todo.ParentID = &parentID
+	}
+
+	if todo.Recurrence != nil {
+		recurrence := *todo.Recurrence
+		recurrence.ByDay = slices.Clone(recurrence.ByDay)
+		recurrence.Until = cloneTimePointer(recurrence.Until)
+		todo.Recurrence = &recurrence
+	}
+
+	todo.Tags = slices.Clone(todo.Tags)
+	todo.DueAt = cloneTimePointer(todo.DueAt)
+	todo.UpdatedAt = cloneTimePointer(todo.UpdatedAt)
+	todo.CompletedAt = cloneTimePointer(todo.CompletedAt)
+	todo.DeletedAt = cloneTimePointer(todo.DeletedAt)
+
+	return todo
+}
+
+// cloneList copies the list so the stored list cannot be changed through the returned one.
+func cloneList(list todos.List) todos.List {
+	list.UpdatedAt = cloneTimePointer(list.UpdatedAt)
+
+	return list
+}
+
+func cloneComment(comment todos.Comment) todos.Comment {
+	comment.UpdatedAt = cloneTimePointer(comment.UpdatedAt)
+
+	return comment
+}
+
+func cloneAPIKey(key auth.Key) auth.Key {
+	key.Scopes = slices.Clone(key.Scopes)
+	key.ExpiresAt = cloneTimePointer(key.ExpiresAt)
+	key.LastUsedAt = cloneTimePointer(key.LastUsedAt)
+	key.RevokedAt = cloneTimePointer(key.RevokedAt)
+
+	return key
+}
+
+func cloneEvent(event todos.Event) todos.Event {
+	if event.Before != nil {
+		before := cloneTodo(*event.Before)
+		event.Before = &before
+	}
+
+	if event.After != nil {
+		after := cloneTodo(*event.After)
+		event.After = &after
+	}
+
+	return event
+}
+
+func cloneTimePointer(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+
+	cloned := *t
+
+	return &cloned
+}
@@ -0,0 +1,560 @@
+package repository_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/course-go/todos/internal/repository"
+	"github.com/course-go/todos/internal/todos"
+	"github.com/course-go/todos/internal/utils/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+)
+
+func TestMemoryRepository(t *testing.T) { //nolint: gocognit, gocyclo, cyclop, maintidx
+	t.Parallel()
+
+	ctx := t.Context()
+
+	now, err := time.Parse(time.RFC3339Nano, "2024-08-18T14:14:45.847679Z")
+	if err != nil {
+		t.Fatalf("could not parse time: %v", err)
+	}
+
+	t.Run("Get todos page by page", func(t *testing.T) {
+		t.Parallel()
+
+		r := test.NewTestMemoryRepository(t)
+
+		_, err := r.CreateTodo(ctx, todos.Todo{Description: "Water the plants", CreatedAt: now})
+		if err != nil {
+			t.Fatalf("could not create todo: %v", err)
+		}
+
+		sort, err := repository.ParseSort("-completedAt,description")
+		if err != nil {
+			t.Fatalf("could not parse sort: %v", err)
+		}
+
+		query := repository.TodosQuery{
+			Sort:  sort,
+			Limit: 1,
+		}
+
+		var descriptions []string
+
+		for {
+			page, next, err := r.GetTodos(ctx, query)
+			if err != nil {
+				t.Fatalf("could not get todos: %v", err)
+			}
+
+			for _, todo := range page {
+				descriptions = append(descriptions, todo.Description)
+			}
+
+			if next == nil {
+				break
+			}
+
+			query.Cursor = next
+		}
+
+		expectedDescriptions := []string{"Vacuum", "Mop the floor", "Water the plants"}
+		if !cmp.Equal(expectedDescriptions, descriptions) {
+			t.Fatalf("todos do not match: %s", cmp.Diff(expectedDescriptions, descriptions))
+		}
+	})
+
+	t.Run("Search todos", func(t *testing.T) {
+		t.Parallel()
+
+		r := test.NewTestMemoryRepository(t)
+
+		results, _, err := r.SearchTodos(ctx, repository.SearchQuery{Text: "FLOOR mop"})
+		if err != nil {
+			t.Fatalf("could not search todos: %v", err)
+		}
+
+		if len(results) != 1 {
+			t.Fatalf("search results count does not match: expected: 1 != actual: %d", len(results))
+		}
+
+		expectedSnippet := "<mark>Mop</mark> the <mark>floor</mark>"
+		if results[0].Snippet != expectedSnippet {
+			t.Fatalf("snippets do not match: expected: %s != actual: %s", expectedSnippet, results[0].Snippet)
+		}
+	})
+
+	t.Run("Escape search snippets", func(t *testing.T) {
+		t.Parallel()
+
+		r := test.NewTestMemoryRepository(t)
+
+		_, err := r.CreateTodo(
+			ctx,
+			todos.Todo{Description: `Water <img src=x onerror="alert(1)"> plants`, CreatedAt: now},
+		)
+		if err != nil {
+			t.Fatalf("could not create todo: %v", err)
+		}
+
+		results, _, err := r.SearchTodos(ctx, repository.SearchQuery{Text: "plants"})
+		if err != nil {
+			t.Fatalf("could not search todos: %v", err)
+		}
+
+		if len(results) != 1 {
+			t.Fatalf("search results count does not match: expected: 1 != actual: %d", len(results))
+		}
+
+		expectedSnippet := "Water &lt;img src=x onerror=&#34;alert(1)&#34;&gt; <mark>plants</mark>"
+		if results[0].Snippet != expectedSnippet {
+			t.Fatalf("snippets do not match: expected: %s != actual: %s", expectedSnippet, results[0].Snippet)
+		}
+	})
+
+	t.Run("Reorder subtasks", func(t *testing.T) {
+		t.Parallel()
+
+		r := test.NewTestMemoryRepository(t)
+		parentID := uuid.MustParse("62446c85-3798-471f-abb8-75c1cdd7153b")
+
+		var order []uuid.UUID
+
+		for _, description := range []string{"Buy detergent", "Load the washer"} {
+			subtask, err := r.CreateTodo(ctx, todos.Todo{Description: description, ParentID: &parentID, CreatedAt: now})
+			if err != nil {
+				t.Fatalf("could not create subtask: %v", err)
+			}
+
+			order = append([]uuid.UUID{subtask.ID}, order...)
+		}
+
+		parent, err := r.GetTodo(ctx, parentID)
+		if err != nil {
+			t.Fatalf("could not get todo: %v", err)
+		}
+
+		reorderedAt := now.Add(time.Minute)
+
+		subtasks, err := r.ReorderSubtasks(ctx, parentID, order, reorderedAt)
+		if err != nil {
+			t.Fatalf("could not reorder subtasks: %v", err)
+		}
+
+		for i, subtask := range subtasks {
+			if subtask.ID != order[i] || subtask.Version != 2 || !subtask.UpdatedAt.Equal(reorderedAt) {
+				t.Fatalf("reordered subtask does not match: %+v", subtask)
+			}
+		}
+
+		reorderedParent, err := r.GetTodo(ctx, parentID)
+		if err != nil {
+			t.Fatalf("could not get todo: %v", err)
+		}
+
+		if reorderedParent.Version != parent.Version+1 || !reorderedParent.UpdatedAt.Equal(reorderedAt) {
+			t.Fatalf("todo with reordered subtasks should be changed: version %d, updated at %v",
+				reorderedParent.Version,
+				reorderedParent.UpdatedAt,
+			)
+		}
+	})
+
+	t.Run("Save todo with stale version", func(t *testing.T) {
+		t.Parallel()
+
+		r := test.NewTestMemoryRepository(t)
+
+		id, err := uuid.Parse("62446c85-3798-471f-abb8-75c1cdd7153b")
+		if err != nil {
+			t.Fatalf("could not parse uuid: %v", err)
+		}
+
+		todo, err := r.GetTodo(ctx, id)
+		if err != nil {
+			t.Fatalf("could not get todo: %v", err)
+		}
+
+		todo.UpdatedAt = &now
+
+		_, err = r.SaveTodo(ctx, todo)
+		if err != nil {
+			t.Fatalf("could not save todo: %v", err)
+		}
+
+		_, err = r.SaveTodo(ctx, todo)
+		if !errors.Is(err, repository.ErrVersionConflict) {
+			t.Fatalf("todo should conflict: expected: %v != actual: %v", repository.ErrVersionConflict, err)
+		}
+	})
+
+	t.Run("Execute atomic batch", func(t *testing.T) {
+		t.Parallel()
+
+		r := test.NewTestMemoryRepository(t)
+
+		operations := []repository.BatchOperation{
+			{Kind: repository.BatchCreate, Todo: todos.Todo{Description: "Water the plants"}},
+			{Kind: repository.BatchDelete, Todo: todos.Todo{ID: uuid.New()}},
+		}
+
+		results, err := r.ExecuteBatch(ctx, operations, true, now)
+		if err != nil {
+			t.Fatalf("could not execute batch: %v", err)
+		}
+
+		if !errors.Is(results[0].Err, repository.ErrBatchAborted) {
+			t.Fatalf("operation should be aborted: expected: %v != actual: %v",
+				repository.ErrBatchAborted,
+				results[0].Err,
+			)
+		}
+
+		retrievedTodos, _, err := r.GetTodos(ctx, repository.TodosQuery{})
+		if err != nil {
+			t.Fatalf("could not get todos: %v", err)
+		}
+
+		expectedLen := 2
+		if len(retrievedTodos) != expectedLen {
+			t.Fatalf("created todo should be rolled back: expected: %d != actual: %d", expectedLen, len(retrievedTodos))
+		}
+	})
+
+	t.Run("Record batch history", func(t *testing.T) {
+		t.Parallel()
+
+		r := test.NewTestMemoryRepository(t)
+
+		id := uuid.MustParse("62446c85-3798-471f-abb8-75c1cdd7153b")
+		operations := []repository.BatchOperation{
+			{Kind: repository.BatchUpdate, Todo: todos.Todo{ID: id, Description: "Mop the floor twice"}},
+			{Kind: repository.BatchDelete, Todo: todos.Todo{ID: uuid.New()}},
+		}
+
+		_, err := r.ExecuteBatch(ctx, operations, false, now)
+		if err != nil {
+			t.Fatalf("could not execute batch: %v", err)
+		}
+
+		events, err := r.GetTodoEvents(ctx, id)
+		if err != nil {
+			t.Fatalf("could not get todo events: %v", err)
+		}
+
+		if len(events) != 1 || events[0].Type != todos.EventUpdated ||
+			events[0].After.Description != "Mop the floor twice" {
+			t.Fatalf("only the update should be recorded: %+v", events)
+		}
+	})
+
+	t.Run("Save recurring todo as completed", func(t *testing.T) {
+		t.Parallel()
+
+		r := test.NewTestMemoryRepository(t)
+
+		recurrence, err := todos.ParseRecurrence("FREQ=DAILY;COUNT=3")
+		if err != nil {
+			t.Fatalf("could not parse recurrence: %v", err)
+		}
+
+		todo, err := r.CreateTodo(ctx, todos.Todo{Description: "Feed the cat", Recurrence: &recurrence, CreatedAt: now})
+		if err != nil {
+			t.Fatalf("could not create todo: %v", err)
+		}
+
+		todo.CompletedAt = &now
+
+		_, err = r.SaveTodo(ctx, todo)
+		if err != nil {
+			t.Fatalf("could not save todo: %v", err)
+		}
+
+		completed := false
+		query := repository.TodosQuery{Filter: repository.TodosFilter{Completed: &completed, Text: "cat"}}
+
+		retrievedTodos, _, err := r.GetTodos(ctx, query)
+		if err != nil {
+			t.Fatalf("could not get todos: %v", err)
+		}
+
+		if len(retrievedTodos) != 1 || retrievedTodos[0].ID == todo.ID {
+			t.Fatalf("next occurrence should be created: %v", retrievedTodos)
+		}
+
+		next := retrievedTodos[0]
+		next.CompletedAt = &now
+
+		_, err = r.ExecuteBatch(
+			ctx,
+			[]repository.BatchOperation{{Kind: repository.BatchUpdate, Todo: next}},
+			false,
+			now,
+		)
+		if err != nil {
+			t.Fatalf("could not execute batch: %v", err)
+		}
+
+		retrievedTodos, _, err = r.GetTodos(ctx, query)
+		if err != nil {
+			t.Fatalf("could not get todos: %v", err)
+		}
+
+		if len(retrievedTodos) != 1 || retrievedTodos[0].ID == next.ID {
+			t.Fatalf("next occurrence should be created by batch: %v", retrievedTodos)
+		}
+
+		events, err := r.GetTodoEvents(ctx, retrievedTodos[0].ID)
+		if err != nil || len(events) != 1 || events[0].Type != todos.EventCreated {
+			t.Fatalf("creation of next occurrence should be recorded: %v %v", events, err)
+		}
+	})
+
+	t.Run("Record reopen, move and restore history", func(t *testing.T) {
+		t.Parallel()
+
+		r := test.NewTestMemoryRepository(t)
+
+		mopID := uuid.MustParse("62446c85-3798-471f-abb8-75c1cdd7153b")
+		vacuumID := uuid.MustParse("f52bad23-c201-414e-9bdb-af4327c42aa7")
+		deletedID := uuid.MustParse("aeec043e-05ea-4271-9772-ddefe87628d6")
+
+		_, err := r.ReopenTodo(ctx, vacuumID, now)
+		if err != nil {
+			t.Fatalf("could not reopen todo: %v", err)
+		}
+
+		_, err = r.MoveTodo(ctx, mopID, repository.TodoMove{After: &vacuumID}, now)
+		if err != nil {
+			t.Fatalf("could not move todo: %v", err)
+		}
+
+		_, err = r.RestoreTodo(ctx, deletedID, now)
+		if err != nil {
+			t.Fatalf("could not restore todo: %v", err)
+		}
+
+		expectedTypes := map[uuid.UUID]todos.EventType{
+			vacuumID:  todos.EventReopened,
+			mopID:     todos.EventMoved,
+			deletedID: todos.EventRestored,
+		}
+		for id, expectedType := range expectedTypes {
+			events, err := r.GetTodoEvents(ctx, id)
+			if err != nil {
+				t.Fatalf("could not get todo events: %v", err)
+			}
+
+			if len(events) != 1 || events[0].Type != expectedType || events[0].Before == nil || events[0].After == nil {
+				t.Errorf("only the %s event should be recorded: %+v", expectedType, events)
+			}
+		}
+	})
+
+	t.Run("Record batch deletion of subtasks", func(t *testing.T) {
+		t.Parallel()
+
+		r := test.NewTestMemoryRepository(t)
+
+		id := uuid.MustParse("62446c85-3798-471f-abb8-75c1cdd7153b")
+
+		subtask, err := r.CreateTodo(ctx, todos.Todo{Description: "Buy detergent", ParentID: &id, CreatedAt: now})
+		if err != nil {
+			t.Fatalf("could not create subtask: %v", err)
+		}
+
+		operations := []repository.BatchOperation{
+			{Kind: repository.BatchDelete, Todo: todos.Todo{ID: id}},
+		}
+
+		_, err = r.ExecuteBatch(ctx, operations, true, now)
+		if err != nil {
+			t.Fatalf("could not execute batch: %v", err)
+		}
+
+		events, err := r.GetTodoEvents(ctx, subtask.ID)
+		if err != nil {
+			t.Fatalf("could not get subtask events: %v", err)
+		}
+
+		expectedLen := 2
+		if len(events) != expectedLen || events[1].Type != todos.EventDeleted || events[1].Before == nil {
+			t.Fatalf("deletion of subtask should be recorded: %+v", events)
+		}
+
+		versions, err := r.GetTodoVersions(ctx, subtask.ID)
+		if err != nil {
+			t.Fatalf("could not get subtask versions: %v", err)
+		}
+
+		if len(versions) != expectedLen || versions[1].Todo.DeletedAt == nil {
+			t.Fatalf("deleted subtask should be the last version: %+v", versions)
+		}
+	})
+
+	t.Run("Record renaming and deletion of tag", func(t *testing.T) {
+		t.Parallel()
+
+		r := test.NewTestMemoryRepository(t)
+
+		homeID := uuid.MustParse("c5f3a5e2-1d4b-4c8e-9a51-7f2b8d3e6a10")
+		vacuumID := uuid.MustParse("f52bad23-c201-414e-9bdb-af4327c42aa7")
+
+		_, err := r.SaveTag(ctx, todos.Tag{ID: homeID, Name: "household"}, now)
+		if err != nil {
+			t.Fatalf("could not save tag: %v", err)
+		}
+
+		err = r.DeleteTag(ctx, homeID, now)
+		if err != nil {
+			t.Fatalf("could not delete tag: %v", err)
+		}
+
+		events, err := r.GetTodoEvents(ctx, vacuumID)
+		if err != nil {
+			t.Fatalf("could not get todo events: %v", err)
+		}
+
+		var tags [][]string
+
+		for _, event := range events {
+			if event.Type == todos.EventUpdated && event.After != nil {
+				tags = append(tags, event.After.Tags)
+			}
+		}
+
+		expectedTags := [][]string{{"household", "urgent"}, {"urgent"}}
+		if !cmp.Equal(expectedTags, tags) {
+			t.Fatalf("changes of tag should be recorded: %s", cmp.Diff(expectedTags, tags))
+		}
+
+		versions, err := r.GetTodoVersions(ctx, vacuumID)
+		if err != nil {
+			t.Fatalf("could not get todo versions: %v", err)
+		}
+
+		expectedLen := 3
+		if len(versions) != expectedLen || !cmp.Equal([]string{"household", "urgent"}, versions[1].Todo.Tags) {
+			t.Fatalf("todo versions should follow changes of tag: %+v", versions)
+		}
+	})
+
+	t.Run("Rebalance todo positions", func(t *testing.T) {
+		t.Parallel()
+
+		r := test.NewTestMemoryRepository(t)
+
+		todo, err := r.CreateTodo(ctx, todos.Todo{Description: "Water the plants", CreatedAt: now})
+		if err != nil {
+			t.Fatalf("could not create todo: %v", err)
+		}
+
+		mopID := uuid.MustParse("62446c85-3798-471f-abb8-75c1cdd7153b")
+
+		// Moving the todo right after the first todo repeatedly halves the room between them.
+		for range 100 {
+			_, err = r.MoveTodo(ctx, todo.ID, repository.TodoMove{After: &mopID}, now)
+			if err != nil {
+				t.Fatalf("could not move todo: %v", err)
+			}
+
+			todo, err = r.CreateTodo(ctx, todos.Todo{Description: "Water the plants", CreatedAt: now})
+			if err != nil {
+				t.Fatalf("could not create todo: %v", err)
+			}
+		}
+
+		retrievedTodos, _, err := r.GetTodos(ctx, repository.TodosQuery{Limit: 2})
+		if err != nil {
+			t.Fatalf("could not get todos: %v", err)
+		}
+
+		for _, todo := range retrievedTodos {
+			if len(todo.Position) > 16 {
+				t.Fatalf("todo position should be rebalanced: actual: %s", todo.Position)
+			}
+		}
+
+		if retrievedTodos[0].ID != mopID || retrievedTodos[1].Description != "Water the plants" {
+			t.Fatalf("todos order does not match: expected: [Mop the floor, Water the plants] != actual: %v",
+				retrievedTodos,
+			)
+		}
+
+		vacuum, err := r.GetTodo(ctx, uuid.MustParse("f52bad23-c201-414e-9bdb-af4327c42aa7"))
+		if err != nil {
+			t.Fatalf("could not get todo: %v", err)
+		}
+
+		if vacuum.Version == 1 || vacuum.UpdatedAt == nil || !vacuum.UpdatedAt.Equal(now) {
+			t.Fatalf("rebalanced todo should have new version: %+v", vacuum)
+		}
+	})
+
+	t.Run("Purge deleted todos", func(t *testing.T) {
+		t.Parallel()
+
+		r := test.NewTestMemoryRepository(t)
+
+		purged, _, err := r.PurgeTodos(ctx, now, 10)
+		if err != nil {
+			t.Fatalf("could not purge todos: %v", err)
+		}
+
+		var expectedPurged int64 = 2
+		if purged != expectedPurged {
+			t.Fatalf("purged todos count does not match: expected: %d != actual: %d", expectedPurged, purged)
+		}
+	})
+
+	t.Run("Purge no todos with negative limit", func(t *testing.T) {
+		t.Parallel()
+
+		r := test.NewTestMemoryRepository(t)
+
+		purged, _, err := r.PurgeTodos(ctx, now, -1)
+		if err != nil {
+			t.Fatalf("could not purge todos: %v", err)
+		}
+
+		if purged != 0 {
+			t.Fatalf("no todos should be purged: %d", purged)
+		}
+	})
+
+	t.Run("Purge attachments of deleted todo", func(t *testing.T) {
+		t.Parallel()
+
+		r := test.NewTestMemoryRepository(t)
+		mopID := uuid.MustParse("62446c85-3798-471f-abb8-75c1cdd7153b")
+
+		attachment, err := r.CreateAttachment(ctx, todos.Attachment{
+			TodoID:      mopID,
+			Name:        "receipt.pdf",
+			ContentType: "application/pdf",
+			Size:        1024,
+			CreatedAt:   now,
+		})
+		if err != nil {
+			t.Fatalf("could not create attachment: %v", err)
+		}
+
+		err = r.DeleteTodo(ctx, mopID, 0, now)
+		if err != nil {
+			t.Fatalf("could not delete todo: %v", err)
+		}
+
+		_, attachments, err := r.PurgeTodos(ctx, now.Add(time.Second), 10)
+		if err != nil {
+			t.Fatalf("could not purge todos: %v", err)
+		}
+
+		expectedAttachments := []uuid.UUID{attachment.ID}
+		if !cmp.Equal(expectedAttachments, attachments) {
+			t.Fatalf("purged attachments do not match: %s", cmp.Diff(expectedAttachments, attachments))
+		}
+	})
+}
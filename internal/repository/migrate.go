@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"database/sql"
 	"embed"
 	"errors"
 	"fmt"
@@ -8,11 +9,17 @@ import (
 
 	"github.com/course-go/todos/internal/config"
 	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/sqlite"
 	"github.com/golang-migrate/migrate/v4/source/iofs"
 )
 
-//go:embed migrations/*.sql
-var embedMigrations embed.FS
+var (
+	//go:embed migrations/*.sql
+	embedMigrations embed.FS
+
+	//go:embed migrations/sqlite/*.sql
+	embedSQLiteMigrations embed.FS
+)
 
 func Migrate(config *config.Database, logger *slog.Logger) error {
 	databaseURL := fmt.Sprintf("%s://%s:%s@%s:%s/%s",
@@ -49,7 +56,41 @@ func Migrate(config *config.Database, logger *slog.Logger) error {
 		}
 	}()
 
-	err = m.Up()
+	return up(m, logger)
+}
+
+// MigrateSQLite migrates schema of the SQLite database.
+// The database is left open so that also in-memory databases can be migrated.
+func MigrateSQLite(db *sql.DB, logger *slog.Logger) error {
+	d, err := iofs.New(embedSQLiteMigrations, "migrations/sqlite")
+	if err != nil {
+		return fmt.Errorf("failed initializing driver from iofs: %w", err)
+	}
+
+	defer func() {
+		err := d.Close()
+		if err != nil {
+			logger.Warn("failed closing migrations source",
+				"error", err,
+			)
+		}
+	}()
+
+	driver, err := sqlite.WithInstance(db, &sqlite.Config{})
+	if err != nil {
+		return fmt.Errorf("failed initializing sqlite migrations driver: %w", err)
+	}
+
+	m, err := migrate.NewWithInstance("iofs", d, "sqlite", driver)
+	if err != nil {
+		return fmt.Errorf("failed creating migrations: %w", err)
+	}
+
+	return up(m, logger)
+}
+
+func up(m *migrate.Migrate, logger *slog.Logger) error {
+	err := m.Up()
 	if errors.Is(err, migrate.ErrNoChange) {
 		logger.Info("database schema is up to date")
 		return nil
@@ -0,0 +1,3 @@
+DROP INDEX todos_search_vector_idx;
+
+ALTER TABLE todos DROP COLUMN search_vector;
@@ -0,0 +1,4 @@
+ALTER TABLE todos
+  ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (to_tsvector('english', description)) STORED;
+
+CREATE INDEX todos_search_vector_idx ON todos USING GIN (search_vector);
@@ -0,0 +1,2 @@
+ALTER TABLE todos
+  ADD COLUMN priority SMALLINT NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+DROP INDEX todos_due_at_idx;
+
+ALTER TABLE todos DROP COLUMN due_at;
@@ -0,0 +1,4 @@
+ALTER TABLE todos
+  ADD COLUMN due_at TIMESTAMP;
+
+CREATE INDEX todos_due_at_idx ON todos (due_at);
@@ -0,0 +1,12 @@
+CREATE TABLE tags (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  name TEXT NOT NULL UNIQUE
+);
+
+CREATE TABLE todo_tags (
+  todo_id UUID NOT NULL REFERENCES todos (id) ON DELETE CASCADE,
+  tag_id UUID NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+  PRIMARY KEY (todo_id, tag_id)
+);
+
+CREATE INDEX todo_tags_tag_id_idx ON todo_tags (tag_id);
@@ -0,0 +1,5 @@
+DROP INDEX todos_list_id_idx;
+
+ALTER TABLE todos DROP COLUMN list_id;
+
+DROP TABLE lists;
@@ -0,0 +1,14 @@
+CREATE TABLE lists (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  name TEXT NOT NULL,
+  color TEXT NOT NULL DEFAULT '',
+  archived BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP,
+  deleted_at TIMESTAMP
+);
+
+ALTER TABLE todos
+  ADD COLUMN list_id UUID REFERENCES lists (id) ON DELETE SET NULL;
+
+CREATE INDEX todos_list_id_idx ON todos (list_id);
@@ -0,0 +1,5 @@
+DROP INDEX todos_parent_id_idx;
+
+ALTER TABLE todos
+  DROP COLUMN subtask_order,
+  DROP COLUMN parent_id;
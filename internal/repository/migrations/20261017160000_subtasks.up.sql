@@ -0,0 +1,5 @@
+ALTER TABLE todos
+  ADD COLUMN parent_id UUID REFERENCES todos (id) ON DELETE SET NULL,
+  ADD COLUMN subtask_order INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX todos_parent_id_idx ON todos (parent_id);
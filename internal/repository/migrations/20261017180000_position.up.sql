@@ -0,0 +1,17 @@
+-- Positions are compared bytewise regardless of the database collation.
+ALTER TABLE todos
+  ADD COLUMN position TEXT COLLATE "C" NOT NULL DEFAULT '';
+
+-- Existing todos keep the order they were created in.
+UPDATE todos
+SET position = ordered.position
+FROM (
+  SELECT id, lpad(row_number() OVER (ORDER BY created_at, id)::text, 8, '0') || 'i' AS position
+  FROM todos
+) AS ordered
+WHERE todos.id = ordered.id;
+
+ALTER TABLE todos
+  ALTER COLUMN position DROP DEFAULT;
+
+CREATE INDEX todos_position_idx ON todos (position);
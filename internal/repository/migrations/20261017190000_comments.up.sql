@@ -0,0 +1,12 @@
+-- Comments are only marked as deleted, they are removed together with their todo when it is purged.
+CREATE TABLE comments (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  todo_id UUID NOT NULL REFERENCES todos (id) ON DELETE CASCADE,
+  author TEXT NOT NULL,
+  body TEXT NOT NULL,
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP,
+  deleted_at TIMESTAMP
+);
+
+CREATE INDEX comments_todo_id_idx ON comments (todo_id);
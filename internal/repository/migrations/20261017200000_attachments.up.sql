@@ -0,0 +1,11 @@
+-- Attachments only describe the files, their contents are kept in blob storage under their IDs.
+CREATE TABLE attachments (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  todo_id UUID NOT NULL REFERENCES todos (id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  content_type TEXT NOT NULL,
+  size BIGINT NOT NULL,
+  created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX attachments_todo_id_idx ON attachments (todo_id);
@@ -0,0 +1,13 @@
+-- Events keep the todo before and after the change, they are removed together with their todo when it is purged.
+CREATE TABLE todo_events (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  seq BIGINT GENERATED ALWAYS AS IDENTITY,
+  todo_id UUID NOT NULL REFERENCES todos (id) ON DELETE CASCADE,
+  type TEXT NOT NULL,
+  actor TEXT NOT NULL,
+  before JSONB,
+  after JSONB,
+  occurred_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX todo_events_todo_id_idx ON todo_events (todo_id, occurred_at, seq);
@@ -0,0 +1,11 @@
+-- Only hashes of the tokens are stored. Revoked keys are kept to show when they were revoked.
+CREATE TABLE api_keys (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  name TEXT NOT NULL,
+  hash TEXT NOT NULL UNIQUE,
+  scopes TEXT[] NOT NULL,
+  expires_at TIMESTAMP,
+  last_used_at TIMESTAMP,
+  created_at TIMESTAMP NOT NULL,
+  revoked_at TIMESTAMP
+);
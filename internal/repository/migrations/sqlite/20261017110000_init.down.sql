@@ -0,0 +1,2 @@
+DROP TABLE todos_search;
+DROP TABLE todos;
@@ -0,0 +1,25 @@
+-- Timestamps are stored as UTC text with microsecond precision, e.g. '2024-07-26 22:48:21.090537Z',
+-- so that they sort the same way as they would as timestamps.
+CREATE TABLE todos (
+  id TEXT PRIMARY KEY,
+  description TEXT NOT NULL,
+  created_at TEXT NOT NULL,
+  updated_at TEXT,
+  completed_at TEXT,
+  deleted_at TEXT,
+  version INTEGER NOT NULL DEFAULT 1
+);
+
+CREATE VIRTUAL TABLE todos_search USING fts5(id UNINDEXED, description, tokenize = 'porter');
+
+CREATE TRIGGER todos_search_insert AFTER INSERT ON todos BEGIN
+  INSERT INTO todos_search (id, description) VALUES (new.id, new.description);
+END;
+
+CREATE TRIGGER todos_search_update AFTER UPDATE OF description ON todos BEGIN
+  UPDATE todos_search SET description = new.description WHERE id = old.id;
+END;
+
+CREATE TRIGGER todos_search_delete AFTER DELETE ON todos BEGIN
+  DELETE FROM todos_search WHERE id = old.id;
+END;
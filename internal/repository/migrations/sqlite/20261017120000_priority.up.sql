@@ -0,0 +1,2 @@
+ALTER TABLE todos
+  ADD COLUMN priority INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,16 @@
+CREATE TABLE lists (
+  id TEXT PRIMARY KEY,
+  name TEXT NOT NULL,
+  color TEXT NOT NULL DEFAULT '',
+  archived INTEGER NOT NULL DEFAULT 0,
+  created_at TEXT NOT NULL,
+  updated_at TEXT,
+  deleted_at TEXT
+);
+
+-- SQLite cannot drop columns with foreign keys so the list is only checked by the repository.
+-- Lists are never removed from the table since they are only marked as deleted.
+ALTER TABLE todos
+  ADD COLUMN list_id TEXT;
+
+CREATE INDEX todos_list_id_idx ON todos (list_id);
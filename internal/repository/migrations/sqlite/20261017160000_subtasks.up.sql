@@ -0,0 +1,9 @@
+-- SQLite cannot drop columns with foreign keys so the parent is only checked by the repository.
+-- Purged parents may therefore leave deleted subtasks referencing them.
+ALTER TABLE todos
+  ADD COLUMN parent_id TEXT;
+
+ALTER TABLE todos
+  ADD COLUMN subtask_order INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX todos_parent_id_idx ON todos (parent_id);
@@ -0,0 +1,3 @@
+DROP INDEX todos_position_idx;
+
+ALTER TABLE todos DROP COLUMN position;
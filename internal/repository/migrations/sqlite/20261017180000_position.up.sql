@@ -0,0 +1,12 @@
+ALTER TABLE todos
+  ADD COLUMN position TEXT NOT NULL DEFAULT '';
+
+-- Existing todos keep the order they were created in.
+UPDATE todos
+SET position = (
+  SELECT printf('%08di', ordered.n)
+  FROM (SELECT id, row_number() OVER (ORDER BY created_at, id) AS n FROM todos) AS ordered
+  WHERE ordered.id = todos.id
+);
+
+CREATE INDEX todos_position_idx ON todos (position);
@@ -0,0 +1,12 @@
+-- Comments are only marked as deleted, they are removed together with their todo when it is purged.
+CREATE TABLE comments (
+  id TEXT PRIMARY KEY,
+  todo_id TEXT NOT NULL REFERENCES todos (id) ON DELETE CASCADE,
+  author TEXT NOT NULL,
+  body TEXT NOT NULL,
+  created_at TEXT NOT NULL,
+  updated_at TEXT,
+  deleted_at TEXT
+);
+
+CREATE INDEX comments_todo_id_idx ON comments (todo_id);
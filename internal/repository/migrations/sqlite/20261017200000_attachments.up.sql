@@ -0,0 +1,11 @@
+-- Attachments only describe the files, their contents are kept in blob storage under their IDs.
+CREATE TABLE attachments (
+  id TEXT PRIMARY KEY,
+  todo_id TEXT NOT NULL REFERENCES todos (id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  content_type TEXT NOT NULL,
+  size INTEGER NOT NULL,
+  created_at TEXT NOT NULL
+);
+
+CREATE INDEX attachments_todo_id_idx ON attachments (todo_id);
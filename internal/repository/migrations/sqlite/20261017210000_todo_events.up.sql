@@ -0,0 +1,13 @@
+-- Events keep the todo before and after the change, they are removed together with their todo when it is purged.
+-- Events recorded at the same time are ordered by their rowid.
+CREATE TABLE todo_events (
+  id TEXT PRIMARY KEY,
+  todo_id TEXT NOT NULL REFERENCES todos (id) ON DELETE CASCADE,
+  type TEXT NOT NULL,
+  actor TEXT NOT NULL,
+  before TEXT,
+  after TEXT,
+  occurred_at TEXT NOT NULL
+);
+
+CREATE INDEX todo_events_todo_id_idx ON todo_events (todo_id, occurred_at);
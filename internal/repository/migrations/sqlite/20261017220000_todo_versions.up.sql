@@ -0,0 +1,9 @@
+-- Versions keep the todo after each of its changes, they are removed together with their todo when it is purged.
+-- Version is valid until the valid_from of the next version of the same todo.
+CREATE TABLE todo_versions (
+  todo_id TEXT NOT NULL REFERENCES todos (id) ON DELETE CASCADE,
+  version INTEGER NOT NULL,
+  todo TEXT NOT NULL,
+  valid_from TEXT NOT NULL,
+  PRIMARY KEY (todo_id, version)
+);
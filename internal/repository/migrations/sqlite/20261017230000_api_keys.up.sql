@@ -0,0 +1,12 @@
+-- Only hashes of the tokens are stored. Revoked keys are kept to show when they were revoked.
+-- Scopes are stored as JSON array.
+CREATE TABLE api_keys (
+  id TEXT PRIMARY KEY,
+  name TEXT NOT NULL,
+  hash TEXT NOT NULL UNIQUE,
+  scopes TEXT NOT NULL,
+  expires_at TEXT,
+  last_used_at TEXT,
+  created_at TEXT NOT NULL,
+  revoked_at TEXT
+);
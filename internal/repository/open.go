@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/course-go/todos/internal/config"
+	"github.com/course-go/todos/internal/health"
+)
+
+const MemoryDriver = "memory"
+
+// Open creates repository selected by the database config.
+// Database schema is migrated before the repository is created.
+func Open( //nolint: ireturn
+	ctx context.Context,
+	logger *slog.Logger,
+	registry *health.Registry,
+	config *config.Database,
+) (repository TodoRepository, err error) {
+	if config.Driver == MemoryDriver {
+		logger.Warn("using in-memory repository, todos will be lost on exit")
+		return NewMemory(), nil
+	}
+
+	if config.Protocol == SQLiteProtocol {
+		r, err := NewSQLite(ctx, logger, registry, config)
+		if err != nil {
+			return nil, err
+		}
+
+		return r, nil
+	}
+
+	err = Migrate(config, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed migrating database: %w", err)
+	}
+
+	r, err := New(ctx, logger, registry, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
@@ -0,0 +1,358 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/course-go/todos/internal/todos"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Positions are keys ordering todos lexicographically. They consist of base 36 digits
+// and are interpreted as fractions so there is always another key between any two of them.
+// Keys never end with zero as nothing could be placed right before them otherwise.
+const (
+	positionDigits = "0123456789abcdefghijklmnopqrstuvwxyz"
+	positionBase   = len(positionDigits)
+	// maxPositionLength is the length of keys above which all the keys are rebalanced.
+	maxPositionLength = 16
+	// positionLockKey identifies the advisory lock serialising the changes of positions.
+	positionLockKey = 7_302_140_235
+)
+
+var (
+	ErrNeighborNotFound = errors.New("neighbor todo with given UUID does not exist")
+	ErrInvalidMove      = errors.New("todo has to be moved next to other todos in their order")
+)
+
+// TodoMove places todo between its neighbors. At least one of them has to be set.
+// If only one of them is set, the todo is placed right next to it.
+type TodoMove struct {
+	// Before is the todo which should follow the moved todo.
+	Before *uuid.UUID
+	// After is the todo which should precede the moved todo.
+	After *uuid.UUID
+}
+
+// MoveTodo places the todo between its neighbors. If the new position of the todo
+// would be too long, positions of all todos which are not deleted are rebalanced first.
+func (r Repository) MoveTodo(
+	ctx context.Context,
+	id uuid.UUID,
+	move TodoMove,
+	movedAt time.Time,
+) (movedTodo todos.Todo, err error) {
+	err = pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		movedTodo, err = moveTodo(ctx, tx, id, move, movedAt)
+		return err
+	})
+	if err != nil {
+		return todos.Todo{}, fmt.Errorf("failed moving todo: %w", err)
+	}
+
+	return movedTodo, nil
+}
+
+func moveTodo(ctx context.Context, q querier, id uuid.UUID, move TodoMove, movedAt time.Time) (todos.Todo, error) {
+	previousTodo, err := lockTodo(ctx, q, id)
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	position, err := balancedPosition(ctx, q, movedAt, func() (string, error) {
+		return movePosition(ctx, q, id, move)
+	})
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	_, err = q.Exec(ctx,
+		`
+		UPDATE todos
+		SET position = $2, updated_at = $3, version = version + 1
+		WHERE id=$1
+		`,
+		id,
+		position,
+		movedAt,
+	)
+	if err != nil {
+		return todos.Todo{}, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	movedTodo, err := getTodo(ctx, q, id)
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	event := newEvent(ctx, todos.EventMoved, &previousTodo, &movedTodo, movedAt)
+
+	err = recordChange(ctx, q, event, previousTodo, movedTodo)
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	return movedTodo, nil
+}
+
+// movePosition finds position between the neighbors of the moved todo.
+func movePosition(ctx context.Context, q querier, id uuid.UUID, move TodoMove) (position string, err error) {
+	err = checkMove(id, move)
+	if err != nil {
+		return "", err
+	}
+
+	var lower, upper string
+
+	if move.After != nil {
+		lower, err = neighborPosition(ctx, q, *move.After)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if move.Before != nil {
+		upper, err = neighborPosition(ctx, q, *move.Before)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	switch {
+	case move.After == nil:
+		err = q.QueryRow(ctx,
+			"SELECT COALESCE(MAX(position), '') FROM todos WHERE position < $1 AND id <> $2 AND deleted_at IS NULL",
+			upper,
+			id,
+		).Scan(&lower)
+	case move.Before == nil:
+		err = q.QueryRow(ctx,
+			"SELECT COALESCE(MIN(position), '') FROM todos WHERE position > $1 AND id <> $2 AND deleted_at IS NULL",
+			lower,
+			id,
+		).Scan(&upper)
+	case lower >= upper:
+		return "", ErrInvalidMove
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return positionBetween(lower, upper), nil
+}
+
+// appendPosition returns position following all todos.
+func appendPosition(ctx context.Context, q querier, appendedAt time.Time) (position string, err error) {
+	return balancedPosition(ctx, q, appendedAt, func() (string, error) {
+		var last string
+
+		err := q.QueryRow(ctx, "SELECT COALESCE(MAX(position), '') FROM todos WHERE deleted_at IS NULL").Scan(&last)
+		if err != nil {
+			return "", fmt.Errorf("%w: %w", ErrDatabase, err)
+		}
+
+		return positionAfter(last), nil
+	})
+}
+
+// balancedPosition returns the position found by the function.
+// If it is too long, the positions of todos which are not deleted are rebalanced
+// and the position is found again. Todos whose position changes get a new version.
+// Positions are found one at a time so that concurrent changes never get the same position.
+// The querier should be a transaction as the lock is held until its end.
+func balancedPosition(
+	ctx context.Context,
+	q querier,
+	balancedAt time.Time,
+	find func() (string, error),
+) (position string, err error) {
+	err = lockPositions(ctx, q)
+	if err != nil {
+		return "", err
+	}
+
+	position, err = find()
+	if err != nil || len(position) <= maxPositionLength {
+		return position, err
+	}
+
+	rows, err := q.Query(ctx, "SELECT id FROM todos WHERE deleted_at IS NULL ORDER BY position, id")
+	if err != nil {
+		return "", fmt.Errorf("failed querying database: %w", err)
+	}
+
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	_, err = q.Exec(ctx,
+		`
+		UPDATE todos
+		SET position = balanced.position, updated_at = $3, version = version + 1
+		FROM unnest($1::uuid[], $2::text[]) AS balanced (id, position)
+		WHERE todos.id = balanced.id AND todos.position <> balanced.position
+		`,
+		ids,
+		balancedPositions(len(ids)),
+		balancedAt,
+	)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return find()
+}
+
+// restoredPosition returns position of the restored todo. The todo keeps its position
+// unless another todo took it while it was deleted, in which case it follows all todos.
+func restoredPosition(ctx context.Context, q querier, todo todos.Todo, restoredAt time.Time) (string, error) {
+	err := lockPositions(ctx, q)
+	if err != nil {
+		return "", err
+	}
+
+	var taken bool
+
+	err = q.QueryRow(ctx,
+		"SELECT EXISTS (SELECT 1 FROM todos WHERE position=$1 AND id <> $2 AND deleted_at IS NULL)",
+		todo.Position,
+		todo.ID,
+	).Scan(&taken)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	if !taken {
+		return todo.Position, nil
+	}
+
+	return appendPosition(ctx, q, restoredAt)
+}
+
+// lockPositions serialises the changes of positions until the end of the transaction.
+func lockPositions(ctx context.Context, q querier) error {
+	_, err := q.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", positionLockKey)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return nil
+}
+
+func neighborPosition(ctx context.Context, q querier, id uuid.UUID) (position string, err error) {
+	err = q.QueryRow(ctx, "SELECT position FROM todos WHERE id=$1 AND deleted_at IS NULL", id).Scan(&position)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrNeighborNotFound
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return position, nil
+}
+
+// checkMove verifies that the todo is moved next to at least one other todo.
+func checkMove(id uuid.UUID, move TodoMove) error {
+	switch {
+	case move.Before == nil && move.After == nil:
+		return ErrInvalidMove
+	case move.Before != nil && *move.Before == id, move.After != nil && *move.After == id:
+		return ErrInvalidMove
+	default:
+		return nil
+	}
+}
+
+// positionAfter returns key following the given one, empty key meaning there is none.
+// The last digit is incremented carrying over the maximal ones, so the key only grows
+// by the smallest digit when all of its digits are maximal. Appending todos one after
+// another thus uses all the keys of the same length before the keys get longer.
+func positionAfter(key string) string {
+	if key == "" {
+		return positionDigits[positionBase/2 : positionBase/2+1]
+	}
+
+	for i := len(key) - 1; i >= 0; i-- {
+		digit := strings.IndexByte(positionDigits, key[i])
+		if digit < positionBase-1 {
+			return key[:i] + positionDigits[digit+1:digit+2]
+		}
+	}
+
+	return key + positionDigits[1:2]
+}
+
+// positionBetween returns key between the given keys. Empty lower key means the start
+// and empty upper key means the end of the order. The lower key has to precede the upper one.
+func positionBetween(lower, upper string) string {
+	if upper != "" {
+		// Missing digits of the lower key are zeros.
+		n := 0
+		for n < len(upper) && positionDigit(lower, n) == strings.IndexByte(positionDigits, upper[n]) {
+			n++
+		}
+
+		if n > 0 {
+			return upper[:n] + positionBetween(lower[min(n, len(lower)):], upper[n:])
+		}
+	}
+
+	lowerDigit := positionDigit(lower, 0)
+
+	upperDigit := positionBase
+	if upper != "" {
+		upperDigit = strings.IndexByte(positionDigits, upper[0])
+	}
+
+	if upperDigit-lowerDigit > 1 {
+		middle := lowerDigit + (upperDigit-lowerDigit)>>1
+		return positionDigits[middle : middle+1]
+	}
+
+	// The first digit of longer upper key precedes it.
+	if len(upper) > 1 {
+		return upper[:1]
+	}
+
+	return positionDigits[lowerDigit:lowerDigit+1] + positionBetween(lower[min(1, len(lower)):], "")
+}
+
+// balancedPositions returns the given number of the shortest keys evenly spread across the order
+// so that there is enough room between the neighboring keys.
+func balancedPositions(count int) []string {
+	width := 1
+	capacity := positionBase
+
+	for capacity/(count+1) < positionBase {
+		width++
+		capacity *= positionBase
+	}
+
+	step := capacity / (count + 1)
+	positions := make([]string, 0, count)
+
+	for i := range count {
+		key := strconv.FormatInt(int64((i+1)*step), positionBase)
+		key = strings.Repeat("0", width-len(key)) + key
+		positions = append(positions, strings.TrimRight(key, "0"))
+	}
+
+	return positions
+}
+
+// positionDigit returns the digit of the key at the given index, missing digits being zeros.
+func positionDigit(key string, i int) int {
+	if i >= len(key) {
+		return 0
+	}
+
+	return strings.IndexByte(positionDigits, key[i])
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/course-go/todos/internal/todos"
+	"github.com/google/uuid"
+)
+
+var ErrInvalidCursor = errors.New("invalid pagination cursor")
+
+// TodosQuery describes which page of todos should be retrieved and in what order.
+// Zero Limit means that the page is not limited, empty Sort means [DefaultSort].
+type TodosQuery struct {
+	Filter TodosFilter
+	Sort   []Sort
+	Limit  int
+	Cursor *Cursor
+}
+
+// TodosFilter narrows down retrieved todos. Zero values do not filter anything.
+// Lower time bounds are inclusive, upper time bounds are exclusive.
+type TodosFilter struct {
+	ListID         *uuid.UUID
+	Completed      *bool
+	CreatedAfter   *time.Time
+	CreatedBefore  *time.Time
+	CompletedAfter *time.Time
+	DueAfter       *time.Time
+	DueBefore      *time.Time
+	// Overdue matches todos which are not completed and were due before Now.
+	// If false, it matches all the other todos.
+	Overdue *bool
+	Now     time.Time
+	// Priorities match todos with any of the priorities.
+	Priorities []todos.Priority
+	// Tags match todos with any of the tags or, if AllTags is set, with all of them.
+	Tags    []string
+	AllTags bool
+	// Text is matched as case-insensitive substring of the description.
+	Text string
+}
+
+// Cursor points to the last todo of an already retrieved page.
+// Todos are paginated by keyset consisting of the sorted fields and ID
+// so the cursor is only valid for the sort it was created with.
+type Cursor struct {
+	Sort string     `json:"sort"`
+	Todo todos.Todo `json:"todo"`
+}
+
+func NewCursor(sort []Sort, todo todos.Todo) *Cursor {
+	var keys todos.Todo
+	for _, s := range keyset(sort) {
+		sortFields[s.Field].pick(&keys, todo)
+	}
+
+	return &Cursor{
+		Sort: FormatSort(sort),
+		Todo: keys,
+	}
+}
+
+// DecodeCursor parses cursor previously encoded by [Cursor.Encode].
+func DecodeCursor(s string) (cursor *Cursor, err error) {
+	var c Cursor
+
+	err = decodeCursor(s, &c)
+	if err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// Encode returns opaque URL safe representation of the cursor.
+func (c Cursor) Encode() string {
+	return encodeCursor(c)
+}
+
+// SearchQuery describes which page of todos matching the text should be retrieved.
+// Zero Limit means that the page is not limited.
+type SearchQuery struct {
+	Text   string
+	Limit  int
+	Cursor *SearchCursor
+}
+
+// SearchCursor points to the last result of an already retrieved page.
+// Results are paginated by keyset consisting of their rank and ID
+// so the cursor is only valid for the text it was created with.
+type SearchCursor struct {
+	Text string    `json:"text"`
+	Rank float32   `json:"rank"`
+	ID   uuid.UUID `json:"id"`
+}
+
+func NewSearchCursor(text string, result todos.SearchResult) *SearchCursor {
+	return &SearchCursor{
+		Text: text,
+		Rank: result.Rank,
+		ID:   result.ID,
+	}
+}
+
+// Matches in snippets are first delimited by control characters which cannot be confused with HTML.
+const (
+	snippetStartSel = "\x02"
+	snippetStopSel  = "\x03"
+)
+
+// snippetReplacer escapes the snippet as HTML and replaces the match delimiters with <mark> tags.
+var snippetReplacer = strings.NewReplacer(
+	"<", "&lt;",
+	">", "&gt;",
+	"&", "&amp;",
+	"'", "&#39;",
+	`"`, "&#34;",
+	snippetStartSel, "<mark>",
+	snippetStopSel, "</mark>",
+)
+
+// markSnippet returns the snippet with matches delimited by [snippetStartSel] and [snippetStopSel]
+// escaped so that it is safe to be rendered as HTML with the matches enclosed in <mark> tags.
+func markSnippet(snippet string) string {
+	return snippetReplacer.Replace(snippet)
+}
+
+// DecodeSearchCursor parses cursor previously encoded by [SearchCursor.Encode].
+func DecodeSearchCursor(s string) (cursor *SearchCursor, err error) {
+	var c SearchCursor
+
+	err = decodeCursor(s, &c)
+	if err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// Encode returns opaque URL safe representation of the cursor.
+func (c SearchCursor) Encode() string {
+	return encodeCursor(c)
+}
+
+func encodeCursor(cursor any) string {
+	bytes, err := json.Marshal(cursor)
+	if err != nil {
+		return ""
+	}
+
+	return base64.RawURLEncoding.EncodeToString(bytes)
+}
+
+func decodeCursor(s string, cursor any) error {
+	bytes, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	err = json.Unmarshal(bytes, cursor)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	return nil
+}
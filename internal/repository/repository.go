@@ -13,19 +13,78 @@ import (
 	_ "github.com/golang-migrate/migrate/v4/database/pgx/v5" // Used to register "pgx5" driver used for migrations.
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 const (
 	databaseHealthPingPeriod = 30 * time.Second
+
+	// todoColumns are the columns of the todos table mapped to [todos.Todo] fields.
+	todoColumns = "id, description, list_id, parent_id, subtask_order, priority, due_at, recurrence, position, " +
+		"completed_at, created_at, updated_at, deleted_at, version"
+
+	// postgresTodoColumns are all columns mapped to [todos.Todo] fields including the tags and subtasks progress.
+	postgresTodoColumns = todoColumns + `,
+		ARRAY(
+			SELECT tags.name
+			FROM todo_tags JOIN tags ON tags.id = todo_tags.tag_id
+			WHERE todo_tags.todo_id = todos.id
+			ORDER BY tags.name
+		) AS tags,
+		(
+			SELECT CASE WHEN count(*) > 0 THEN
+				json_build_object('completed', count(subtasks.completed_at), 'total', count(*))
+			END
+			FROM todos AS subtasks
+			WHERE subtasks.parent_id = todos.id AND subtasks.deleted_at IS NULL
+		) AS progress`
 )
 
 var (
-	ErrMigrations   = errors.New("failed migrating database schema")
-	ErrTodoNotFound = errors.New("todo with given UUID does not exist")
-	ErrDatabase     = errors.New("failed querying database")
+	ErrMigrations      = errors.New("failed migrating database schema")
+	ErrTodoNotFound    = errors.New("todo with given UUID does not exist")
+	ErrVersionConflict = errors.New("todo was modified by someone else")
+	ErrDatabase        = errors.New("failed querying database")
 )
 
+// TodoRepository stores todos. Deleted todos are kept until they are purged
+// and are treated as not existing by all methods except those working with deleted todos.
+type TodoRepository interface { //nolint: interfacebloat
+	TagRepository
+	ListRepository
+	SubtaskRepository
+	CommentRepository
+	AttachmentRepository
+	EventRepository
+	VersionRepository
+	APIKeyRepository
+
+	GetTodos(ctx context.Context, query TodosQuery) ([]todos.Todo, *Cursor, error)
+	SearchTodos(ctx context.Context, query SearchQuery) ([]todos.SearchResult, *SearchCursor, error)
+	GetTodosLastModified(ctx context.Context) (*time.Time, error)
+	GetDeletedTodos(ctx context.Context) ([]todos.Todo, error)
+	GetTodo(ctx context.Context, id uuid.UUID) (todos.Todo, error)
+	CreateTodo(ctx context.Context, todo todos.Todo) (todos.Todo, error)
+	SaveTodo(ctx context.Context, todo todos.Todo) (todos.Todo, error)
+	CompleteTodo(ctx context.Context, id uuid.UUID, completedAt time.Time) (todos.Todo, error)
+	MoveTodo(ctx context.Context, id uuid.UUID, move TodoMove, movedAt time.Time) (todos.Todo, error)
+	ReopenTodo(ctx context.Context, id uuid.UUID, reopenedAt time.Time) (todos.Todo, error)
+	RestoreTodo(ctx context.Context, id uuid.UUID, restoredAt time.Time) (todos.Todo, error)
+	DeleteTodo(ctx context.Context, id uuid.UUID, version int, deletedAt time.Time) error
+	PurgeTodos(ctx context.Context, deletedBefore time.Time, limit int) (int64, []uuid.UUID, error)
+	ExecuteBatch(ctx context.Context, operations []BatchOperation, atomic bool, now time.Time) ([]BatchResult, error)
+}
+
+var _ TodoRepository = Repository{}
+
+// querier executes queries either directly on the pool or within a transaction.
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
 type Repository struct {
 	logger   *slog.Logger
 	registry *health.Registry
@@ -89,33 +148,149 @@ func New(
 	return repository, nil
 }
 
-func (r Repository) GetTodos(ctx context.Context) (t []todos.Todo, err error) {
-	rows, err := r.pool.Query(ctx,
-		`
-		SELECT id, description, completed_at, created_at, updated_at
+func (r Repository) GetTodos(
+	ctx context.Context,
+	query TodosQuery,
+) (t []todos.Todo, next *Cursor, err error) {
+	var b queryBuilder
+
+	b.where("deleted_at IS NULL")
+	filterTodos(&b, query.Filter)
+
+	sort := query.Sort
+	if len(sort) == 0 {
+		sort = DefaultSort()
+	}
+
+	if query.Cursor != nil {
+		afterCursor(&b, sort, *query.Cursor)
+	}
+
+	var limit *int
+
+	if query.Limit > 0 {
+		// One extra todo is fetched to find out whether there is a next page.
+		l := query.Limit + 1
+		limit = &l
+	}
+
+	sql := `
+		SELECT ` + postgresTodoColumns + `
 		FROM todos
-		WHERE deleted_at IS NULL
-		`,
-	)
+		` + b.whereClause() + `
+		` + orderTodos(sort) + `
+		LIMIT ` + b.arg(limit)
+
+	rows, err := r.pool.Query(ctx, sql, b.args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed querying database: %w", err)
+		return nil, nil, fmt.Errorf("failed querying database: %w", err)
 	}
 
 	// Use append to avoid returning nil slice
 	t = make([]todos.Todo, 0)
 
 	t, err = pgx.AppendRows(t, rows, pgx.RowToStructByName[todos.Todo])
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	if query.Limit > 0 && len(t) > query.Limit {
+		t = t[:query.Limit]
+		next = NewCursor(sort, t[len(t)-1])
+	}
+
+	return t, next, nil
+}
+
+func (r Repository) SearchTodos(
+	ctx context.Context,
+	query SearchQuery,
+) (results []todos.SearchResult, next *SearchCursor, err error) {
+	var b queryBuilder
+
+	text := b.arg(query.Text)
+	headlineOptions := b.arg(`StartSel="`+snippetStartSel+`", StopSel="`+snippetStopSel+`"`) + "::text"
+
+	if query.Cursor != nil {
+		rank := b.arg(query.Cursor.Rank)
+		b.where(fmt.Sprintf("(rank < %s::real OR (rank = %s::real AND id > %s::uuid))",
+			rank,
+			rank,
+			b.arg(query.Cursor.ID),
+		))
+	}
+
+	var limit *int
+
+	if query.Limit > 0 {
+		// One extra result is fetched to find out whether there is a next page.
+		l := query.Limit + 1
+		limit = &l
+	}
+
+	sql := `
+		WITH matches AS (
+			SELECT ` + postgresTodoColumns + `,
+				ts_rank(search_vector, query) AS rank, query
+			FROM todos, websearch_to_tsquery('english', ` + text + `) AS query
+			WHERE deleted_at IS NULL AND search_vector @@ query
+		)
+		SELECT ` + todoColumns + `, tags, progress, rank,
+			ts_headline('english', description, query, ` + headlineOptions + `) AS snippet
+		FROM matches
+		` + b.whereClause() + `
+		ORDER BY rank DESC, id
+		LIMIT ` + b.arg(limit)
+
+	rows, err := r.pool.Query(ctx, sql, b.args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed querying database: %w", err)
+	}
+
+	// Use append to avoid returning nil slice
+	results = make([]todos.SearchResult, 0)
+
+	results, err = pgx.AppendRows(results, rows, pgx.RowToStructByName[todos.SearchResult])
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	for i := range results {
+		results[i].Snippet = markSnippet(results[i].Snippet)
+	}
+
+	if query.Limit > 0 && len(results) > query.Limit {
+		results = results[:query.Limit]
+		next = NewSearchCursor(query.Text, results[len(results)-1])
+	}
+
+	return results, next, nil
+}
+
+// GetTodosLastModified returns time of the latest creation, change or deletion of any todo.
+// Nil is returned if there are no todos at all.
+func (r Repository) GetTodosLastModified(ctx context.Context) (lastModified *time.Time, err error) {
+	err = r.pool.QueryRow(ctx,
+		`
+		SELECT MAX(GREATEST(created_at, updated_at, deleted_at))
+		FROM todos
+		`,
+	).Scan(&lastModified)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
 	}
 
-	return t, nil
+	return lastModified, nil
 }
 
 func (r Repository) GetTodo(ctx context.Context, id uuid.UUID) (t todos.Todo, err error) {
-	rows, err := r.pool.Query(ctx,
+	return getTodo(ctx, r.pool, id)
+}
+
+func getTodo(ctx context.Context, q querier, id uuid.UUID) (t todos.Todo, err error) {
+	rows, err := q.Query(ctx,
 		`
-		SELECT id, description, completed_at, created_at, updated_at
+		SELECT `+postgresTodoColumns+`
 		FROM todos
 		WHERE id=$1 AND deleted_at IS NULL
 		`,
@@ -137,74 +312,528 @@ func (r Repository) GetTodo(ctx context.Context, id uuid.UUID) (t todos.Todo, er
 	return t, nil
 }
 
-func (r Repository) CreateTodo(ctx context.Context, todo todos.Todo) (createdTodo todos.Todo, err error) {
+// GetDeletedTodos returns deleted todos, the most recently deleted first.
+func (r Repository) GetDeletedTodos(ctx context.Context) (t []todos.Todo, err error) {
 	rows, err := r.pool.Query(ctx,
 		`
-		INSERT INTO todos (description, created_at)
-		VALUES ($1, $2)
-		RETURNING id, description, completed_at, created_at, updated_at
+		SELECT `+postgresTodoColumns+`
+		FROM todos
+		WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC, id
 		`,
-		todo.Description,
-		todo.CreatedAt,
 	)
 	if err != nil {
-		return todos.Todo{}, fmt.Errorf("failed querying database: %w", err)
+		return nil, fmt.Errorf("failed querying database: %w", err)
 	}
 
-	createdTodo, err = pgx.CollectOneRow(rows, pgx.RowToStructByName[todos.Todo])
+	// Use append to avoid returning nil slice
+	t = make([]todos.Todo, 0)
+
+	t, err = pgx.AppendRows(t, rows, pgx.RowToStructByName[todos.Todo])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return t, nil
+}
+
+func (r Repository) CreateTodo(ctx context.Context, todo todos.Todo) (createdTodo todos.Todo, err error) {
+	err = pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		createdTodo, err = createTodo(ctx, tx, todo)
+		return err
+	})
+	if err != nil {
+		return todos.Todo{}, fmt.Errorf("failed creating todo: %w", err)
+	}
+
+	return createdTodo, nil
+}
+
+// createTodo inserts the todo and its tags. The querier should be a transaction
+// so that the todo is not created without its tags. Subtasks are ordered after the existing ones.
+func createTodo(ctx context.Context, q querier, todo todos.Todo) (createdTodo todos.Todo, err error) {
+	err = checkList(ctx, q, todo.ListID)
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	err = checkParent(ctx, q, uuid.Nil, todo.ParentID)
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	position, err := appendPosition(ctx, q, todo.CreatedAt)
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	var id uuid.UUID
+
+	err = q.QueryRow(ctx,
+		`
+		INSERT INTO todos (description, list_id, parent_id, subtask_order, priority, due_at, recurrence, position, created_at)
+		VALUES ($1, $2, $3, (SELECT COALESCE(MAX(subtask_order) + 1, 0) FROM todos WHERE parent_id = $3), $4, $5, $6, $7, $8)
+		RETURNING id
+		`,
+		todo.Description,
+		todo.ListID,
+		todo.ParentID,
+		todo.Priority,
+		todo.DueAt,
+		todo.Recurrence,
+		position,
+		todo.CreatedAt,
+	).Scan(&id)
 	if err != nil {
 		return todos.Todo{}, fmt.Errorf("%w: %w", ErrDatabase, err)
 	}
 
+	err = setTodoTags(ctx, q, id, normalizeTags(todo.Tags))
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	createdTodo, err = getTodo(ctx, q, id)
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	event := newEvent(ctx, todos.EventCreated, nil, &createdTodo, todo.CreatedAt)
+
+	err = recordChange(ctx, q, event, createdTodo)
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
 	return createdTodo, nil
 }
 
+// SaveTodo updates the todo including its tags. If the todo has non-zero version,
+// the update only succeeds if the stored todo still has the same version.
+// Completing a recurring todo creates its next occurrence like [Repository.CompleteTodo] does.
 func (r Repository) SaveTodo(ctx context.Context, todo todos.Todo) (savedTodo todos.Todo, err error) {
-	rows, err := r.pool.Query(ctx,
+	err = pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		savedTodo, err = saveTodo(ctx, tx, todo)
+		return err
+	})
+	if err != nil {
+		return todos.Todo{}, fmt.Errorf("failed saving todo: %w", err)
+	}
+
+	return savedTodo, nil
+}
+
+// saveTodo updates the todo and its tags. The querier should be a transaction
+// so that the todo is not updated without its tags. Re-parented todos are ordered after the existing subtasks.
+func saveTodo(ctx context.Context, q querier, todo todos.Todo) (savedTodo todos.Todo, err error) {
+	previousTodo, err := lockTodo(ctx, q, todo.ID)
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	c, err := q.Exec(ctx,
 		`
 		UPDATE todos
-		SET description = $2, completed_at = $3, updated_at = $4
-		WHERE id=$1
-		RETURNING id, description, completed_at, created_at, updated_at
+		SET description = $2, list_id = $3, priority = $4, due_at = $5, completed_at = $6, updated_at = $7,
+			version = version + 1,
+			subtask_order = CASE WHEN parent_id IS NOT DISTINCT FROM $9 THEN subtask_order ELSE
+				(SELECT COALESCE(MAX(subtasks.subtask_order) + 1, 0) FROM todos AS subtasks WHERE subtasks.parent_id = $9)
+			END,
+			parent_id = $9,
+			recurrence = $10
+		WHERE id=$1 AND deleted_at IS NULL AND ($8 = 0 OR version = $8)
 		`,
 		todo.ID,
 		todo.Description,
+		todo.ListID,
+		todo.Priority,
+		todo.DueAt,
 		todo.CompletedAt,
 		todo.UpdatedAt,
+		todo.Version,
+		todo.ParentID,
+		todo.Recurrence,
+	)
+	if err != nil {
+		return todos.Todo{}, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	if c.RowsAffected() == 0 {
+		return todos.Todo{}, missingTodoError(ctx, q, todo.ID, todo.Version)
+	}
+
+	err = checkList(ctx, q, todo.ListID)
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	err = checkParent(ctx, q, todo.ID, todo.ParentID)
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	err = setTodoTags(ctx, q, todo.ID, normalizeTags(todo.Tags))
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	savedTodo, err = getTodo(ctx, q, todo.ID)
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	event := newEvent(ctx, todos.EventUpdated, &previousTodo, &savedTodo, changedAt(todo.UpdatedAt))
+
+	err = recordChange(ctx, q, event, previousTodo, savedTodo)
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	err = createNextOccurrence(ctx, q, previousTodo, savedTodo)
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	return savedTodo, nil
+}
+
+// completeTodo marks todo as completed and creates its next occurrence if it recurs.
+// The querier should be a transaction so that the todo is not completed without its next occurrence.
+func completeTodo(ctx context.Context, q querier, id uuid.UUID, completedAt time.Time) (todos.Todo, error) {
+	previousTodo, err := lockTodo(ctx, q, id)
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	rows, err := q.Query(ctx,
+		`
+		UPDATE todos
+		SET completed_at = $2, updated_at = $2, version = version + 1
+		WHERE id=$1 AND deleted_at IS NULL AND completed_at IS NULL
+		RETURNING `+postgresTodoColumns,
+		id,
+		completedAt,
 	)
 	if err != nil {
 		return todos.Todo{}, fmt.Errorf("failed querying database: %w", err)
 	}
 
-	savedTodo, err = pgx.CollectOneRow(rows, pgx.RowToStructByName[todos.Todo])
+	completedTodo, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[todos.Todo])
 	if errors.Is(err, pgx.ErrNoRows) {
-		return todos.Todo{}, ErrTodoNotFound
+		// The todo is already completed.
+		return previousTodo, nil
 	}
 
 	if err != nil {
 		return todos.Todo{}, fmt.Errorf("%w: %w", ErrDatabase, err)
 	}
 
-	return savedTodo, nil
+	event := newEvent(ctx, todos.EventCompleted, &previousTodo, &completedTodo, completedAt)
+
+	err = recordChange(ctx, q, event, previousTodo, completedTodo)
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	err = createNextOccurrence(ctx, q, previousTodo, completedTodo)
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	return completedTodo, nil
+}
+
+// createNextOccurrence creates the next occurrence of the recurring todo if the change completed it,
+// no matter whether the todo was completed or saved with its completion time set.
+func createNextOccurrence(ctx context.Context, q querier, previousTodo, todo todos.Todo) error {
+	if previousTodo.CompletedAt != nil || todo.CompletedAt == nil {
+		return nil
+	}
+
+	next, ok := todo.NextOccurrence(*todo.CompletedAt)
+	if !ok {
+		return nil
+	}
+
+	_, err := createTodo(ctx, q, next)
+
+	return err
+}
+
+// CompleteTodo marks todo as completed at the given time. If the todo recurs,
+// its next occurrence is created. Already completed todos are left unchanged.
+func (r Repository) CompleteTodo(
+	ctx context.Context,
+	id uuid.UUID,
+	completedAt time.Time,
+) (completedTodo todos.Todo, err error) {
+	err = pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		completedTodo, err = completeTodo(ctx, tx, id, completedAt)
+		return err
+	})
+	if err != nil {
+		return todos.Todo{}, fmt.Errorf("failed completing todo: %w", err)
+	}
+
+	return completedTodo, nil
+}
+
+// ReopenTodo marks todo as not completed.
+// Todos which are not completed are left unchanged.
+func (r Repository) ReopenTodo(
+	ctx context.Context,
+	id uuid.UUID,
+	reopenedAt time.Time,
+) (reopenedTodo todos.Todo, err error) {
+	err = pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		previousTodo, err := lockTodo(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if previousTodo.CompletedAt == nil {
+			reopenedTodo = previousTodo
+			return nil
+		}
+
+		rows, err := tx.Query(ctx,
+			`
+			UPDATE todos
+			SET completed_at = NULL, updated_at = $2, version = version + 1
+			WHERE id=$1
+			RETURNING `+postgresTodoColumns,
+			id,
+			reopenedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed querying database: %w", err)
+		}
+
+		reopenedTodo, err = collectChangedTodo(rows)
+		if err != nil {
+			return err
+		}
+
+		event := newEvent(ctx, todos.EventReopened, &previousTodo, &reopenedTodo, reopenedAt)
+
+		return recordChange(ctx, tx, event, previousTodo, reopenedTodo)
+	})
+	if err != nil {
+		return todos.Todo{}, fmt.Errorf("failed reopening todo: %w", err)
+	}
+
+	return reopenedTodo, nil
+}
+
+// RestoreTodo brings back deleted todo. The todo is removed from its list or parent if they were deleted.
+// Subtasks deleted together with the todo are not restored. Todos which are not deleted are reported as not found.
+// The todo keeps its position unless another todo took it meanwhile, in which case it follows all todos.
+func (r Repository) RestoreTodo(
+	ctx context.Context,
+	id uuid.UUID,
+	restoredAt time.Time,
+) (restoredTodo todos.Todo, err error) {
+	err = pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		previousTodo, err := lockDeletedTodo(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		position, err := restoredPosition(ctx, tx, previousTodo, restoredAt)
+		if err != nil {
+			return err
+		}
+
+		rows, err := tx.Query(ctx,
+			`
+			UPDATE todos
+			SET deleted_at = NULL, updated_at = $2, version = version + 1, position = $3,
+				list_id = (SELECT id FROM lists WHERE id = todos.list_id AND deleted_at IS NULL),
+				parent_id = (
+					SELECT parents.id FROM todos AS parents WHERE parents.id = todos.parent_id AND parents.deleted_at IS NULL
+				)
+			WHERE id=$1
+			RETURNING `+postgresTodoColumns,
+			id,
+			restoredAt,
+			position,
+		)
+		if err != nil {
+			return fmt.Errorf("failed querying database: %w", err)
+		}
+
+		restoredTodo, err = collectChangedTodo(rows)
+		if err != nil {
+			return err
+		}
+
+		event := newEvent(ctx, todos.EventRestored, &previousTodo, &restoredTodo, restoredAt)
+
+		return recordChange(ctx, tx, event, previousTodo, restoredTodo)
+	})
+	if err != nil {
+		return todos.Todo{}, fmt.Errorf("failed restoring todo: %w", err)
+	}
+
+	return restoredTodo, nil
 }
 
-func (r Repository) DeleteTodo(ctx context.Context, id uuid.UUID, deletedAt time.Time) error {
-	c, err := r.pool.Exec(ctx,
+// DeleteTodo marks the todo and all of its subtasks as deleted. If the version is non-zero,
+// the deletion only succeeds if the stored todo still has the same version.
+func (r Repository) DeleteTodo(ctx context.Context, id uuid.UUID, version int, deletedAt time.Time) error {
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		return deleteTodo(ctx, tx, id, version, deletedAt)
+	})
+	if err != nil {
+		return fmt.Errorf("failed deleting todo: %w", err)
+	}
+
+	return nil
+}
+
+func deleteTodo(ctx context.Context, q querier, id uuid.UUID, version int, deletedAt time.Time) error {
+	previousTodo, err := lockTodo(ctx, q, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := q.Query(ctx,
 		`
 		UPDATE todos
-		SET deleted_at=$2
-		WHERE id=$1
-		`,
+		SET deleted_at = $2, version = version + 1
+		WHERE id=$1 AND deleted_at IS NULL AND ($3 = 0 OR version = $3)
+		RETURNING `+postgresTodoColumns,
 		id,
 		deletedAt,
+		version,
 	)
+	if err != nil {
+		return fmt.Errorf("failed querying database: %w", err)
+	}
+
+	deletedTodo, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[todos.Todo])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return missingTodoError(ctx, q, id, version)
+	}
+
 	if err != nil {
 		return fmt.Errorf("%w: %w", ErrDatabase, err)
 	}
 
-	if c.RowsAffected() == 0 {
-		return ErrTodoNotFound
+	event := newEvent(ctx, todos.EventDeleted, &previousTodo, nil, deletedAt)
+
+	err = recordChange(ctx, q, event, previousTodo, deletedTodo)
+	if err != nil {
+		return err
+	}
+
+	return deleteSubtasks(ctx, q, id, deletedAt)
+}
+
+// deleteTodos marks the todos deleted together with their parent or list as deleted.
+// The todos are in the state before the deletion which is recorded for each of them.
+func deleteTodos(ctx context.Context, q querier, previousTodos []todos.Todo, deletedAt time.Time) error {
+	for _, previousTodo := range previousTodos {
+		rows, err := q.Query(ctx,
+			`
+			UPDATE todos
+			SET deleted_at = $2, version = version + 1
+			WHERE id=$1
+			RETURNING `+postgresTodoColumns,
+			previousTodo.ID,
+			deletedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed querying database: %w", err)
+		}
+
+		deletedTodo, err := collectChangedTodo(rows)
+		if err != nil {
+			return err
+		}
+
+		event := newEvent(ctx, todos.EventDeleted, &previousTodo, nil, deletedAt)
+
+		err = recordChange(ctx, q, event, previousTodo, deletedTodo)
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
+
+// PurgeTodos permanently removes at most limit todos deleted before the given time
+// together with their comments and attachments. It returns the number of removed todos
+// and IDs of the removed attachments whose contents are left in the blob store.
+func (r Repository) PurgeTodos(
+	ctx context.Context,
+	deletedBefore time.Time,
+	limit int,
+) (purged int64, attachments []uuid.UUID, err error) {
+	// Attachments are selected from the snapshot before the purged todos and their attachments are removed.
+	err = r.pool.QueryRow(ctx,
+		`
+		WITH purged AS (
+			DELETE FROM todos
+			WHERE id IN (
+				SELECT id
+				FROM todos
+				WHERE deleted_at < $1
+				ORDER BY deleted_at
+				LIMIT $2
+			)
+			RETURNING id
+		)
+		SELECT
+			(SELECT count(*) FROM purged),
+			ARRAY(SELECT attachments.id FROM attachments JOIN purged ON attachments.todo_id = purged.id)
+		`,
+		deletedBefore,
+		limit,
+	).Scan(&purged, &attachments)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return purged, attachments, nil
+}
+
+// collectChangedTodo returns the todo changed by the query or [ErrTodoNotFound] if there is none.
+func collectChangedTodo(rows pgx.Rows) (todos.Todo, error) {
+	todo, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[todos.Todo])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return todos.Todo{}, ErrTodoNotFound
+	}
+
+	if err != nil {
+		return todos.Todo{}, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return todo, nil
+}
+
+// missingTodoError finds out why conditional change of todo did not affect any rows.
+func missingTodoError(ctx context.Context, q querier, id uuid.UUID, version int) error {
+	if version == 0 {
+		return ErrTodoNotFound
+	}
+
+	var exists bool
+
+	err := q.QueryRow(ctx,
+		`
+		SELECT EXISTS (SELECT 1 FROM todos WHERE id=$1 AND deleted_at IS NULL)
+		`,
+		id,
+	).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	if !exists {
+		return ErrTodoNotFound
+	}
+
+	return ErrVersionConflict
+}
@@ -2,53 +2,88 @@ package repository_test
 
 import (
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/course-go/todos/internal/actor"
+	"github.com/course-go/todos/internal/auth"
 	"github.com/course-go/todos/internal/repository"
 	"github.com/course-go/todos/internal/todos"
 	"github.com/course-go/todos/internal/utils/test"
+	"github.com/google/go-cmp/cmp"
 	"github.com/google/uuid"
 	"github.com/testcontainers/testcontainers-go/modules/postgres"
 )
 
-func TestRepository(t *testing.T) { //nolint: gocognit, cyclop, maintidx, tparallel
+func TestRepository(t *testing.T) {
 	t.Parallel()
 
 	ctx := t.Context()
-	c := test.NewTestContainer(ctx, t)
-	t.Cleanup(func() {
-		err := c.Terminate(ctx)
+	logger := test.NewTestLogger(t)
+
+	t.Run("Postgres", func(t *testing.T) {
+		t.Parallel()
+
+		c := test.NewTestContainer(ctx, t)
+		t.Cleanup(func() {
+			err := c.Terminate(ctx)
+			if err != nil {
+				t.Logf("failed terminating postgres container: %v", err)
+			}
+		})
+		cfg := test.NewTestDatabaseConfig(ctx, t, c)
+
+		err := repository.Migrate(cfg, logger)
+		if err != nil {
+			t.Fatalf("failed migrating database: %v", err)
+		}
+
+		test.SeedDatabase(ctx, t, c)
+
+		err = c.Snapshot(ctx, postgres.WithSnapshotName("test-todos"))
 		if err != nil {
-			t.Logf("failed terminating postgres container: %v", err)
+			t.Fatalf("failed creating database snapshot: %v", err)
 		}
+
+		testRepository(t, func(t *testing.T) repository.TodoRepository {
+			t.Helper()
+			t.Cleanup(func() {
+				test.RestoreDatabase(ctx, t, c)
+			})
+
+			return test.NewTestRepository(ctx, t, logger, cfg)
+		})
 	})
-	cfg := test.NewTestDatabaseConfig(ctx, t, c)
-	logger := test.NewTestLogger(t)
 
-	err := repository.Migrate(cfg, logger)
-	if err != nil {
-		t.Fatalf("failed migrating database: %v", err)
-	}
+	t.Run("SQLite", func(t *testing.T) {
+		t.Parallel()
 
-	test.SeedDatabase(ctx, t, c)
+		testRepository(t, func(t *testing.T) repository.TodoRepository {
+			t.Helper()
 
-	err = c.Snapshot(ctx, postgres.WithSnapshotName("test-todos"))
-	if err != nil {
-		t.Fatalf("failed creating database snapshot: %v", err)
-	}
+			return test.NewTestSQLiteRepository(ctx, t, logger)
+		})
+	})
+}
+
+// testRepository runs the same tests against every backend.
+// The newRepository creates repository seeded with testdata for a single test.
+func testRepository( //nolint: gocognit, gocyclo, cyclop, maintidx
+	t *testing.T,
+	newRepository func(t *testing.T) repository.TodoRepository,
+) {
+	t.Helper()
+
+	ctx := t.Context()
 
 	now, err := time.Parse(time.RFC3339Nano, "2024-08-18T14:14:45.847679Z")
 	if err != nil {
 		t.Fatalf("could not parse time: %v", err)
 	}
 
-	t.Run("Create todo", func(t *testing.T) { //nolint: paralleltest
-		t.Cleanup(func() {
-			test.RestoreDatabase(ctx, t, c)
-		})
-
-		r := test.NewTestRepository(ctx, t, logger, cfg)
+	t.Run("Create todo", func(t *testing.T) {
+		r := newRepository(t)
 		todo := todos.Todo{
 			Description: "Mop the floor",
 			CreatedAt:   now,
@@ -72,12 +107,8 @@ func TestRepository(t *testing.T) { //nolint: gocognit, cyclop, maintidx, tparal
 		}
 	})
 
-	t.Run("Get existing todo", func(t *testing.T) { //nolint: paralleltest
-		t.Cleanup(func() {
-			test.RestoreDatabase(ctx, t, c)
-		})
-
-		r := test.NewTestRepository(ctx, t, logger, cfg)
+	t.Run("Get existing todo", func(t *testing.T) {
+		r := newRepository(t)
 
 		id, err := uuid.Parse("f52bad23-c201-414e-9bdb-af4327c42aa7")
 		if err != nil {
@@ -98,12 +129,8 @@ func TestRepository(t *testing.T) { //nolint: gocognit, cyclop, maintidx, tparal
 		}
 	})
 
-	t.Run("Get non-existing todo", func(t *testing.T) { //nolint: paralleltest
-		t.Cleanup(func() {
-			test.RestoreDatabase(ctx, t, c)
-		})
-
-		r := test.NewTestRepository(ctx, t, logger, cfg)
+	t.Run("Get non-existing todo", func(t *testing.T) {
+		r := newRepository(t)
 
 		id, err := uuid.Parse("be95c29a-c4dd-4d31-a5c4-d229f3374ab7")
 		if err != nil {
@@ -116,14 +143,10 @@ func TestRepository(t *testing.T) { //nolint: gocognit, cyclop, maintidx, tparal
 		}
 	})
 
-	t.Run("Get todos", func(t *testing.T) { //nolint: paralleltest
-		t.Cleanup(func() {
-			test.RestoreDatabase(ctx, t, c)
-		})
-
-		r := test.NewTestRepository(ctx, t, logger, cfg)
+	t.Run("Get todos", func(t *testing.T) {
+		r := newRepository(t)
 
-		todos, err := r.GetTodos(ctx)
+		todos, next, err := r.GetTodos(ctx, repository.TodosQuery{})
 		if err != nil {
 			t.Fatalf("could not get todos: %v", err)
 		}
@@ -135,26 +158,193 @@ func TestRepository(t *testing.T) { //nolint: gocognit, cyclop, maintidx, tparal
 				len(todos),
 			)
 		}
+
+		if next != nil {
+			t.Fatalf("there should be no next page: expected: nil != actual: %v", next)
+		}
 	})
 
-	t.Run("Save existing todo", func(t *testing.T) { //nolint: paralleltest
-		t.Cleanup(func() {
-			test.RestoreDatabase(ctx, t, c)
+	t.Run("Get todos page by page", func(t *testing.T) {
+		r := newRepository(t)
+
+		firstPage, next, err := r.GetTodos(ctx, repository.TodosQuery{Limit: 1})
+		if err != nil {
+			t.Fatalf("could not get first page of todos: %v", err)
+		}
+
+		if len(firstPage) != 1 || next == nil {
+			t.Fatalf("first page should contain single todo and cursor: actual: %d todos, cursor %v",
+				len(firstPage),
+				next,
+			)
+		}
+
+		secondPage, next, err := r.GetTodos(ctx, repository.TodosQuery{Limit: 1, Cursor: next})
+		if err != nil {
+			t.Fatalf("could not get second page of todos: %v", err)
+		}
+
+		if len(secondPage) != 1 || next != nil {
+			t.Fatalf("second page should contain single todo and no cursor: actual: %d todos, cursor %v",
+				len(secondPage),
+				next,
+			)
+		}
+
+		if firstPage[0].ID == secondPage[0].ID {
+			t.Fatalf("pages should not contain the same todo: %s", firstPage[0].ID)
+		}
+	})
+
+	t.Run("Get filtered todos", func(t *testing.T) {
+		r := newRepository(t)
+
+		completed := true
+		createdAfter := time.Date(2024, time.July, 26, 0, 0, 0, 0, time.UTC)
+		query := repository.TodosQuery{
+			Filter: repository.TodosFilter{
+				Completed:    &completed,
+				CreatedAfter: &createdAfter,
+				Text:         "vac",
+			},
+		}
+
+		todos, _, err := r.GetTodos(ctx, query)
+		if err != nil {
+			t.Fatalf("could not get todos: %v", err)
+		}
+
+		if len(todos) != 1 || todos[0].ID.String() != "f52bad23-c201-414e-9bdb-af4327c42aa7" {
+			t.Fatalf("filtered todos do not match: expected: [Vacuum] != actual: %v", todos)
+		}
+	})
+
+	t.Run("Get todos by priority", func(t *testing.T) {
+		r := newRepository(t)
+
+		_, err := r.CreateTodo(ctx, todos.Todo{
+			Description: "Water the plants",
+			Priority:    todos.PriorityUrgent,
+			CreatedAt:   now,
 		})
+		if err != nil {
+			t.Fatalf("could not create todo: %v", err)
+		}
+
+		sort, err := repository.ParseSort("-priority")
+		if err != nil {
+			t.Fatalf("could not parse sort: %v", err)
+		}
 
-		r := test.NewTestRepository(ctx, t, logger, cfg)
+		query := repository.TodosQuery{
+			Filter: repository.TodosFilter{
+				Priorities: []todos.Priority{todos.PriorityHigh, todos.PriorityUrgent},
+			},
+			Sort: sort,
+		}
 
-		id, err := uuid.Parse("62446c85-3798-471f-abb8-75c1cdd7153b")
+		retrievedTodos, _, err := r.GetTodos(ctx, query)
 		if err != nil {
-			t.Fatalf("could not parse uuid: %v", err)
+			t.Fatalf("could not get todos: %v", err)
 		}
 
-		todo, err := r.GetTodo(ctx, id)
+		var descriptions []string
+		for _, todo := range retrievedTodos {
+			descriptions = append(descriptions, todo.Description)
+		}
+
+		expectedDescriptions := []string{"Water the plants", "Vacuum"}
+		if !cmp.Equal(expectedDescriptions, descriptions) {
+			t.Fatalf("todos do not match: %s", cmp.Diff(expectedDescriptions, descriptions))
+		}
+	})
+
+	t.Run("Get overdue todos", func(t *testing.T) {
+		r := newRepository(t)
+
+		overdue := true
+		dueAfter := time.Date(2024, time.July, 30, 0, 0, 0, 0, time.UTC)
+		query := repository.TodosQuery{
+			Filter: repository.TodosFilter{
+				Overdue:  &overdue,
+				Now:      now,
+				DueAfter: &dueAfter,
+			},
+		}
+
+		retrievedTodos, _, err := r.GetTodos(ctx, query)
+		if err != nil {
+			t.Fatalf("could not get todos: %v", err)
+		}
+
+		if len(retrievedTodos) != 1 || retrievedTodos[0].ID.String() != "62446c85-3798-471f-abb8-75c1cdd7153b" {
+			t.Fatalf("overdue todos do not match: expected: [Mop the floor] != actual: %v", retrievedTodos)
+		}
+	})
+
+	t.Run("Get todos by tags", func(t *testing.T) {
+		r := newRepository(t)
+
+		createdTodo, err := r.CreateTodo(ctx, todos.Todo{
+			Description: "Write report",
+			Tags:        []string{"work", "urgent", "work"},
+			CreatedAt:   now,
+		})
+		if err != nil {
+			t.Fatalf("could not create todo: %v", err)
+		}
+
+		expectedTags := []string{"urgent", "work"}
+		if !cmp.Equal(expectedTags, createdTodo.Tags) {
+			t.Fatalf("tags do not match: %s", cmp.Diff(expectedTags, createdTodo.Tags))
+		}
+
+		filters := map[string]struct {
+			filter               repository.TodosFilter
+			expectedDescriptions []string
+		}{
+			"any": {
+				filter:               repository.TodosFilter{Tags: []string{"urgent", "work"}},
+				expectedDescriptions: []string{"Vacuum", "Write report"},
+			},
+			"all": {
+				filter:               repository.TodosFilter{Tags: []string{"home", "urgent"}, AllTags: true},
+				expectedDescriptions: []string{"Vacuum"},
+			},
+		}
+		for name, filter := range filters {
+			retrievedTodos, _, err := r.GetTodos(ctx, repository.TodosQuery{
+				Filter: filter.filter,
+				Sort:   []repository.Sort{{Field: repository.SortByDescription}},
+			})
+			if err != nil {
+				t.Fatalf("could not get todos: %v", err)
+			}
+
+			var descriptions []string
+			for _, todo := range retrievedTodos {
+				descriptions = append(descriptions, todo.Description)
+			}
+
+			if !cmp.Equal(filter.expectedDescriptions, descriptions) {
+				t.Fatalf(
+					"todos with %s tags do not match: %s",
+					name,
+					cmp.Diff(filter.expectedDescriptions, descriptions),
+				)
+			}
+		}
+	})
+
+	t.Run("Save todo with new tag", func(t *testing.T) {
+		r := newRepository(t)
+
+		todo, err := r.GetTodo(ctx, uuid.MustParse("62446c85-3798-471f-abb8-75c1cdd7153b"))
 		if err != nil {
 			t.Fatalf("could not get todo: %v", err)
 		}
 
-		todo.CompletedAt = &now
+		todo.Tags = []string{"garden"}
 		todo.UpdatedAt = &now
 
 		savedTodo, err := r.SaveTodo(ctx, todo)
@@ -162,78 +352,1647 @@ func TestRepository(t *testing.T) { //nolint: gocognit, cyclop, maintidx, tparal
 			t.Fatalf("could not save todo: %v", err)
 		}
 
-		if savedTodo.UpdatedAt == nil {
-			t.Fatalf("todo updated timestamp was not changed")
+		if !cmp.Equal([]string{"garden"}, savedTodo.Tags) {
+			t.Fatalf("tags do not match: expected: [garden] != actual: %v", savedTodo.Tags)
 		}
 
-		nowRounded := now.Round(time.Millisecond)
+		tags, err := r.GetTags(ctx)
+		if err != nil {
+			t.Fatalf("could not get tags: %v", err)
+		}
 
-		todoRounded := savedTodo.UpdatedAt.Round(time.Millisecond)
-		if !todoRounded.Equal(nowRounded) {
-			t.Fatalf("todo completed timestamp does not match: expected: %s != actual: %s",
-				nowRounded,
-				todoRounded,
+		var names []string
+		for _, tag := range tags {
+			names = append(names, tag.Name)
+		}
+
+		expectedNames := []string{"garden", "home", "urgent", "work"}
+		if !cmp.Equal(expectedNames, names) {
+			t.Fatalf("tags do not match: %s", cmp.Diff(expectedNames, names))
+		}
+	})
+
+	t.Run("Create existing tag", func(t *testing.T) {
+		r := newRepository(t)
+
+		_, err := r.CreateTag(ctx, todos.Tag{Name: "home"})
+		if !errors.Is(err, repository.ErrTagExists) {
+			t.Fatalf("tag should already exist: expected: %v != actual: %v", repository.ErrTagExists, err)
+		}
+	})
+
+	t.Run("Rename tag", func(t *testing.T) {
+		r := newRepository(t)
+
+		homeID := uuid.MustParse("c5f3a5e2-1d4b-4c8e-9a51-7f2b8d3e6a10")
+		vacuumID := uuid.MustParse("f52bad23-c201-414e-9bdb-af4327c42aa7")
+
+		previousTodo, err := r.GetTodo(ctx, vacuumID)
+		if err != nil {
+			t.Fatalf("could not get todo: %v", err)
+		}
+
+		_, err = r.SaveTag(ctx, todos.Tag{ID: homeID, Name: "work"}, now)
+		if !errors.Is(err, repository.ErrTagExists) {
+			t.Fatalf("tag name should be taken: expected: %v != actual: %v", repository.ErrTagExists, err)
+		}
+
+		_, err = r.SaveTag(ctx, todos.Tag{ID: homeID, Name: "household"}, now)
+		if err != nil {
+			t.Fatalf("could not save tag: %v", err)
+		}
+
+		todo, err := r.GetTodo(ctx, vacuumID)
+		if err != nil {
+			t.Fatalf("could not get todo: %v", err)
+		}
+
+		expectedTags := []string{"household", "urgent"}
+		if !cmp.Equal(expectedTags, todo.Tags) {
+			t.Fatalf("tags do not match: %s", cmp.Diff(expectedTags, todo.Tags))
+		}
+
+		if todo.Version != previousTodo.Version+1 || todo.UpdatedAt == nil || !todo.UpdatedAt.Equal(now) {
+			t.Fatalf("todo should be changed: expected: version %d updated at %s != actual: version %d updated at %v",
+				previousTodo.Version+1,
+				now,
+				todo.Version,
+				todo.UpdatedAt,
 			)
 		}
+
+		events, err := r.GetTodoEvents(ctx, vacuumID)
+		if err != nil {
+			t.Fatalf("could not get todo events: %v", err)
+		}
+
+		last := events[len(events)-1]
+		if last.Type != todos.EventUpdated || last.Before == nil || last.After == nil ||
+			!cmp.Equal(previousTodo.Tags, last.Before.Tags) || !cmp.Equal(expectedTags, last.After.Tags) {
+			t.Fatalf("renaming of tag should be recorded: %+v", last)
+		}
+
+		versions, err := r.GetTodoVersions(ctx, vacuumID)
+		if err != nil {
+			t.Fatalf("could not get todo versions: %v", err)
+		}
+
+		expectedNumbers := []int{previousTodo.Version, todo.Version}
+		if len(versions) != len(expectedNumbers) ||
+			versions[0].Number != expectedNumbers[0] || versions[1].Number != expectedNumbers[1] ||
+			!cmp.Equal(previousTodo.Tags, versions[0].Todo.Tags) {
+			t.Fatalf("todo before renaming of tag should be recorded: %+v", versions)
+		}
 	})
 
-	t.Run("Save non-existing todo", func(t *testing.T) { //nolint: paralleltest
-		t.Cleanup(func() {
-			test.RestoreDatabase(ctx, t, c)
-		})
+	t.Run("Delete tag", func(t *testing.T) {
+		r := newRepository(t)
+
+		err := r.DeleteTag(ctx, uuid.MustParse("c5f3a5e2-1d4b-4c8e-9a51-7f2b8d3e6a10"), now)
+		if err != nil {
+			t.Fatalf("could not delete tag: %v", err)
+		}
+
+		todo, err := r.GetTodo(ctx, uuid.MustParse("f52bad23-c201-414e-9bdb-af4327c42aa7"))
+		if err != nil {
+			t.Fatalf("could not get todo: %v", err)
+		}
 
-		r := test.NewTestRepository(ctx, t, logger, cfg)
+		if !cmp.Equal([]string{"urgent"}, todo.Tags) {
+			t.Fatalf("tags do not match: expected: [urgent] != actual: %v", todo.Tags)
+		}
 
-		id, err := uuid.Parse("ac4011ce-59c9-4361-8abf-10abd273d5e5")
+		if todo.UpdatedAt == nil || !todo.UpdatedAt.Equal(now) {
+			t.Fatalf("todo updated at does not match: expected: %s != actual: %v", now, todo.UpdatedAt)
+		}
+
+		events, err := r.GetTodoEvents(ctx, todo.ID)
 		if err != nil {
-			t.Fatalf("could not parse uuid: %v", err)
+			t.Fatalf("could not get todo events: %v", err)
 		}
 
-		todo := todos.Todo{
-			ID:          id,
-			Description: "Do some shopping",
-			CompletedAt: nil,
+		last := events[len(events)-1]
+		if last.Type != todos.EventUpdated || last.After == nil || !cmp.Equal(todo.Tags, last.After.Tags) {
+			t.Fatalf("deletion of tag should be recorded: %+v", last)
 		}
 
-		_, err = r.SaveTodo(ctx, todo)
-		if !errors.Is(err, repository.ErrTodoNotFound) {
-			t.Fatalf("todo should not be found: expected: %v != actual: %v", repository.ErrTodoNotFound, err)
+		err = r.DeleteTag(ctx, uuid.MustParse("c5f3a5e2-1d4b-4c8e-9a51-7f2b8d3e6a10"), now)
+		if !errors.Is(err, repository.ErrTagNotFound) {
+			t.Fatalf("tag should not be found: expected: %v != actual: %v", repository.ErrTagNotFound, err)
 		}
 	})
 
-	t.Run("Delete existing todo", func(t *testing.T) { //nolint: paralleltest
-		t.Cleanup(func() {
-			test.RestoreDatabase(ctx, t, c)
-		})
+	t.Run("Create todo in non-existing list", func(t *testing.T) {
+		r := newRepository(t)
 
-		r := test.NewTestRepository(ctx, t, logger, cfg)
+		listID := uuid.MustParse("be95c29a-c4dd-4d31-a5c4-d229f3374ab7")
 
-		id, err := uuid.Parse("f52bad23-c201-414e-9bdb-af4327c42aa7")
+		_, err := r.CreateTodo(ctx, todos.Todo{Description: "Water the plants", ListID: &listID, CreatedAt: now})
+		if !errors.Is(err, repository.ErrListNotFound) {
+			t.Fatalf("list should not be found: expected: %v != actual: %v", repository.ErrListNotFound, err)
+		}
+	})
+
+	t.Run("Save list", func(t *testing.T) {
+		r := newRepository(t)
+
+		list, err := r.CreateList(ctx, todos.List{Name: "Garden", CreatedAt: now})
 		if err != nil {
-			t.Fatalf("could not parse uuid: %v", err)
+			t.Fatalf("could not create list: %v", err)
 		}
 
-		err = r.DeleteTodo(ctx, id, now)
+		list.Name = "Backyard"
+		list.Color = "#228b22"
+		list.Archived = true
+		list.UpdatedAt = &now
+
+		_, err = r.SaveList(ctx, list)
 		if err != nil {
-			t.Fatalf("todo should be deleted: expected: nil != actual: %v", err)
+			t.Fatalf("could not save list: %v", err)
+		}
+
+		lists, err := r.GetLists(ctx)
+		if err != nil {
+			t.Fatalf("could not get lists: %v", err)
+		}
+
+		expectedLists := []todos.List{list}
+		if !cmp.Equal(expectedLists, lists) {
+			t.Fatalf("lists do not match: %s", cmp.Diff(expectedLists, lists))
 		}
 	})
 
-	t.Run("Delete non-existing todo", func(t *testing.T) { //nolint: paralleltest
-		t.Cleanup(func() {
-			test.RestoreDatabase(ctx, t, c)
-		})
+	t.Run("Delete list with todos", func(t *testing.T) {
+		r := newRepository(t)
 
-		r := test.NewTestRepository(ctx, t, logger, cfg)
+		list, err := r.CreateList(ctx, todos.List{Name: "Garden", CreatedAt: now})
+		if err != nil {
+			t.Fatalf("could not create list: %v", err)
+		}
 
-		id, err := uuid.Parse("4fabcaa9-7fe6-4129-86f2-1d62d142a67b")
+		todo, err := r.CreateTodo(ctx, todos.Todo{Description: "Water the plants", ListID: &list.ID, CreatedAt: now})
 		if err != nil {
-			t.Fatalf("could not parse uuid: %v", err)
+			t.Fatalf("could not create todo: %v", err)
+		}
+
+		listTodos, _, err := r.GetTodos(ctx, repository.TodosQuery{Filter: repository.TodosFilter{ListID: &list.ID}})
+		if err != nil {
+			t.Fatalf("could not get list todos: %v", err)
+		}
+
+		if len(listTodos) != 1 || listTodos[0].ID != todo.ID {
+			t.Fatalf("list todos do not match: expected: [%s] != actual: %v", todo.ID, listTodos)
+		}
+
+		err = r.DeleteList(ctx, list.ID, now)
+		if err != nil {
+			t.Fatalf("could not delete list: %v", err)
+		}
+
+		_, err = r.GetList(ctx, list.ID)
+		if !errors.Is(err, repository.ErrListNotFound) {
+			t.Fatalf("list should not be found: expected: %v != actual: %v", repository.ErrListNotFound, err)
 		}
 
-		err = r.DeleteTodo(ctx, id, now)
+		_, err = r.GetTodo(ctx, todo.ID)
 		if !errors.Is(err, repository.ErrTodoNotFound) {
-			t.Fatalf("todo should not be found: expected: %v != actual: %v", repository.ErrTodoNotFound, err)
+			t.Fatalf("todo should be deleted: expected: %v != actual: %v", repository.ErrTodoNotFound, err)
+		}
+
+		restoredTodo, err := r.RestoreTodo(ctx, todo.ID, now)
+		if err != nil {
+			t.Fatalf("could not restore todo: %v", err)
+		}
+
+		if restoredTodo.ListID != nil {
+			t.Fatalf("restored todo should not be in list: expected: nil != actual: %s", restoredTodo.ListID)
+		}
+
+		err = r.DeleteList(ctx, list.ID, now)
+		if !errors.Is(err, repository.ErrListNotFound) {
+			t.Fatalf("list should not be found: expected: %v != actual: %v", repository.ErrListNotFound, err)
+		}
+	})
+
+	t.Run("Create subtasks", func(t *testing.T) {
+		r := newRepository(t)
+
+		parentID := uuid.MustParse("62446c85-3798-471f-abb8-75c1cdd7153b")
+
+		var subtaskIDs []uuid.UUID
+
+		for _, description := range []string{"Buy detergent", "Load the washer"} {
+			subtask, err := r.CreateTodo(ctx, todos.Todo{Description: description, ParentID: &parentID, CreatedAt: now})
+			if err != nil {
+				t.Fatalf("could not create subtask: %v", err)
+			}
+
+			subtaskIDs = append(subtaskIDs, subtask.ID)
+		}
+
+		_, err := r.CompleteTodo(ctx, subtaskIDs[1], now)
+		if err != nil {
+			t.Fatalf("could not complete subtask: %v", err)
+		}
+
+		parent, err := r.GetTodo(ctx, parentID)
+		if err != nil {
+			t.Fatalf("could not get parent todo: %v", err)
+		}
+
+		expectedProgress := &todos.Progress{Completed: 1, Total: 2}
+		if !cmp.Equal(expectedProgress, parent.Progress) {
+			t.Fatalf("progress does not match: %s", cmp.Diff(expectedProgress, parent.Progress))
+		}
+
+		subtasks, err := r.GetSubtasks(ctx, parentID)
+		if err != nil {
+			t.Fatalf("could not get subtasks: %v", err)
+		}
+
+		var ids []uuid.UUID
+		for _, subtask := range subtasks {
+			ids = append(ids, subtask.ID)
+		}
+
+		if !cmp.Equal(subtaskIDs, ids) {
+			t.Fatalf("subtasks do not match: %s", cmp.Diff(subtaskIDs, ids))
+		}
+
+		missingID := uuid.MustParse("be95c29a-c4dd-4d31-a5c4-d229f3374ab7")
+
+		_, err = r.CreateTodo(ctx, todos.Todo{Description: "Dry the clothes", ParentID: &missingID, CreatedAt: now})
+		if !errors.Is(err, repository.ErrParentNotFound) {
+			t.Fatalf("parent should not be found: expected: %v != actual: %v", repository.ErrParentNotFound, err)
+		}
+	})
+
+	t.Run("Reorder subtasks", func(t *testing.T) {
+		r := newRepository(t)
+
+		parentID := uuid.MustParse("62446c85-3798-471f-abb8-75c1cdd7153b")
+
+		var order []uuid.UUID
+
+		for _, description := range []string{"Buy detergent", "Load the washer", "Dry the clothes"} {
+			subtask, err := r.CreateTodo(ctx, todos.Todo{Description: description, ParentID: &parentID, CreatedAt: now})
+			if err != nil {
+				t.Fatalf("could not create subtask: %v", err)
+			}
+
+			order = append([]uuid.UUID{subtask.ID}, order...)
+		}
+
+		_, err := r.ReorderSubtasks(ctx, parentID, order[1:], now)
+		if !errors.Is(err, repository.ErrSubtasksMismatch) {
+			t.Fatalf("subtasks should not match: expected: %v != actual: %v", repository.ErrSubtasksMismatch, err)
+		}
+
+		parent, err := r.GetTodo(ctx, parentID)
+		if err != nil {
+			t.Fatalf("could not get todo: %v", err)
+		}
+
+		reorderedAt := now.Add(time.Minute)
+
+		subtasks, err := r.ReorderSubtasks(ctx, parentID, order, reorderedAt)
+		if err != nil {
+			t.Fatalf("could not reorder subtasks: %v", err)
+		}
+
+		var ids []uuid.UUID
+		for _, subtask := range subtasks {
+			ids = append(ids, subtask.ID)
+
+			if subtask.Version != 2 || subtask.UpdatedAt == nil || !subtask.UpdatedAt.Equal(reorderedAt) {
+				t.Fatalf(
+					"reordered subtask should be changed: version %d, updated at %v",
+					subtask.Version,
+					subtask.UpdatedAt,
+				)
+			}
+		}
+
+		if !cmp.Equal(order, ids) {
+			t.Fatalf("subtasks do not match: %s", cmp.Diff(order, ids))
+		}
+
+		reorderedParent, err := r.GetTodo(ctx, parentID)
+		if err != nil {
+			t.Fatalf("could not get todo: %v", err)
+		}
+
+		if reorderedParent.Version != parent.Version+1 || !reorderedParent.UpdatedAt.Equal(reorderedAt) {
+			t.Fatalf("todo with reordered subtasks should be changed: version %d, updated at %v",
+				reorderedParent.Version,
+				reorderedParent.UpdatedAt,
+			)
+		}
+	})
+
+	t.Run("Re-parent todo to its subtask", func(t *testing.T) {
+		r := newRepository(t)
+
+		parent, err := r.GetTodo(ctx, uuid.MustParse("62446c85-3798-471f-abb8-75c1cdd7153b"))
+		if err != nil {
+			t.Fatalf("could not get todo: %v", err)
+		}
+
+		subtask, err := r.CreateTodo(
+			ctx,
+			todos.Todo{Description: "Buy detergent", ParentID: &parent.ID, CreatedAt: now},
+		)
+		if err != nil {
+			t.Fatalf("could not create subtask: %v", err)
+		}
+
+		nested, err := r.CreateTodo(ctx, todos.Todo{Description: "Go to shop", ParentID: &subtask.ID, CreatedAt: now})
+		if err != nil {
+			t.Fatalf("could not create subtask: %v", err)
+		}
+
+		for _, parentID := range []uuid.UUID{parent.ID, nested.ID} {
+			parent.ParentID = &parentID
+			parent.UpdatedAt = &now
+
+			_, err = r.SaveTodo(ctx, parent)
+			if !errors.Is(err, repository.ErrParentCycle) {
+				t.Fatalf("todo should not be re-parented: expected: %v != actual: %v", repository.ErrParentCycle, err)
+			}
+		}
+
+		nested.ParentID = &parent.ID
+		nested.UpdatedAt = &now
+
+		savedTodo, err := r.SaveTodo(ctx, nested)
+		if err != nil {
+			t.Fatalf("could not re-parent todo: %v", err)
+		}
+
+		if savedTodo.ParentID == nil || *savedTodo.ParentID != parent.ID {
+			t.Fatalf("parent does not match: expected: %s != actual: %v", parent.ID, savedTodo.ParentID)
+		}
+	})
+
+	t.Run("Delete todo with subtasks", func(t *testing.T) {
+		r := newRepository(t)
+
+		parentID := uuid.MustParse("62446c85-3798-471f-abb8-75c1cdd7153b")
+
+		subtask, err := r.CreateTodo(ctx, todos.Todo{Description: "Buy detergent", ParentID: &parentID, CreatedAt: now})
+		if err != nil {
+			t.Fatalf("could not create subtask: %v", err)
+		}
+
+		err = r.DeleteTodo(ctx, parentID, 0, now)
+		if err != nil {
+			t.Fatalf("could not delete todo: %v", err)
+		}
+
+		_, err = r.GetTodo(ctx, subtask.ID)
+		if !errors.Is(err, repository.ErrTodoNotFound) {
+			t.Fatalf("subtask should be deleted: expected: %v != actual: %v", repository.ErrTodoNotFound, err)
+		}
+
+		restoredTodo, err := r.RestoreTodo(ctx, subtask.ID, now)
+		if err != nil {
+			t.Fatalf("could not restore subtask: %v", err)
+		}
+
+		if restoredTodo.ParentID != nil {
+			t.Fatalf("restored subtask should not have parent: expected: nil != actual: %s", restoredTodo.ParentID)
+		}
+	})
+
+	t.Run("Move todo", func(t *testing.T) {
+		r := newRepository(t)
+
+		mopID := uuid.MustParse("62446c85-3798-471f-abb8-75c1cdd7153b")
+		vacuumID := uuid.MustParse("f52bad23-c201-414e-9bdb-af4327c42aa7")
+
+		todo, err := r.CreateTodo(ctx, todos.Todo{Description: "Dust the shelves", CreatedAt: now})
+		if err != nil {
+			t.Fatalf("could not create todo: %v", err)
+		}
+
+		movedTodo, err := r.MoveTodo(ctx, todo.ID, repository.TodoMove{Before: &mopID}, now)
+		if err != nil {
+			t.Fatalf("could not move todo: %v", err)
+		}
+
+		if movedTodo.UpdatedAt == nil || !movedTodo.UpdatedAt.Equal(now) {
+			t.Fatalf("moved todo updated at does not match: expected: %s != actual: %v", now, movedTodo.UpdatedAt)
+		}
+
+		_, err = r.MoveTodo(ctx, mopID, repository.TodoMove{After: &vacuumID}, now)
+		if err != nil {
+			t.Fatalf("could not move todo: %v", err)
+		}
+
+		retrievedTodos, _, err := r.GetTodos(ctx, repository.TodosQuery{})
+		if err != nil {
+			t.Fatalf("could not get todos: %v", err)
+		}
+
+		var ids []uuid.UUID
+		for _, todo := range retrievedTodos {
+			ids = append(ids, todo.ID)
+		}
+
+		expectedIDs := []uuid.UUID{todo.ID, vacuumID, mopID}
+		if !cmp.Equal(expectedIDs, ids) {
+			t.Fatalf("todos order does not match: %s", cmp.Diff(expectedIDs, ids))
+		}
+	})
+
+	t.Run("Move todo next to invalid neighbors", func(t *testing.T) {
+		r := newRepository(t)
+
+		mopID := uuid.MustParse("62446c85-3798-471f-abb8-75c1cdd7153b")
+		vacuumID := uuid.MustParse("f52bad23-c201-414e-9bdb-af4327c42aa7")
+
+		for _, move := range []repository.TodoMove{
+			{},
+			{Before: &mopID},
+			{Before: &mopID, After: &vacuumID},
+		} {
+			_, err := r.MoveTodo(ctx, mopID, move, now)
+			if !errors.Is(err, repository.ErrInvalidMove) {
+				t.Fatalf("todo should not be moved: expected: %v != actual: %v", repository.ErrInvalidMove, err)
+			}
+		}
+
+		missingID := uuid.New()
+
+		_, err := r.MoveTodo(ctx, mopID, repository.TodoMove{After: &missingID}, now)
+		if !errors.Is(err, repository.ErrNeighborNotFound) {
+			t.Fatalf("neighbor should not be found: expected: %v != actual: %v", repository.ErrNeighborNotFound, err)
+		}
+
+		_, err = r.MoveTodo(ctx, missingID, repository.TodoMove{After: &mopID}, now)
+		if !errors.Is(err, repository.ErrTodoNotFound) {
+			t.Fatalf("todo should not be found: expected: %v != actual: %v", repository.ErrTodoNotFound, err)
+		}
+	})
+
+	t.Run("Rebalance todo positions", func(t *testing.T) {
+		r := newRepository(t)
+
+		mopID := uuid.MustParse("62446c85-3798-471f-abb8-75c1cdd7153b")
+		vacuumID := uuid.MustParse("f52bad23-c201-414e-9bdb-af4327c42aa7")
+
+		todo, err := r.CreateTodo(ctx, todos.Todo{Description: "Dust the shelves", CreatedAt: now})
+		if err != nil {
+			t.Fatalf("could not create todo: %v", err)
+		}
+
+		// Moving todos to the top repeatedly halves the room before the first todo.
+		for i := range 100 {
+			id, before := vacuumID, mopID
+			if i%2 == 1 {
+				id, before = mopID, vacuumID
+			}
+
+			_, err := r.MoveTodo(ctx, id, repository.TodoMove{Before: &before}, now)
+			if err != nil {
+				t.Fatalf("could not move todo: %v", err)
+			}
+		}
+
+		retrievedTodos, _, err := r.GetTodos(ctx, repository.TodosQuery{})
+		if err != nil {
+			t.Fatalf("could not get todos: %v", err)
+		}
+
+		var ids []uuid.UUID
+
+		for _, todo := range retrievedTodos {
+			if len(todo.Position) > 16 {
+				t.Fatalf("todo position should be rebalanced: actual: %s", todo.Position)
+			}
+
+			ids = append(ids, todo.ID)
+		}
+
+		expectedIDs := []uuid.UUID{mopID, vacuumID, todo.ID}
+		if !cmp.Equal(expectedIDs, ids) {
+			t.Fatalf("todos order does not match: %s", cmp.Diff(expectedIDs, ids))
+		}
+
+		balancedTodo := retrievedTodos[2]
+		if balancedTodo.Version <= todo.Version || balancedTodo.UpdatedAt == nil || !balancedTodo.UpdatedAt.Equal(now) {
+			t.Fatalf("rebalanced todo should have new version: %+v", balancedTodo)
+		}
+
+		deletedTodos, err := r.GetDeletedTodos(ctx)
+		if err != nil {
+			t.Fatalf("could not get deleted todos: %v", err)
+		}
+
+		for _, deletedTodo := range deletedTodos {
+			if deletedTodo.UpdatedAt != nil && deletedTodo.UpdatedAt.Equal(now) {
+				t.Fatalf("deleted todo should not be rebalanced: %+v", deletedTodo)
+			}
+		}
+	})
+
+	t.Run("Append todos", func(t *testing.T) {
+		r := newRepository(t)
+
+		var last string
+
+		for range 50 {
+			todo, err := r.CreateTodo(ctx, todos.Todo{Description: "Dust the shelves", CreatedAt: now})
+			if err != nil {
+				t.Fatalf("could not create todo: %v", err)
+			}
+
+			if todo.Position <= last || len(todo.Position) > 2 {
+				t.Fatalf("appended todo position should be short and follow %s: actual: %s", last, todo.Position)
+			}
+
+			last = todo.Position
+		}
+	})
+
+	t.Run("Restore todo to taken position", func(t *testing.T) {
+		r := newRepository(t)
+
+		mopID := uuid.MustParse("62446c85-3798-471f-abb8-75c1cdd7153b")
+		vacuumID := uuid.MustParse("f52bad23-c201-414e-9bdb-af4327c42aa7")
+		deletedID := uuid.MustParse("aeec043e-05ea-4271-9772-ddefe87628d6")
+
+		todo, err := r.CreateTodo(ctx, todos.Todo{Description: "Dust the shelves", CreatedAt: now})
+		if err != nil {
+			t.Fatalf("could not create todo: %v", err)
+		}
+
+		// The todos take the positions of the deleted todos.
+		_, err = r.MoveTodo(ctx, todo.ID, repository.TodoMove{Before: &mopID}, now)
+		if err != nil {
+			t.Fatalf("could not move todo: %v", err)
+		}
+
+		_, err = r.MoveTodo(ctx, vacuumID, repository.TodoMove{After: &todo.ID, Before: &mopID}, now)
+		if err != nil {
+			t.Fatalf("could not move todo: %v", err)
+		}
+
+		_, err = r.RestoreTodo(ctx, deletedID, now)
+		if err != nil {
+			t.Fatalf("could not restore todo: %v", err)
+		}
+
+		retrievedTodos, _, err := r.GetTodos(ctx, repository.TodosQuery{})
+		if err != nil {
+			t.Fatalf("could not get todos: %v", err)
+		}
+
+		var ids []uuid.UUID
+		for _, todo := range retrievedTodos {
+			ids = append(ids, todo.ID)
+		}
+
+		expectedIDs := []uuid.UUID{todo.ID, vacuumID, mopID, deletedID}
+		if !cmp.Equal(expectedIDs, ids) {
+			t.Fatalf("todos order does not match: %s", cmp.Diff(expectedIDs, ids))
+		}
+	})
+
+	t.Run("Comment todo", func(t *testing.T) {
+		r := newRepository(t)
+
+		todoID := uuid.MustParse("62446c85-3798-471f-abb8-75c1cdd7153b")
+
+		comment, err := r.CreateComment(ctx, todos.Comment{
+			TodoID:    todoID,
+			Author:    "Alex",
+			Body:      "Use the new mop",
+			CreatedAt: now,
+		})
+		if err != nil {
+			t.Fatalf("could not create comment: %v", err)
+		}
+
+		comment.Body = "Use the old mop"
+		comment.UpdatedAt = &now
+
+		_, err = r.SaveComment(ctx, comment)
+		if err != nil {
+			t.Fatalf("could not save comment: %v", err)
+		}
+
+		comments, err := r.GetComments(ctx, todoID)
+		if err != nil {
+			t.Fatalf("could not get comments: %v", err)
+		}
+
+		expectedComments := []todos.Comment{comment}
+		if !cmp.Equal(expectedComments, comments) {
+			t.Fatalf("comments do not match: %s", cmp.Diff(expectedComments, comments))
+		}
+
+		otherTodoID := uuid.MustParse("f52bad23-c201-414e-9bdb-af4327c42aa7")
+
+		err = r.DeleteComment(ctx, otherTodoID, comment.ID, now)
+		if !errors.Is(err, repository.ErrCommentNotFound) {
+			t.Fatalf("comment of other todo should not be found: expected: %v != actual: %v",
+				repository.ErrCommentNotFound,
+				err,
+			)
+		}
+
+		err = r.DeleteComment(ctx, todoID, comment.ID, now)
+		if err != nil {
+			t.Fatalf("could not delete comment: %v", err)
+		}
+
+		_, err = r.SaveComment(ctx, comment)
+		if !errors.Is(err, repository.ErrCommentNotFound) {
+			t.Fatalf(
+				"deleted comment should not be found: expected: %v != actual: %v",
+				repository.ErrCommentNotFound,
+				err,
+			)
+		}
+	})
+
+	t.Run("Hide comments of deleted todo", func(t *testing.T) {
+		r := newRepository(t)
+
+		todoID := uuid.MustParse("62446c85-3798-471f-abb8-75c1cdd7153b")
+
+		_, err := r.CreateComment(ctx, todos.Comment{TodoID: todoID, Author: "Alex", Body: "Done?", CreatedAt: now})
+		if err != nil {
+			t.Fatalf("could not create comment: %v", err)
+		}
+
+		err = r.DeleteTodo(ctx, todoID, 0, now)
+		if err != nil {
+			t.Fatalf("could not delete todo: %v", err)
+		}
+
+		_, err = r.GetComments(ctx, todoID)
+		if !errors.Is(err, repository.ErrTodoNotFound) {
+			t.Fatalf("comments should be hidden: expected: %v != actual: %v", repository.ErrTodoNotFound, err)
+		}
+
+		_, err = r.CreateComment(ctx, todos.Comment{TodoID: todoID, Author: "Alex", Body: "Done!", CreatedAt: now})
+		if !errors.Is(err, repository.ErrTodoNotFound) {
+			t.Fatalf("comment should not be created: expected: %v != actual: %v", repository.ErrTodoNotFound, err)
+		}
+
+		_, err = r.RestoreTodo(ctx, todoID, now)
+		if err != nil {
+			t.Fatalf("could not restore todo: %v", err)
+		}
+
+		comments, err := r.GetComments(ctx, todoID)
+		if err != nil {
+			t.Fatalf("could not get comments: %v", err)
+		}
+
+		if len(comments) != 1 || comments[0].Body != "Done?" {
+			t.Fatalf("comments of restored todo do not match: expected: [Done?] != actual: %v", comments)
+		}
+
+		err = r.DeleteTodo(ctx, todoID, 0, now)
+		if err != nil {
+			t.Fatalf("could not delete todo: %v", err)
+		}
+
+		_, _, err = r.PurgeTodos(ctx, now.Add(time.Second), 10)
+		if err != nil {
+			t.Fatalf("could not purge todo with comments: %v", err)
+		}
+	})
+
+	t.Run("Attach file to todo", func(t *testing.T) {
+		r := newRepository(t)
+
+		todoID := uuid.MustParse("62446c85-3798-471f-abb8-75c1cdd7153b")
+
+		attachment, err := r.CreateAttachment(ctx, todos.Attachment{
+			TodoID:      todoID,
+			Name:        "receipt.pdf",
+			ContentType: "application/pdf",
+			Size:        1024,
+			CreatedAt:   now,
+		})
+		if err != nil {
+			t.Fatalf("could not create attachment: %v", err)
+		}
+
+		attachments, err := r.GetAttachments(ctx, todoID)
+		if err != nil {
+			t.Fatalf("could not get attachments: %v", err)
+		}
+
+		expectedAttachments := []todos.Attachment{attachment}
+		if !cmp.Equal(expectedAttachments, attachments) {
+			t.Fatalf("attachments do not match: %s", cmp.Diff(expectedAttachments, attachments))
+		}
+
+		otherTodoID := uuid.MustParse("f52bad23-c201-414e-9bdb-af4327c42aa7")
+
+		_, err = r.GetAttachment(ctx, otherTodoID, attachment.ID)
+		if !errors.Is(err, repository.ErrAttachmentNotFound) {
+			t.Fatalf("attachment of other todo should not be found: expected: %v != actual: %v",
+				repository.ErrAttachmentNotFound,
+				err,
+			)
+		}
+
+		err = r.DeleteAttachment(ctx, todoID, attachment.ID)
+		if err != nil {
+			t.Fatalf("could not delete attachment: %v", err)
+		}
+
+		_, err = r.GetAttachment(ctx, todoID, attachment.ID)
+		if !errors.Is(err, repository.ErrAttachmentNotFound) {
+			t.Fatalf(
+				"deleted attachment should not be found: expected: %v != actual: %v",
+				repository.ErrAttachmentNotFound,
+				err,
+			)
+		}
+	})
+
+	t.Run("Purge attachments of deleted todo", func(t *testing.T) {
+		r := newRepository(t)
+
+		todoID := uuid.MustParse("62446c85-3798-471f-abb8-75c1cdd7153b")
+
+		attachment, err := r.CreateAttachment(ctx, todos.Attachment{
+			TodoID:      todoID,
+			Name:        "receipt.pdf",
+			ContentType: "application/pdf",
+			Size:        1024,
+			CreatedAt:   now,
+		})
+		if err != nil {
+			t.Fatalf("could not create attachment: %v", err)
+		}
+
+		err = r.DeleteTodo(ctx, todoID, 0, now)
+		if err != nil {
+			t.Fatalf("could not delete todo: %v", err)
+		}
+
+		_, attachments, err := r.PurgeTodos(ctx, now.Add(time.Second), 10)
+		if err != nil {
+			t.Fatalf("could not purge todos: %v", err)
+		}
+
+		expectedAttachments := []uuid.UUID{attachment.ID}
+		if !cmp.Equal(expectedAttachments, attachments) {
+			t.Fatalf("purged attachments do not match: %s", cmp.Diff(expectedAttachments, attachments))
+		}
+	})
+
+	t.Run("Record todo history", func(t *testing.T) {
+		r := newRepository(t)
+
+		ctx := actor.With(ctx, "alex")
+
+		todo, err := r.CreateTodo(ctx, todos.Todo{Description: "Feed the cat", CreatedAt: now})
+		if err != nil {
+			t.Fatalf("could not create todo: %v", err)
+		}
+
+		todo.Description = "Feed the cats"
+		todo.UpdatedAt = &now
+
+		_, err = r.SaveTodo(ctx, todo)
+		if err != nil {
+			t.Fatalf("could not save todo: %v", err)
+		}
+
+		_, err = r.SaveTodo(ctx, todo)
+		if !errors.Is(err, repository.ErrVersionConflict) {
+			t.Fatalf("stale todo should not be saved: expected: %v != actual: %v", repository.ErrVersionConflict, err)
+		}
+
+		_, err = r.CompleteTodo(ctx, todo.ID, now)
+		if err != nil {
+			t.Fatalf("could not complete todo: %v", err)
+		}
+
+		err = r.DeleteTodo(ctx, todo.ID, 0, now)
+		if err != nil {
+			t.Fatalf("could not delete todo: %v", err)
+		}
+
+		events, err := r.GetTodoEvents(ctx, todo.ID)
+		if err != nil {
+			t.Fatalf("could not get todo events: %v", err)
+		}
+
+		expectedTypes := []todos.EventType{
+			todos.EventCreated,
+			todos.EventUpdated,
+			todos.EventCompleted,
+			todos.EventDeleted,
+		}
+		if len(events) != len(expectedTypes) {
+			t.Fatalf("number of events does not match: expected: %d != actual: %d", len(expectedTypes), len(events))
+		}
+
+		for i, event := range events {
+			if event.Type != expectedTypes[i] || event.TodoID != todo.ID || event.Actor != "alex" {
+				t.Errorf("event does not match: expected: %s by alex != actual: %+v", expectedTypes[i], event)
+			}
+		}
+
+		if events[0].Before != nil || events[0].After.Description != "Feed the cat" {
+			t.Errorf("created event does not match: %+v", events[0])
+		}
+
+		if events[1].Before.Description != "Feed the cat" || events[1].After.Description != "Feed the cats" {
+			t.Errorf("updated event does not match: %+v", events[1])
+		}
+
+		if events[2].Before.CompletedAt != nil || events[2].After.CompletedAt == nil {
+			t.Errorf("completed event does not match: %+v", events[2])
+		}
+
+		if events[3].Before.CompletedAt == nil || events[3].After != nil {
+			t.Errorf("deleted event does not match: %+v", events[3])
+		}
+
+		_, err = r.GetTodoEvents(ctx, uuid.MustParse("be95c29a-c4dd-4d31-a5c4-d229f3374ab7"))
+		if !errors.Is(err, repository.ErrTodoNotFound) {
+			t.Fatalf("events of non-existing todo should not be found: expected: %v != actual: %v",
+				repository.ErrTodoNotFound,
+				err,
+			)
+		}
+	})
+
+	t.Run("Record reopen, move and restore history", func(t *testing.T) {
+		r := newRepository(t)
+
+		mopID := uuid.MustParse("62446c85-3798-471f-abb8-75c1cdd7153b")
+
+		todo, err := r.CreateTodo(ctx, todos.Todo{Description: "Dust the shelves", CreatedAt: now})
+		if err != nil {
+			t.Fatalf("could not create todo: %v", err)
+		}
+
+		_, err = r.CompleteTodo(ctx, todo.ID, now)
+		if err != nil {
+			t.Fatalf("could not complete todo: %v", err)
+		}
+
+		_, err = r.ReopenTodo(ctx, todo.ID, now)
+		if err != nil {
+			t.Fatalf("could not reopen todo: %v", err)
+		}
+
+		_, err = r.ReopenTodo(ctx, todo.ID, now)
+		if err != nil {
+			t.Fatalf("could not reopen todo: %v", err)
+		}
+
+		_, err = r.MoveTodo(ctx, todo.ID, repository.TodoMove{Before: &mopID}, now)
+		if err != nil {
+			t.Fatalf("could not move todo: %v", err)
+		}
+
+		err = r.DeleteTodo(ctx, todo.ID, 0, now)
+		if err != nil {
+			t.Fatalf("could not delete todo: %v", err)
+		}
+
+		_, err = r.RestoreTodo(ctx, todo.ID, now)
+		if err != nil {
+			t.Fatalf("could not restore todo: %v", err)
+		}
+
+		events, err := r.GetTodoEvents(ctx, todo.ID)
+		if err != nil {
+			t.Fatalf("could not get todo events: %v", err)
+		}
+
+		expectedTypes := []todos.EventType{
+			todos.EventCreated,
+			todos.EventCompleted,
+			todos.EventReopened,
+			todos.EventMoved,
+			todos.EventDeleted,
+			todos.EventRestored,
+		}
+		if len(events) != len(expectedTypes) {
+			t.Fatalf("number of events does not match: expected: %d != actual: %d", len(expectedTypes), len(events))
+		}
+
+		for i, event := range events {
+			if event.Type != expectedTypes[i] {
+				t.Errorf("event type does not match: expected: %s != actual: %s", expectedTypes[i], event.Type)
+			}
+		}
+
+		if events[2].Before.CompletedAt == nil || events[2].After.CompletedAt != nil {
+			t.Errorf("reopened event does not match: %+v", events[2])
+		}
+
+		if events[3].Before.Position == events[3].After.Position {
+			t.Errorf("moved event does not match: %+v", events[3])
+		}
+
+		if events[5].Before.DeletedAt == nil || events[5].After.DeletedAt != nil {
+			t.Errorf("restored event does not match: %+v", events[5])
+		}
+
+		versions, err := r.GetTodoVersions(ctx, todo.ID)
+		if err != nil {
+			t.Fatalf("could not get todo versions: %v", err)
+		}
+
+		if len(versions) != len(expectedTypes) {
+			t.Fatalf("number of versions does not match: expected: %d != actual: %d", len(expectedTypes), len(versions))
+		}
+	})
+
+	t.Run("Record deletion of subtasks and list todos", func(t *testing.T) {
+		r := newRepository(t)
+
+		list, err := r.CreateList(ctx, todos.List{Name: "Garden", CreatedAt: now})
+		if err != nil {
+			t.Fatalf("could not create list: %v", err)
+		}
+
+		todo, err := r.CreateTodo(ctx, todos.Todo{Description: "Water the plants", ListID: &list.ID, CreatedAt: now})
+		if err != nil {
+			t.Fatalf("could not create todo: %v", err)
+		}
+
+		subtask, err := r.CreateTodo(ctx, todos.Todo{Description: "Fill the can", ParentID: &todo.ID, CreatedAt: now})
+		if err != nil {
+			t.Fatalf("could not create subtask: %v", err)
+		}
+
+		err = r.DeleteList(ctx, list.ID, now)
+		if err != nil {
+			t.Fatalf("could not delete list: %v", err)
+		}
+
+		for _, id := range []uuid.UUID{todo.ID, subtask.ID} {
+			events, err := r.GetTodoEvents(ctx, id)
+			if err != nil {
+				t.Fatalf("could not get todo events: %v", err)
+			}
+
+			expectedLen := 2
+			if len(events) != expectedLen {
+				t.Fatalf("number of events does not match: expected: %d != actual: %d", expectedLen, len(events))
+			}
+
+			deleted := events[1]
+			if deleted.Type != todos.EventDeleted || deleted.Before == nil || deleted.Before.DeletedAt != nil {
+				t.Errorf("deleted event does not match: %+v", deleted)
+			}
+		}
+	})
+
+	t.Run("Record todo versions", func(t *testing.T) {
+		r := newRepository(t)
+
+		savedAt := now.Add(time.Hour)
+		deletedAt := now.Add(2 * time.Hour)
+
+		todo, err := r.CreateTodo(ctx, todos.Todo{Description: "Feed the dog", CreatedAt: now})
+		if err != nil {
+			t.Fatalf("could not create todo: %v", err)
+		}
+
+		todo.Description = "Feed the dogs"
+		todo.UpdatedAt = &savedAt
+
+		_, err = r.SaveTodo(ctx, todo)
+		if err != nil {
+			t.Fatalf("could not save todo: %v", err)
+		}
+
+		_, err = r.ReopenTodo(ctx, todo.ID, savedAt)
+		if err != nil {
+			t.Fatalf("could not reopen todo: %v", err)
+		}
+
+		err = r.DeleteTodo(ctx, todo.ID, 0, deletedAt)
+		if err != nil {
+			t.Fatalf("could not delete todo: %v", err)
+		}
+
+		versions, err := r.GetTodoVersions(ctx, todo.ID)
+		if err != nil {
+			t.Fatalf("could not get todo versions: %v", err)
+		}
+
+		if len(versions) != 3 {
+			t.Fatalf("number of versions does not match: expected: 3 != actual: %d", len(versions))
+		}
+
+		expectedValidFroms := []time.Time{now, savedAt, deletedAt}
+		for i, version := range versions {
+			if version.Number != todo.Version+i || version.Todo.Version != version.Number ||
+				!version.ValidFrom.Equal(expectedValidFroms[i]) {
+				t.Errorf("version does not match: expected: %d valid from %s != actual: %+v",
+					todo.Version+i,
+					expectedValidFroms[i],
+					version,
+				)
+			}
+		}
+
+		if versions[0].ValidTo == nil || !versions[0].ValidTo.Equal(savedAt) || versions[2].ValidTo != nil {
+			t.Errorf("versions should be valid until the next one: %+v", versions)
+		}
+
+		version, ok := todos.VersionAt(versions, now.Add(time.Minute))
+		if !ok || version.Todo.Description != "Feed the dog" || version.Todo.DeletedAt != nil {
+			t.Errorf("version at the time does not match: %+v", version)
+		}
+
+		_, ok = todos.VersionAt(versions, now.Add(-time.Minute))
+		if ok {
+			t.Errorf("todo should not have any version before its creation")
+		}
+
+		_, err = r.GetTodoVersions(ctx, uuid.MustParse("be95c29a-c4dd-4d31-a5c4-d229f3374ab7"))
+		if !errors.Is(err, repository.ErrTodoNotFound) {
+			t.Fatalf("versions of non-existing todo should not be found: expected: %v != actual: %v",
+				repository.ErrTodoNotFound,
+				err,
+			)
+		}
+	})
+
+	t.Run("Get todos last modified", func(t *testing.T) {
+		r := newRepository(t)
+
+		id, err := uuid.Parse("62446c85-3798-471f-abb8-75c1cdd7153b")
+		if err != nil {
+			t.Fatalf("could not parse uuid: %v", err)
+		}
+
+		err = r.DeleteTodo(ctx, id, 0, now)
+		if err != nil {
+			t.Fatalf("could not delete todo: %v", err)
+		}
+
+		lastModified, err := r.GetTodosLastModified(ctx)
+		if err != nil {
+			t.Fatalf("could not get todos last modified: %v", err)
+		}
+
+		if lastModified == nil || !lastModified.Equal(now) {
+			t.Fatalf("todos last modified does not match: expected: %s != actual: %v", now, lastModified)
+		}
+	})
+
+	t.Run("Search todos", func(t *testing.T) {
+		r := newRepository(t)
+
+		results, next, err := r.SearchTodos(ctx, repository.SearchQuery{Text: "vacuuming", Limit: 10})
+		if err != nil {
+			t.Fatalf("could not search todos: %v", err)
+		}
+
+		if len(results) != 1 || results[0].ID.String() != "f52bad23-c201-414e-9bdb-af4327c42aa7" || next != nil {
+			t.Fatalf("found todos do not match: expected: [Vacuum] != actual: %v", results)
+		}
+	})
+
+	t.Run("Escape search snippets", func(t *testing.T) {
+		r := newRepository(t)
+
+		_, err := r.CreateTodo(
+			ctx,
+			todos.Todo{Description: `Water <img src=x onerror="alert(1)"> plants`, CreatedAt: now},
+		)
+		if err != nil {
+			t.Fatalf("could not create todo: %v", err)
+		}
+
+		results, _, err := r.SearchTodos(ctx, repository.SearchQuery{Text: "plants"})
+		if err != nil {
+			t.Fatalf("could not search todos: %v", err)
+		}
+
+		if len(results) != 1 {
+			t.Fatalf("search results count does not match: expected: 1 != actual: %d", len(results))
+		}
+
+		snippet := results[0].Snippet
+		if strings.Contains(snippet, "<img") || !strings.Contains(snippet, "&lt;img") ||
+			!strings.Contains(snippet, "<mark>plants</mark>") {
+			t.Fatalf("snippet is not escaped: %s", snippet)
+		}
+	})
+
+	t.Run("Save existing todo", func(t *testing.T) {
+		r := newRepository(t)
+
+		id, err := uuid.Parse("62446c85-3798-471f-abb8-75c1cdd7153b")
+		if err != nil {
+			t.Fatalf("could not parse uuid: %v", err)
+		}
+
+		todo, err := r.GetTodo(ctx, id)
+		if err != nil {
+			t.Fatalf("could not get todo: %v", err)
+		}
+
+		todo.CompletedAt = &now
+		todo.UpdatedAt = &now
+
+		savedTodo, err := r.SaveTodo(ctx, todo)
+		if err != nil {
+			t.Fatalf("could not save todo: %v", err)
+		}
+
+		if savedTodo.UpdatedAt == nil {
+			t.Fatalf("todo updated timestamp was not changed")
+		}
+
+		nowRounded := now.Round(time.Millisecond)
+
+		todoRounded := savedTodo.UpdatedAt.Round(time.Millisecond)
+		if !todoRounded.Equal(nowRounded) {
+			t.Fatalf("todo completed timestamp does not match: expected: %s != actual: %s",
+				nowRounded,
+				todoRounded,
+			)
+		}
+	})
+
+	t.Run("Save non-existing todo", func(t *testing.T) {
+		r := newRepository(t)
+
+		id, err := uuid.Parse("ac4011ce-59c9-4361-8abf-10abd273d5e5")
+		if err != nil {
+			t.Fatalf("could not parse uuid: %v", err)
+		}
+
+		todo := todos.Todo{
+			ID:          id,
+			Description: "Do some shopping",
+			CompletedAt: nil,
+		}
+
+		_, err = r.SaveTodo(ctx, todo)
+		if !errors.Is(err, repository.ErrTodoNotFound) {
+			t.Fatalf("todo should not be found: expected: %v != actual: %v", repository.ErrTodoNotFound, err)
+		}
+	})
+
+	t.Run("Save todo with stale version", func(t *testing.T) {
+		r := newRepository(t)
+
+		id, err := uuid.Parse("62446c85-3798-471f-abb8-75c1cdd7153b")
+		if err != nil {
+			t.Fatalf("could not parse uuid: %v", err)
+		}
+
+		todo, err := r.GetTodo(ctx, id)
+		if err != nil {
+			t.Fatalf("could not get todo: %v", err)
+		}
+
+		todo.UpdatedAt = &now
+
+		savedTodo, err := r.SaveTodo(ctx, todo)
+		if err != nil {
+			t.Fatalf("could not save todo: %v", err)
+		}
+
+		if savedTodo.Version != todo.Version+1 {
+			t.Fatalf("todo version was not incremented: expected: %d != actual: %d",
+				todo.Version+1,
+				savedTodo.Version,
+			)
+		}
+
+		_, err = r.SaveTodo(ctx, todo)
+		if !errors.Is(err, repository.ErrVersionConflict) {
+			t.Fatalf("todo should conflict: expected: %v != actual: %v", repository.ErrVersionConflict, err)
+		}
+
+		err = r.DeleteTodo(ctx, id, todo.Version, now)
+		if !errors.Is(err, repository.ErrVersionConflict) {
+			t.Fatalf("todo should conflict: expected: %v != actual: %v", repository.ErrVersionConflict, err)
+		}
+	})
+
+	t.Run("Complete already completed todo", func(t *testing.T) {
+		r := newRepository(t)
+
+		id, err := uuid.Parse("f52bad23-c201-414e-9bdb-af4327c42aa7")
+		if err != nil {
+			t.Fatalf("could not parse uuid: %v", err)
+		}
+
+		todo, err := r.GetTodo(ctx, id)
+		if err != nil {
+			t.Fatalf("could not get todo: %v", err)
+		}
+
+		completedTodo, err := r.CompleteTodo(ctx, id, now)
+		if err != nil {
+			t.Fatalf("could not complete todo: %v", err)
+		}
+
+		if !completedTodo.CompletedAt.Equal(*todo.CompletedAt) {
+			t.Fatalf("todo completed timestamp should not change: expected: %s != actual: %s",
+				todo.CompletedAt,
+				completedTodo.CompletedAt,
+			)
+		}
+	})
+
+	t.Run("Complete recurring todo", func(t *testing.T) {
+		r := newRepository(t)
+
+		recurrence, err := todos.ParseRecurrence("FREQ=WEEKLY;BYDAY=TH;COUNT=2")
+		if err != nil {
+			t.Fatalf("could not parse recurrence: %v", err)
+		}
+
+		dueAt := time.Date(2024, time.August, 1, 12, 0, 0, 0, time.UTC)
+
+		todo, err := r.CreateTodo(ctx, todos.Todo{
+			Description: "Take out the trash",
+			DueAt:       &dueAt,
+			Recurrence:  &recurrence,
+			CreatedAt:   now,
+		})
+		if err != nil {
+			t.Fatalf("could not create todo: %v", err)
+		}
+
+		completed := false
+		query := repository.TodosQuery{
+			Filter: repository.TodosFilter{
+				Completed: &completed,
+				Text:      "trash",
+			},
+		}
+
+		for _, expectedDueAt := range []time.Time{dueAt.AddDate(0, 0, 7), {}} {
+			_, err = r.CompleteTodo(ctx, todo.ID, now)
+			if err != nil {
+				t.Fatalf("could not complete todo: %v", err)
+			}
+
+			retrievedTodos, _, err := r.GetTodos(ctx, query)
+			if err != nil {
+				t.Fatalf("could not get todos: %v", err)
+			}
+
+			if expectedDueAt.IsZero() {
+				if len(retrievedTodos) != 0 {
+					t.Fatalf("recurrence should have ended: expected: [] != actual: %v", retrievedTodos)
+				}
+
+				break
+			}
+
+			if len(retrievedTodos) != 1 || !expectedDueAt.Equal(*retrievedTodos[0].DueAt) {
+				t.Fatalf("next occurrence does not match: expected due at: %s != actual: %v",
+					expectedDueAt,
+					retrievedTodos,
+				)
+			}
+
+			todo = retrievedTodos[0]
+		}
+	})
+
+	t.Run("Save recurring todo as completed", func(t *testing.T) {
+		r := newRepository(t)
+
+		recurrence, err := todos.ParseRecurrence("FREQ=WEEKLY;BYDAY=TH;COUNT=3")
+		if err != nil {
+			t.Fatalf("could not parse recurrence: %v", err)
+		}
+
+		dueAt := time.Date(2024, time.August, 1, 12, 0, 0, 0, time.UTC)
+
+		todo, err := r.CreateTodo(ctx, todos.Todo{
+			Description: "Take out the trash",
+			DueAt:       &dueAt,
+			Recurrence:  &recurrence,
+			CreatedAt:   now,
+		})
+		if err != nil {
+			t.Fatalf("could not create todo: %v", err)
+		}
+
+		todo.CompletedAt = &now
+		todo.UpdatedAt = &now
+
+		todo, err = r.SaveTodo(ctx, todo)
+		if err != nil {
+			t.Fatalf("could not save todo: %v", err)
+		}
+
+		todo.Description = "Take out the trash bags"
+
+		_, err = r.SaveTodo(ctx, todo)
+		if err != nil {
+			t.Fatalf("could not save completed todo: %v", err)
+		}
+
+		completed := false
+		query := repository.TodosQuery{
+			Filter: repository.TodosFilter{
+				Completed: &completed,
+				Text:      "trash",
+			},
+		}
+
+		retrievedTodos, _, err := r.GetTodos(ctx, query)
+		if err != nil {
+			t.Fatalf("could not get todos: %v", err)
+		}
+
+		expectedDueAt := dueAt.AddDate(0, 0, 7)
+		if len(retrievedTodos) != 1 || !expectedDueAt.Equal(*retrievedTodos[0].DueAt) {
+			t.Fatalf("next occurrence does not match: expected due at: %s != actual: %v", expectedDueAt, retrievedTodos)
+		}
+
+		next := retrievedTodos[0]
+		next.CompletedAt = &now
+
+		operations := []repository.BatchOperation{{Kind: repository.BatchUpdate, Todo: next}}
+
+		_, err = r.ExecuteBatch(ctx, operations, true, now)
+		if err != nil {
+			t.Fatalf("could not execute batch: %v", err)
+		}
+
+		retrievedTodos, _, err = r.GetTodos(ctx, query)
+		if err != nil {
+			t.Fatalf("could not get todos: %v", err)
+		}
+
+		expectedDueAt = dueAt.AddDate(0, 0, 14)
+		if len(retrievedTodos) != 1 || !expectedDueAt.Equal(*retrievedTodos[0].DueAt) {
+			t.Fatalf("next occurrence does not match: expected due at: %s != actual: %v", expectedDueAt, retrievedTodos)
+		}
+	})
+
+	t.Run("Reopen completed todo", func(t *testing.T) {
+		r := newRepository(t)
+
+		id, err := uuid.Parse("f52bad23-c201-414e-9bdb-af4327c42aa7")
+		if err != nil {
+			t.Fatalf("could not parse uuid: %v", err)
+		}
+
+		reopenedTodo, err := r.ReopenTodo(ctx, id, now)
+		if err != nil {
+			t.Fatalf("could not reopen todo: %v", err)
+		}
+
+		if reopenedTodo.CompletedAt != nil {
+			t.Fatalf("todo should not be completed: expected: nil != actual: %s", reopenedTodo.CompletedAt)
+		}
+	})
+
+	t.Run("Get deleted todos", func(t *testing.T) {
+		r := newRepository(t)
+
+		deletedTodos, err := r.GetDeletedTodos(ctx)
+		if err != nil {
+			t.Fatalf("could not get deleted todos: %v", err)
+		}
+
+		expectedLen := 2
+		if len(deletedTodos) != expectedLen {
+			t.Fatalf("deleted todos count does not match: expected: %d != actual: %d", expectedLen, len(deletedTodos))
+		}
+
+		for _, todo := range deletedTodos {
+			if todo.DeletedAt == nil {
+				t.Fatalf("todo should be deleted: %s", todo.ID)
+			}
+		}
+	})
+
+	t.Run("Restore deleted todo", func(t *testing.T) {
+		r := newRepository(t)
+
+		id, err := uuid.Parse("aeec043e-05ea-4271-9772-ddefe87628d6")
+		if err != nil {
+			t.Fatalf("could not parse uuid: %v", err)
+		}
+
+		_, err = r.RestoreTodo(ctx, id, now)
+		if err != nil {
+			t.Fatalf("could not restore todo: %v", err)
+		}
+
+		_, err = r.GetTodo(ctx, id)
+		if err != nil {
+			t.Fatalf("could not get restored todo: %v", err)
+		}
+
+		_, err = r.RestoreTodo(ctx, id, now)
+		if !errors.Is(err, repository.ErrTodoNotFound) {
+			t.Fatalf("todo should not be found: expected: %v != actual: %v", repository.ErrTodoNotFound, err)
+		}
+	})
+
+	t.Run("Purge deleted todos", func(t *testing.T) {
+		r := newRepository(t)
+
+		purged, _, err := r.PurgeTodos(ctx, now, 1)
+		if err != nil {
+			t.Fatalf("could not purge todos: %v", err)
+		}
+
+		var expectedPurged int64 = 1
+		if purged != expectedPurged {
+			t.Fatalf("purged todos count does not match: expected: %d != actual: %d", expectedPurged, purged)
+		}
+
+		purged, _, err = r.PurgeTodos(ctx, now, 10)
+		if err != nil {
+			t.Fatalf("could not purge todos: %v", err)
+		}
+
+		if purged != expectedPurged {
+			t.Fatalf("purged todos count does not match: expected: %d != actual: %d", expectedPurged, purged)
+		}
+
+		deletedTodos, err := r.GetDeletedTodos(ctx)
+		if err != nil {
+			t.Fatalf("could not get deleted todos: %v", err)
+		}
+
+		if len(deletedTodos) != 0 {
+			t.Fatalf("deleted todos should be purged: %v", deletedTodos)
+		}
+	})
+
+	t.Run("Delete existing todo", func(t *testing.T) {
+		r := newRepository(t)
+
+		id, err := uuid.Parse("f52bad23-c201-414e-9bdb-af4327c42aa7")
+		if err != nil {
+			t.Fatalf("could not parse uuid: %v", err)
+		}
+
+		err = r.DeleteTodo(ctx, id, 0, now)
+		if err != nil {
+			t.Fatalf("todo should be deleted: expected: nil != actual: %v", err)
+		}
+	})
+
+	t.Run("Delete non-existing todo", func(t *testing.T) {
+		r := newRepository(t)
+
+		id, err := uuid.Parse("4fabcaa9-7fe6-4129-86f2-1d62d142a67b")
+		if err != nil {
+			t.Fatalf("could not parse uuid: %v", err)
+		}
+
+		err = r.DeleteTodo(ctx, id, 0, now)
+		if !errors.Is(err, repository.ErrTodoNotFound) {
+			t.Fatalf("todo should not be found: expected: %v != actual: %v", repository.ErrTodoNotFound, err)
+		}
+	})
+
+	t.Run("Execute atomic batch", func(t *testing.T) {
+		r := newRepository(t)
+
+		id, err := uuid.Parse("4fabcaa9-7fe6-4129-86f2-1d62d142a67b")
+		if err != nil {
+			t.Fatalf("could not parse uuid: %v", err)
+		}
+
+		operations := []repository.BatchOperation{
+			{Kind: repository.BatchCreate, Todo: todos.Todo{Description: "Water the plants"}},
+			{Kind: repository.BatchDelete, Todo: todos.Todo{ID: id}},
+		}
+
+		results, err := r.ExecuteBatch(ctx, operations, true, now)
+		if err != nil {
+			t.Fatalf("could not execute batch: %v", err)
+		}
+
+		if !errors.Is(results[0].Err, repository.ErrBatchAborted) {
+			t.Fatalf("operation should be aborted: expected: %v != actual: %v",
+				repository.ErrBatchAborted,
+				results[0].Err,
+			)
+		}
+
+		if !errors.Is(results[1].Err, repository.ErrTodoNotFound) {
+			t.Fatalf("todo should not be found: expected: %v != actual: %v", repository.ErrTodoNotFound, results[1].Err)
+		}
+
+		retrievedTodos, _, err := r.GetTodos(ctx, repository.TodosQuery{})
+		if err != nil {
+			t.Fatalf("could not get todos: %v", err)
+		}
+
+		expectedLen := 2
+		if len(retrievedTodos) != expectedLen {
+			t.Fatalf("created todo should be rolled back: expected: %d != actual: %d", expectedLen, len(retrievedTodos))
+		}
+	})
+
+	t.Run("Execute best effort batch", func(t *testing.T) {
+		r := newRepository(t)
+
+		id, err := uuid.Parse("4fabcaa9-7fe6-4129-86f2-1d62d142a67b")
+		if err != nil {
+			t.Fatalf("could not parse uuid: %v", err)
+		}
+
+		operations := []repository.BatchOperation{
+			{Kind: repository.BatchCreate, Todo: todos.Todo{Description: "Water the plants"}},
+			{Kind: repository.BatchDelete, Todo: todos.Todo{ID: id}},
+		}
+
+		results, err := r.ExecuteBatch(ctx, operations, false, now)
+		if err != nil {
+			t.Fatalf("could not execute batch: %v", err)
+		}
+
+		if results[0].Err != nil {
+			t.Fatalf("todo should be created: expected: nil != actual: %v", results[0].Err)
+		}
+
+		if !errors.Is(results[1].Err, repository.ErrTodoNotFound) {
+			t.Fatalf("todo should not be found: expected: %v != actual: %v", repository.ErrTodoNotFound, results[1].Err)
+		}
+
+		_, err = r.GetTodo(ctx, results[0].Todo.ID)
+		if err != nil {
+			t.Fatalf("could not get created todo: %v", err)
+		}
+	})
+
+	t.Run("Use API keys", func(t *testing.T) {
+		r := newRepository(t)
+
+		expiresAt := now.Add(time.Hour)
+
+		key, err := r.CreateAPIKey(ctx, auth.Key{
+			Name:      "reader",
+			Hash:      auth.Hash("todos_reader"),
+			Scopes:    []string{auth.ScopeRead},
+			ExpiresAt: &expiresAt,
+			CreatedAt: now,
+		})
+		if err != nil {
+			t.Fatalf("could not create api key: %v", err)
+		}
+
+		usedKey, err := r.UseAPIKey(ctx, auth.Hash("todos_reader"), now)
+		if err != nil {
+			t.Fatalf("could not use api key: %v", err)
+		}
+
+		key.LastUsedAt = &now
+		if !cmp.Equal(key, usedKey) {
+			t.Fatalf("used key does not match: %s", cmp.Diff(key, usedKey))
+		}
+
+		_, err = r.UseAPIKey(ctx, auth.Hash("todos_reader"), expiresAt)
+		if !errors.Is(err, repository.ErrInvalidAPIKey) {
+			t.Fatalf("expired key should be invalid: expected: %v != actual: %v", repository.ErrInvalidAPIKey, err)
+		}
+
+		err = r.RevokeAPIKey(ctx, key.ID, now)
+		if err != nil {
+			t.Fatalf("could not revoke api key: %v", err)
+		}
+
+		_, err = r.UseAPIKey(ctx, auth.Hash("todos_reader"), now)
+		if !errors.Is(err, repository.ErrInvalidAPIKey) {
+			t.Fatalf("revoked key should be invalid: expected: %v != actual: %v", repository.ErrInvalidAPIKey, err)
+		}
+
+		err = r.RevokeAPIKey(ctx, key.ID, now)
+		if !errors.Is(err, repository.ErrAPIKeyNotFound) {
+			t.Fatalf("revoked key should not be found: expected: %v != actual: %v", repository.ErrAPIKeyNotFound, err)
+		}
+
+		keys, err := r.GetAPIKeys(ctx)
+		if err != nil {
+			t.Fatalf("could not get api keys: %v", err)
+		}
+
+		key.RevokedAt = &now
+
+		expectedKeys := []auth.Key{key}
+		if !cmp.Equal(expectedKeys, keys) {
+			t.Fatalf("keys do not match: %s", cmp.Diff(expectedKeys, keys))
 		}
 	})
 }
@@ -0,0 +1,295 @@
+package repository
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/course-go/todos/internal/todos"
+)
+
+var (
+	ErrUnknownSortField   = errors.New("unknown sort field")
+	ErrDuplicateSortField = errors.New("duplicate sort field")
+)
+
+// SortField is a sortable field of [todos.Todo] named the same way as in its JSON representation.
+type SortField string
+
+const (
+	SortByID          SortField = "id"
+	SortByDescription SortField = "description"
+	SortByPriority    SortField = "priority"
+	SortByCreatedAt   SortField = "createdAt"
+	SortByUpdatedAt   SortField = "updatedAt"
+	SortByCompletedAt SortField = "completedAt"
+	SortByDueAt       SortField = "dueAt"
+	SortByPosition    SortField = "position"
+)
+
+// Sort orders todos by a single field. Missing values are always sorted last.
+type Sort struct {
+	Field      SortField
+	Descending bool
+}
+
+type sortField struct {
+	column   string
+	cast     string
+	nullable bool
+	// pick copies the field value from src to dst.
+	pick func(dst *todos.Todo, src todos.Todo)
+	// value returns the field value or nil if it is missing.
+	value func(todo todos.Todo) any
+	// compare compares the field values of todos which both have the value.
+	compare func(a, b todos.Todo) int
+}
+
+var sortFields = map[SortField]sortField{
+	SortByID: {
+		column: "id",
+		cast:   "uuid",
+		pick:   func(dst *todos.Todo, src todos.Todo) { dst.ID = src.ID },
+		value:  func(todo todos.Todo) any { return todo.ID },
+		compare: func(a, b todos.Todo) int {
+			return bytes.Compare(a.ID[:], b.ID[:])
+		},
+	},
+	SortByDescription: {
+		column: "description",
+		cast:   "text",
+		pick:   func(dst *todos.Todo, src todos.Todo) { dst.Description = src.Description },
+		value:  func(todo todos.Todo) any { return todo.Description },
+		compare: func(a, b todos.Todo) int {
+			return strings.Compare(a.Description, b.Description)
+		},
+	},
+	SortByPriority: {
+		column: "priority",
+		cast:   "smallint",
+		pick:   func(dst *todos.Todo, src todos.Todo) { dst.Priority = src.Priority },
+		value:  func(todo todos.Todo) any { return todo.Priority },
+		compare: func(a, b todos.Todo) int {
+			return cmp.Compare(a.Priority, b.Priority)
+		},
+	},
+	SortByCreatedAt: {
+		column: "created_at",
+		cast:   "timestamp",
+		pick:   func(dst *todos.Todo, src todos.Todo) { dst.CreatedAt = src.CreatedAt },
+		value:  func(todo todos.Todo) any { return todo.CreatedAt },
+		compare: func(a, b todos.Todo) int {
+			return a.CreatedAt.Compare(b.CreatedAt)
+		},
+	},
+	SortByUpdatedAt: {
+		column:   "updated_at",
+		cast:     "timestamp",
+		nullable: true,
+		pick:     func(dst *todos.Todo, src todos.Todo) { dst.UpdatedAt = src.UpdatedAt },
+		value:    func(todo todos.Todo) any { return nullableValue(todo.UpdatedAt) },
+		compare: func(a, b todos.Todo) int {
+			return a.UpdatedAt.Compare(*b.UpdatedAt)
+		},
+	},
+	SortByCompletedAt: {
+		column:   "completed_at",
+		cast:     "timestamp",
+		nullable: true,
+		pick:     func(dst *todos.Todo, src todos.Todo) { dst.CompletedAt = src.CompletedAt },
+		value:    func(todo todos.Todo) any { return nullableValue(todo.CompletedAt) },
+		compare: func(a, b todos.Todo) int {
+			return a.CompletedAt.Compare(*b.CompletedAt)
+		},
+	},
+	SortByDueAt: {
+		column:   "due_at",
+		cast:     "timestamp",
+		nullable: true,
+		pick:     func(dst *todos.Todo, src todos.Todo) { dst.DueAt = src.DueAt },
+		value:    func(todo todos.Todo) any { return nullableValue(todo.DueAt) },
+		compare: func(a, b todos.Todo) int {
+			return a.DueAt.Compare(*b.DueAt)
+		},
+	},
+	SortByPosition: {
+		column: "position",
+		cast:   "text",
+		pick:   func(dst *todos.Todo, src todos.Todo) { dst.Position = src.Position },
+		value:  func(todo todos.Todo) any { return todo.Position },
+		compare: func(a, b todos.Todo) int {
+			return strings.Compare(a.Position, b.Position)
+		},
+	},
+}
+
+// DefaultSort returns the order used when no other order is requested.
+// It is the order the todos were manually moved to.
+func DefaultSort() []Sort {
+	return []Sort{{Field: SortByPosition}}
+}
+
+// ParseSort parses comma separated list of sort fields.
+// Fields prefixed with "-" are sorted in descending order.
+func ParseSort(s string) (sort []Sort, err error) {
+	seen := make(map[SortField]bool)
+
+	for item := range strings.SplitSeq(s, ",") {
+		item = strings.TrimSpace(item)
+
+		var descending bool
+
+		switch {
+		case strings.HasPrefix(item, "-"):
+			descending = true
+			item = item[1:]
+		case strings.HasPrefix(item, "+"):
+			item = item[1:]
+		}
+
+		field := SortField(item)
+		if _, ok := sortFields[field]; !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownSortField, item)
+		}
+
+		if seen[field] {
+			return nil, fmt.Errorf("%w: %q", ErrDuplicateSortField, item)
+		}
+
+		seen[field] = true
+		sort = append(sort, Sort{
+			Field:      field,
+			Descending: descending,
+		})
+	}
+
+	return sort, nil
+}
+
+// FormatSort is the inverse of [ParseSort].
+func FormatSort(sort []Sort) string {
+	items := make([]string, 0, len(sort))
+	for _, s := range sort {
+		item := string(s.Field)
+		if s.Descending {
+			item = "-" + item
+		}
+
+		items = append(items, item)
+	}
+
+	return strings.Join(items, ",")
+}
+
+// keyset returns the sort extended with ID so it always determines unique order.
+func keyset(sort []Sort) []Sort {
+	for i, s := range sort {
+		if s.Field == SortByID {
+			return sort[:i+1]
+		}
+	}
+
+	return append(sort[:len(sort):len(sort)], Sort{Field: SortByID})
+}
+
+func orderTodos(sort []Sort) string {
+	items := make([]string, 0, len(sort)+1)
+	for _, s := range keyset(sort) {
+		direction := "ASC"
+		if s.Descending {
+			direction = "DESC"
+		}
+
+		field := sortFields[s.Field]
+
+		item := field.column + " " + direction
+		if field.nullable {
+			item += " NULLS LAST"
+		}
+
+		items = append(items, item)
+	}
+
+	return "ORDER BY " + strings.Join(items, ", ")
+}
+
+// afterCursor adds condition matching todos following the cursor in the given order.
+func afterCursor(b *queryBuilder, sort []Sort, cursor Cursor) {
+	var (
+		alternatives []string
+		equalities   []string
+	)
+
+	for _, s := range keyset(sort) {
+		field := sortFields[s.Field]
+		value := field.value(cursor.Todo)
+
+		if value != nil {
+			operator := ">"
+			if s.Descending {
+				operator = "<"
+			}
+
+			placeholder := b.typedArg(value, field.cast)
+			following := field.column + " " + operator + " " + placeholder
+
+			if field.nullable {
+				following = "(" + following + " OR " + field.column + " IS NULL)"
+			}
+
+			alternatives = append(alternatives, strings.Join(append(equalities, following), " AND "))
+			equalities = append(equalities, field.column+" = "+placeholder)
+		} else {
+			// Missing values are sorted last so nothing follows them.
+			equalities = append(equalities, field.column+" IS NULL")
+		}
+	}
+
+	if len(alternatives) == 0 {
+		b.where("FALSE")
+		return
+	}
+
+	b.where("((" + strings.Join(alternatives, ") OR (") + "))")
+}
+
+// compareTodos compares todos the same way as they are ordered by [orderTodos].
+func compareTodos(sort []Sort, a, b todos.Todo) int {
+	for _, s := range keyset(sort) {
+		field := sortFields[s.Field]
+
+		aMissing := field.value(a) == nil
+		bMissing := field.value(b) == nil
+
+		// Missing values are sorted last regardless of the direction.
+		switch {
+		case aMissing && bMissing:
+			continue
+		case aMissing:
+			return 1
+		case bMissing:
+			return -1
+		}
+
+		c := field.compare(a, b)
+		if s.Descending {
+			c = -c
+		}
+
+		if c != 0 {
+			return c
+		}
+	}
+
+	return 0
+}
+
+func nullableValue[T any](value *T) any {
+	if value == nil {
+		return nil
+	}
+
+	return *value
+}
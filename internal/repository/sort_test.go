@@ -0,0 +1,54 @@
+package repository_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/course-go/todos/internal/repository"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSort(t *testing.T) {
+	t.Parallel()
+	t.Run("Valid sort", func(t *testing.T) {
+		t.Parallel()
+
+		sort, err := repository.ParseSort("-createdAt, +description,id")
+		if err != nil {
+			t.Fatalf("could not parse sort: expected: nil != actual: %v", err)
+		}
+
+		expectedSort := []repository.Sort{
+			{Field: repository.SortByCreatedAt, Descending: true},
+			{Field: repository.SortByDescription},
+			{Field: repository.SortByID},
+		}
+		if !cmp.Equal(expectedSort, sort) {
+			t.Fatalf("parsed sort does not match: %s", cmp.Diff(expectedSort, sort))
+		}
+
+		expectedFormat := "-createdAt,description,id"
+		if repository.FormatSort(sort) != expectedFormat {
+			t.Fatalf("formatted sort does not match: expected: %s != actual: %s",
+				expectedFormat,
+				repository.FormatSort(sort),
+			)
+		}
+	})
+	t.Run("Unknown sort field", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := repository.ParseSort("-createdAt,owner")
+		if !errors.Is(err, repository.ErrUnknownSortField) {
+			t.Fatalf("sort should not be parsed: expected: %v != actual: %v", repository.ErrUnknownSortField, err)
+		}
+	})
+	t.Run("Duplicate sort field", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := repository.ParseSort("createdAt,-createdAt")
+		if !errors.Is(err, repository.ErrDuplicateSortField) {
+			t.Fatalf("sort should not be parsed: expected: %v != actual: %v", repository.ErrDuplicateSortField, err)
+		}
+	})
+}
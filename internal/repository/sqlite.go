@@ -0,0 +1,2660 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/course-go/todos/internal/auth"
+	"github.com/course-go/todos/internal/config"
+	"github.com/course-go/todos/internal/health"
+	"github.com/course-go/todos/internal/todos"
+	"github.com/google/uuid"
+	_ "modernc.org/sqlite" // Used to register "sqlite" database/sql driver.
+)
+
+const (
+	SQLiteProtocol = "sqlite"
+
+	// sqliteTimeFormat is the format of timestamps stored as text by SQLite.
+	// It has fixed width so that the timestamps sort the same way as the times they represent.
+	sqliteTimeFormat = "2006-01-02 15:04:05.000000Z"
+
+	// sqliteTodoColumns are all columns mapped to [todos.Todo] fields including the tags encoded as JSON array
+	// and the subtasks progress encoded as JSON object.
+	sqliteTodoColumns = todoColumns + `,
+		(
+			SELECT json_group_array(tags.name)
+			FROM todo_tags JOIN tags ON tags.id = todo_tags.tag_id
+			WHERE todo_tags.todo_id = todos.id
+		) AS tags,
+		(
+			SELECT CASE WHEN count(*) > 0 THEN
+				json_object('completed', count(subtasks.completed_at), 'total', count(*))
+			END
+			FROM todos AS subtasks
+			WHERE subtasks.parent_id = todos.id AND subtasks.deleted_at IS NULL
+		) AS progress`
+)
+
+var _ TodoRepository = SQLiteRepository{}
+
+// sqliteQuerier executes queries either directly on the database or within a transaction.
+type sqliteQuerier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// SQLiteRepository stores todos in SQLite database file named by the database config.
+type SQLiteRepository struct {
+	logger   *slog.Logger
+	registry *health.Registry
+	config   *config.Database
+	db       *sql.DB
+}
+
+// NewSQLite opens the SQLite database and migrates its schema.
+func NewSQLite(
+	ctx context.Context,
+	logger *slog.Logger,
+	registry *health.Registry,
+	config *config.Database,
+) (repository *SQLiteRepository, err error) {
+	logger = logger.With("component", "sqlite.repository.todos")
+
+	// SQLite only enforces foreign keys on connections which enable them.
+	dataSourceName := config.Name + "?_pragma=foreign_keys(1)"
+	if config.Options != "" {
+		dataSourceName += "&" + config.Options
+	}
+
+	db, err := sql.Open("sqlite", dataSourceName)
+	if err != nil {
+		return nil, fmt.Errorf("failed opening sqlite database: %w", err)
+	}
+
+	// SQLite allows only a single writer so concurrent connections would only fail on locks.
+	// This also keeps in-memory databases alive as each connection would have its own.
+	db.SetMaxOpenConns(1)
+
+	logger.Info(
+		"opened sqlite database",
+		"dataSourceName", dataSourceName,
+	)
+
+	err = MigrateSQLite(db, logger)
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("%w: %w", ErrMigrations, err)
+	}
+
+	checks := []health.Check{
+		{
+			Period: databaseHealthPingPeriod,
+			CheckFn: func(ctx context.Context, c *health.Component) {
+				c.UpdatedAt = time.Now()
+				err := db.PingContext(ctx)
+				if err != nil {
+					c.Health = health.ERROR
+					c.Message = err.Error()
+					return
+				}
+
+				c.Health = health.OK
+				c.Message = ""
+			},
+		},
+	}
+	registry.RegisterComponent(ctx, health.NewComponent("database", checks...))
+
+	repository = &SQLiteRepository{
+		logger:   logger,
+		registry: registry,
+		config:   config,
+		db:       db,
+	}
+
+	return repository, nil
+}
+
+func (r SQLiteRepository) GetTodos(
+	ctx context.Context,
+	query TodosQuery,
+) (t []todos.Todo, next *Cursor, err error) {
+	b := queryBuilder{dialect: sqliteDialect}
+
+	b.where("deleted_at IS NULL")
+	filterTodos(&b, query.Filter)
+
+	sort := query.Sort
+	if len(sort) == 0 {
+		sort = DefaultSort()
+	}
+
+	if query.Cursor != nil {
+		afterCursor(&b, sort, *query.Cursor)
+	}
+
+	// Negative limit means no limit in SQLite.
+	limit := -1
+	if query.Limit > 0 {
+		// One extra todo is fetched to find out whether there is a next page.
+		limit = query.Limit + 1
+	}
+
+	//nolint: gosec // Only conditions with placeholders for the arguments are concatenated.
+	sqlQuery := `
+		SELECT ` + sqliteTodoColumns + `
+		FROM todos
+		` + b.whereClause() + `
+		` + orderTodos(sort) + `
+		LIMIT ` + b.arg(limit)
+
+	rows, err := r.db.QueryContext(ctx, sqlQuery, b.args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed querying database: %w", err)
+	}
+
+	t, err = collectSQLiteTodos(rows)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	if query.Limit > 0 && len(t) > query.Limit {
+		t = t[:query.Limit]
+		next = NewCursor(sort, t[len(t)-1])
+	}
+
+	return t, next, nil
+}
+
+// SearchTodos searches descriptions using SQLite full-text search.
+// All terms of the text have to match, other web search syntax is not supported.
+// Results are ranked by the database but paginated by their rank converted
+// to the precision of the cursor so all matches have to be fetched for every page.
+func (r SQLiteRepository) SearchTodos(
+	ctx context.Context,
+	query SearchQuery,
+) (results []todos.SearchResult, next *SearchCursor, err error) {
+	terms := strings.Fields(query.Text)
+	if len(terms) == 0 {
+		return make([]todos.SearchResult, 0), nil, nil
+	}
+
+	for i, term := range terms {
+		// Quoted terms are matched as they are without being parsed as query syntax.
+		terms[i] = `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`
+		SELECT `+sqliteTodoColumns+`, rank, snippet
+		FROM todos
+		JOIN (
+			SELECT id AS search_id,
+				-bm25(todos_search) AS rank,
+				highlight(todos_search, 1, $2, $3) AS snippet
+			FROM todos_search
+			WHERE todos_search MATCH $1
+		) ON id = search_id
+		WHERE deleted_at IS NULL
+		`,
+		sqliteArgs(strings.Join(terms, " "), snippetStartSel, snippetStopSel)...,
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed querying database: %w", err)
+	}
+
+	results, err = collectSQLiteSearchResults(rows)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	results, next = pageSearchResults(results, query)
+
+	return results, next, nil
+}
+
+// GetTodosLastModified returns time of the latest creation, change or deletion of any todo.
+// Nil is returned if there are no todos at all.
+func (r SQLiteRepository) GetTodosLastModified(ctx context.Context) (lastModified *time.Time, err error) {
+	// Unlike GREATEST in Postgres, scalar MAX in SQLite returns NULL if any of its arguments is NULL.
+	err = r.db.QueryRowContext(ctx,
+		`
+		SELECT MAX(MAX(created_at, COALESCE(updated_at, created_at), COALESCE(deleted_at, created_at)))
+		FROM todos
+		`,
+	).Scan(sqliteNullTime{&lastModified})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return lastModified, nil
+}
+
+// GetDeletedTodos returns deleted todos, the most recently deleted first.
+func (r SQLiteRepository) GetDeletedTodos(ctx context.Context) (t []todos.Todo, err error) {
+	rows, err := r.db.QueryContext(ctx,
+		`
+		SELECT `+sqliteTodoColumns+`
+		FROM todos
+		WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC, id
+		`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed querying database: %w", err)
+	}
+
+	t, err = collectSQLiteTodos(rows)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return t, nil
+}
+
+func (r SQLiteRepository) GetTodo(ctx context.Context, id uuid.UUID) (t todos.Todo, err error) {
+	return getSQLiteTodo(ctx, r.db, id)
+}
+
+func (r SQLiteRepository) CreateTodo(ctx context.Context, todo todos.Todo) (createdTodo todos.Todo, err error) {
+	err = inSQLiteTransaction(ctx, r.db, func(tx *sql.Tx) error {
+		createdTodo, err = createSQLiteTodo(ctx, tx, todo)
+		return err
+	})
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	return createdTodo, nil
+}
+
+// SaveTodo updates the todo including its tags. If the todo has non-zero version,
+// the update only succeeds if the stored todo still has the same version.
+func (r SQLiteRepository) SaveTodo(ctx context.Context, todo todos.Todo) (savedTodo todos.Todo, err error) {
+	err = inSQLiteTransaction(ctx, r.db, func(tx *sql.Tx) error {
+		savedTodo, err = saveSQLiteTodo(ctx, tx, todo)
+		return err
+	})
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	return savedTodo, nil
+}
+
+// CompleteTodo marks todo as completed at the given time. If the todo recurs,
+// its next occurrence is created. Already completed todos are left unchanged.
+func (r SQLiteRepository) CompleteTodo(
+	ctx context.Context,
+	id uuid.UUID,
+	completedAt time.Time,
+) (completedTodo todos.Todo, err error) {
+	err = inSQLiteTransaction(ctx, r.db, func(tx *sql.Tx) error {
+		completedTodo, err = completeSQLiteTodo(ctx, tx, id, completedAt)
+		return err
+	})
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	return completedTodo, nil
+}
+
+// MoveTodo places the todo between its neighbors. If the new position of the todo
+// would be too long, positions of all todos which are not deleted are rebalanced first.
+func (r SQLiteRepository) MoveTodo(
+	ctx context.Context,
+	id uuid.UUID,
+	move TodoMove,
+	movedAt time.Time,
+) (movedTodo todos.Todo, err error) {
+	err = inSQLiteTransaction(ctx, r.db, func(tx *sql.Tx) error {
+		movedTodo, err = moveSQLiteTodo(ctx, tx, id, move, movedAt)
+		return err
+	})
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	return movedTodo, nil
+}
+
+// ReopenTodo marks todo as not completed.
+// Todos which are not completed are left unchanged.
+func (r SQLiteRepository) ReopenTodo(
+	ctx context.Context,
+	id uuid.UUID,
+	reopenedAt time.Time,
+) (reopenedTodo todos.Todo, err error) {
+	err = inSQLiteTransaction(ctx, r.db, func(tx *sql.Tx) error {
+		previousTodo, err := getSQLiteTodo(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if previousTodo.CompletedAt == nil {
+			reopenedTodo = previousTodo
+			return nil
+		}
+
+		row := tx.QueryRowContext(ctx,
+			`
+			UPDATE todos
+			SET completed_at = NULL, updated_at = $2, version = version + 1
+			WHERE id=$1
+			RETURNING `+sqliteTodoColumns,
+			sqliteArgs(id, reopenedAt)...,
+		)
+
+		reopenedTodo, err = scanChangedSQLiteTodo(row)
+		if err != nil {
+			return err
+		}
+
+		event := newEvent(ctx, todos.EventReopened, &previousTodo, &reopenedTodo, reopenedAt)
+
+		return recordSQLiteChange(ctx, tx, event, previousTodo, reopenedTodo)
+	})
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	return reopenedTodo, nil
+}
+
+// RestoreTodo brings back deleted todo. The todo is removed from its list or parent if they were deleted.
+// Subtasks deleted together with the todo are not restored. Todos which are not deleted are reported as not found.
+// The todo keeps its position unless another todo took it meanwhile, in which case it follows all todos.
+func (r SQLiteRepository) RestoreTodo(
+	ctx context.Context,
+	id uuid.UUID,
+	restoredAt time.Time,
+) (restoredTodo todos.Todo, err error) {
+	err = inSQLiteTransaction(ctx, r.db, func(tx *sql.Tx) error {
+		previousTodo, err := getDeletedSQLiteTodo(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		position, err := restoredSQLitePosition(ctx, tx, previousTodo, restoredAt)
+		if err != nil {
+			return err
+		}
+
+		row := tx.QueryRowContext(ctx,
+			`
+			UPDATE todos
+			SET deleted_at = NULL, updated_at = $2, version = version + 1, position = $3,
+				list_id = (SELECT id FROM lists WHERE id = todos.list_id AND deleted_at IS NULL),
+				parent_id = (
+					SELECT parents.id FROM todos AS parents WHERE parents.id = todos.parent_id AND parents.deleted_at IS NULL
+				)
+			WHERE id=$1
+			RETURNING `+sqliteTodoColumns,
+			sqliteArgs(id, restoredAt, position)...,
+		)
+
+		restoredTodo, err = scanChangedSQLiteTodo(row)
+		if err != nil {
+			return err
+		}
+
+		event := newEvent(ctx, todos.EventRestored, &previousTodo, &restoredTodo, restoredAt)
+
+		return recordSQLiteChange(ctx, tx, event, previousTodo, restoredTodo)
+	})
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	return restoredTodo, nil
+}
+
+// DeleteTodo marks the todo and all of its subtasks as deleted. If the version is non-zero,
+// the deletion only succeeds if the stored todo still has the same version.
+func (r SQLiteRepository) DeleteTodo(ctx context.Context, id uuid.UUID, version int, deletedAt time.Time) error {
+	return inSQLiteTransaction(ctx, r.db, func(tx *sql.Tx) error {
+		return deleteSQLiteTodo(ctx, tx, id, version, deletedAt)
+	})
+}
+
+// PurgeTodos works like [Repository.PurgeTodos].
+func (r SQLiteRepository) PurgeTodos(
+	ctx context.Context,
+	deletedBefore time.Time,
+	limit int,
+) (purged int64, attachments []uuid.UUID, err error) {
+	const purgedTodos = `
+		SELECT id
+		FROM todos
+		WHERE deleted_at < $1
+		ORDER BY deleted_at
+		LIMIT $2
+	`
+
+	err = inSQLiteTransaction(ctx, r.db, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx,
+			`
+			SELECT id
+			FROM attachments
+			WHERE todo_id IN (`+purgedTodos+`)
+			`,
+			sqliteArgs(deletedBefore, limit)...,
+		)
+		if err != nil {
+			return fmt.Errorf("failed querying database: %w", err)
+		}
+
+		attachments, err = collectSQLiteIDs(rows)
+		if err != nil {
+			return err
+		}
+
+		result, err := tx.ExecContext(ctx,
+			`
+			DELETE FROM todos
+			WHERE id IN (`+purgedTodos+`)
+			`,
+			sqliteArgs(deletedBefore, limit)...,
+		)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrDatabase, err)
+		}
+
+		purged, err = result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrDatabase, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return purged, attachments, nil
+}
+
+// GetTags returns all tags sorted by their names.
+func (r SQLiteRepository) GetTags(ctx context.Context) (t []todos.Tag, err error) {
+	rows, err := r.db.QueryContext(ctx,
+		`
+		SELECT id, name
+		FROM tags
+		ORDER BY name
+		`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed querying database: %w", err)
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	// Use append to avoid returning nil slice
+	t = make([]todos.Tag, 0)
+
+	for rows.Next() {
+		var tag todos.Tag
+
+		err = rows.Scan(&tag.ID, &tag.Name)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+		}
+
+		t = append(t, tag)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return t, nil
+}
+
+func (r SQLiteRepository) GetTag(ctx context.Context, id uuid.UUID) (t todos.Tag, err error) {
+	err = r.db.QueryRowContext(ctx,
+		`
+		SELECT id, name
+		FROM tags
+		WHERE id=$1
+		`,
+		sqliteArgs(id)...,
+	).Scan(&t.ID, &t.Name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return todos.Tag{}, ErrTagNotFound
+	}
+
+	if err != nil {
+		return todos.Tag{}, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return t, nil
+}
+
+// CreateTag creates tag with the given name unless there already is one.
+func (r SQLiteRepository) CreateTag(ctx context.Context, tag todos.Tag) (createdTag todos.Tag, err error) {
+	err = r.db.QueryRowContext(ctx,
+		`
+		INSERT INTO tags (id, name)
+		VALUES ($1, $2)
+		ON CONFLICT (name) DO NOTHING
+		RETURNING id, name
+		`,
+		sqliteArgs(uuid.New(), tag.Name)...,
+	).Scan(&createdTag.ID, &createdTag.Name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return todos.Tag{}, ErrTagExists
+	}
+
+	if err != nil {
+		return todos.Tag{}, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return createdTag, nil
+}
+
+// SaveTag renames the tag unless another tag already has the name.
+func (r SQLiteRepository) SaveTag(
+	ctx context.Context,
+	tag todos.Tag,
+	savedAt time.Time,
+) (savedTag todos.Tag, err error) {
+	err = inSQLiteTransaction(ctx, r.db, func(tx *sql.Tx) error {
+		previousTodos, err := taggedSQLiteTodos(ctx, tx, tag.ID)
+		if err != nil {
+			return err
+		}
+
+		err = tx.QueryRowContext(ctx,
+			`
+			UPDATE tags
+			SET name = $2
+			WHERE id=$1 AND NOT EXISTS (SELECT 1 FROM tags WHERE name = $2 AND id <> $1)
+			RETURNING id, name
+			`,
+			sqliteArgs(tag.ID, tag.Name)...,
+		).Scan(&savedTag.ID, &savedTag.Name)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrTagExists
+		}
+
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrDatabase, err)
+		}
+
+		return touchSQLiteTodos(ctx, tx, previousTodos, savedAt)
+	})
+	if errors.Is(err, ErrTagExists) {
+		_, err = r.GetTag(ctx, tag.ID)
+		if err != nil {
+			return todos.Tag{}, err
+		}
+
+		return todos.Tag{}, ErrTagExists
+	}
+
+	if err != nil {
+		return todos.Tag{}, err
+	}
+
+	return savedTag, nil
+}
+
+func (r SQLiteRepository) DeleteTag(ctx context.Context, id uuid.UUID, deletedAt time.Time) error {
+	return inSQLiteTransaction(ctx, r.db, func(tx *sql.Tx) error {
+		previousTodos, err := taggedSQLiteTodos(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		result, err := tx.ExecContext(ctx,
+			`
+			DELETE FROM tags
+			WHERE id=$1
+			`,
+			sqliteArgs(id)...,
+		)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrDatabase, err)
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrDatabase, err)
+		}
+
+		if affected == 0 {
+			return ErrTagNotFound
+		}
+
+		return touchSQLiteTodos(ctx, tx, previousTodos, deletedAt)
+	})
+}
+
+// taggedSQLiteTodos returns the todos labeled by the tag like [taggedTodos].
+func taggedSQLiteTodos(ctx context.Context, q sqliteQuerier, tagID uuid.UUID) ([]todos.Todo, error) {
+	rows, err := q.QueryContext(ctx,
+		`
+		SELECT `+sqliteTodoColumns+`
+		FROM todos
+		WHERE id IN (SELECT todo_id FROM todo_tags WHERE tag_id=$1)
+		ORDER BY id
+		`,
+		sqliteArgs(tagID)...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed querying database: %w", err)
+	}
+
+	taggedTodos, err := collectSQLiteTodos(rows)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return taggedTodos, nil
+}
+
+// touchSQLiteTodos marks the todos as updated and records the change like [touchTodos].
+func touchSQLiteTodos(ctx context.Context, q sqliteQuerier, previousTodos []todos.Todo, changedAt time.Time) error {
+	for _, previousTodo := range previousTodos {
+		row := q.QueryRowContext(ctx,
+			`
+			UPDATE todos
+			SET updated_at = $2, version = version + 1
+			WHERE id=$1
+			RETURNING `+sqliteTodoColumns,
+			sqliteArgs(previousTodo.ID, changedAt)...,
+		)
+
+		touchedTodo, err := scanChangedSQLiteTodo(row)
+		if err != nil {
+			return err
+		}
+
+		event := newEvent(ctx, todos.EventUpdated, &previousTodo, &touchedTodo, changedAt)
+
+		err = recordSQLiteChange(ctx, q, event, previousTodo, touchedTodo)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetLists returns all lists in the order they were created.
+func (r SQLiteRepository) GetLists(ctx context.Context) (l []todos.List, err error) {
+	rows, err := r.db.QueryContext(ctx,
+		`
+		SELECT `+listColumns+`
+		FROM lists
+		WHERE deleted_at IS NULL
+		ORDER BY created_at, id
+		`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed querying database: %w", err)
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	// Use append to avoid returning nil slice
+	l = make([]todos.List, 0)
+
+	for rows.Next() {
+		list, err := scanSQLiteList(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+		}
+
+		l = append(l, list)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return l, nil
+}
+
+func (r SQLiteRepository) GetList(ctx context.Context, id uuid.UUID) (l todos.List, err error) {
+	row := r.db.QueryRowContext(ctx,
+		`
+		SELECT `+listColumns+`
+		FROM lists
+		WHERE id=$1 AND deleted_at IS NULL
+		`,
+		sqliteArgs(id)...,
+	)
+
+	l, err = scanSQLiteList(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return todos.List{}, ErrListNotFound
+	}
+
+	if err != nil {
+		return todos.List{}, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return l, nil
+}
+
+func (r SQLiteRepository) CreateList(ctx context.Context, list todos.List) (createdList todos.List, err error) {
+	row := r.db.QueryRowContext(ctx,
+		`
+		INSERT INTO lists (id, name, color, archived, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING `+listColumns,
+		sqliteArgs(uuid.New(), list.Name, list.Color, list.Archived, list.CreatedAt)...,
+	)
+
+	createdList, err = scanSQLiteList(row)
+	if err != nil {
+		return todos.List{}, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return createdList, nil
+}
+
+func (r SQLiteRepository) SaveList(ctx context.Context, list todos.List) (savedList todos.List, err error) {
+	row := r.db.QueryRowContext(ctx,
+		`
+		UPDATE lists
+		SET name = $2, color = $3, archived = $4, updated_at = $5
+		WHERE id=$1 AND deleted_at IS NULL
+		RETURNING `+listColumns,
+		sqliteArgs(list.ID, list.Name, list.Color, list.Archived, list.UpdatedAt)...,
+	)
+
+	savedList, err = scanSQLiteList(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return todos.List{}, ErrListNotFound
+	}
+
+	if err != nil {
+		return todos.List{}, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return savedList, nil
+}
+
+// DeleteList marks the list and all of its todos including their subtasks as deleted.
+func (r SQLiteRepository) DeleteList(ctx context.Context, id uuid.UUID, deletedAt time.Time) error {
+	return inSQLiteTransaction(ctx, r.db, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx,
+			`
+			UPDATE lists
+			SET deleted_at = $2
+			WHERE id=$1 AND deleted_at IS NULL
+			`,
+			sqliteArgs(id, deletedAt)...,
+		)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrDatabase, err)
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrDatabase, err)
+		}
+
+		if affected == 0 {
+			return ErrListNotFound
+		}
+
+		rows, err := tx.QueryContext(ctx,
+			`
+			WITH RECURSIVE deleted AS (
+				SELECT id FROM todos WHERE list_id=$1 AND deleted_at IS NULL
+				UNION
+				SELECT todos.id FROM todos JOIN deleted ON todos.parent_id = deleted.id
+				WHERE todos.deleted_at IS NULL
+			)
+			SELECT `+sqliteTodoColumns+`
+			FROM todos
+			WHERE id IN (SELECT id FROM deleted)
+			ORDER BY id
+			`,
+			sqliteArgs(id)...,
+		)
+		if err != nil {
+			return fmt.Errorf("failed querying database: %w", err)
+		}
+
+		deletedTodos, err := collectSQLiteTodos(rows)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrDatabase, err)
+		}
+
+		return deleteSQLiteTodos(ctx, tx, deletedTodos, deletedAt)
+	})
+}
+
+// GetComments returns comments of the todo in the order they were created.
+func (r SQLiteRepository) GetComments(ctx context.Context, todoID uuid.UUID) (c []todos.Comment, err error) {
+	_, err = getSQLiteTodo(ctx, r.db, todoID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`
+		SELECT `+commentColumns+`
+		FROM comments
+		WHERE todo_id=$1 AND deleted_at IS NULL
+		ORDER BY created_at, id
+		`,
+		sqliteArgs(todoID)...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed querying database: %w", err)
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	// Use append to avoid returning nil slice
+	c = make([]todos.Comment, 0)
+
+	for rows.Next() {
+		comment, err := scanSQLiteComment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+		}
+
+		c = append(c, comment)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return c, nil
+}
+
+func (r SQLiteRepository) CreateComment(
+	ctx context.Context,
+	comment todos.Comment,
+) (createdComment todos.Comment, err error) {
+	err = inSQLiteTransaction(ctx, r.db, func(tx *sql.Tx) error {
+		_, err = getSQLiteTodo(ctx, tx, comment.TodoID)
+		if err != nil {
+			return err
+		}
+
+		row := tx.QueryRowContext(ctx,
+			`
+			INSERT INTO comments (id, todo_id, author, body, created_at)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING `+commentColumns,
+			sqliteArgs(uuid.New(), comment.TodoID, comment.Author, comment.Body, comment.CreatedAt)...,
+		)
+
+		createdComment, err = scanSQLiteComment(row)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrDatabase, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return todos.Comment{}, err
+	}
+
+	return createdComment, nil
+}
+
+// SaveComment changes body of the comment. The author of the comment cannot be changed.
+func (r SQLiteRepository) SaveComment(
+	ctx context.Context,
+	comment todos.Comment,
+) (savedComment todos.Comment, err error) {
+	err = inSQLiteTransaction(ctx, r.db, func(tx *sql.Tx) error {
+		_, err = getSQLiteTodo(ctx, tx, comment.TodoID)
+		if err != nil {
+			return err
+		}
+
+		row := tx.QueryRowContext(ctx,
+			`
+			UPDATE comments
+			SET body = $3, updated_at = $4
+			WHERE id=$1 AND todo_id=$2 AND deleted_at IS NULL
+			RETURNING `+commentColumns,
+			sqliteArgs(comment.ID, comment.TodoID, comment.Body, comment.UpdatedAt)...,
+		)
+
+		savedComment, err = scanSQLiteComment(row)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrCommentNotFound
+		}
+
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrDatabase, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return todos.Comment{}, err
+	}
+
+	return savedComment, nil
+}
+
+// DeleteComment marks the comment as deleted.
+func (r SQLiteRepository) DeleteComment(
+	ctx context.Context,
+	todoID uuid.UUID,
+	id uuid.UUID,
+	deletedAt time.Time,
+) error {
+	return inSQLiteTransaction(ctx, r.db, func(tx *sql.Tx) error {
+		_, err := getSQLiteTodo(ctx, tx, todoID)
+		if err != nil {
+			return err
+		}
+
+		result, err := tx.ExecContext(ctx,
+			`
+			UPDATE comments
+			SET deleted_at = $3
+			WHERE id=$1 AND todo_id=$2 AND deleted_at IS NULL
+			`,
+			sqliteArgs(id, todoID, deletedAt)...,
+		)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrDatabase, err)
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrDatabase, err)
+		}
+
+		if affected == 0 {
+			return ErrCommentNotFound
+		}
+
+		return nil
+	})
+}
+
+// GetAttachments returns attachments of the todo in the order they were created.
+func (r SQLiteRepository) GetAttachments(ctx context.Context, todoID uuid.UUID) (a []todos.Attachment, err error) {
+	_, err = getSQLiteTodo(ctx, r.db, todoID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`
+		SELECT `+attachmentColumns+`
+		FROM attachments
+		WHERE todo_id=$1
+		ORDER BY created_at, id
+		`,
+		sqliteArgs(todoID)...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed querying database: %w", err)
+	}
+
+	a, err = collectSQLiteAttachments(rows)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return a, nil
+}
+
+func (r SQLiteRepository) GetAttachment(
+	ctx context.Context,
+	todoID uuid.UUID,
+	id uuid.UUID,
+) (attachment todos.Attachment, err error) {
+	_, err = getSQLiteTodo(ctx, r.db, todoID)
+	if err != nil {
+		return todos.Attachment{}, err
+	}
+
+	row := r.db.QueryRowContext(ctx,
+		`
+		SELECT `+attachmentColumns+`
+		FROM attachments
+		WHERE id=$1 AND todo_id=$2
+		`,
+		sqliteArgs(id, todoID)...,
+	)
+
+	attachment, err = scanSQLiteAttachment(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return todos.Attachment{}, ErrAttachmentNotFound
+	}
+
+	if err != nil {
+		return todos.Attachment{}, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return attachment, nil
+}
+
+func (r SQLiteRepository) CreateAttachment(
+	ctx context.Context,
+	attachment todos.Attachment,
+) (createdAttachment todos.Attachment, err error) {
+	err = inSQLiteTransaction(ctx, r.db, func(tx *sql.Tx) error {
+		_, err = getSQLiteTodo(ctx, tx, attachment.TodoID)
+		if err != nil {
+			return err
+		}
+
+		row := tx.QueryRowContext(ctx,
+			`
+			INSERT INTO attachments (id, todo_id, name, content_type, size, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING `+attachmentColumns,
+			sqliteArgs(
+				uuid.New(),
+				attachment.TodoID,
+				attachment.Name,
+				attachment.ContentType,
+				attachment.Size,
+				attachment.CreatedAt,
+			)...,
+		)
+
+		createdAttachment, err = scanSQLiteAttachment(row)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrDatabase, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return todos.Attachment{}, err
+	}
+
+	return createdAttachment, nil
+}
+
+// DeleteAttachment removes the attachment.
+func (r SQLiteRepository) DeleteAttachment(ctx context.Context, todoID uuid.UUID, id uuid.UUID) error {
+	return inSQLiteTransaction(ctx, r.db, func(tx *sql.Tx) error {
+		_, err := getSQLiteTodo(ctx, tx, todoID)
+		if err != nil {
+			return err
+		}
+
+		result, err := tx.ExecContext(ctx,
+			`
+			DELETE FROM attachments
+			WHERE id=$1 AND todo_id=$2
+			`,
+			sqliteArgs(id, todoID)...,
+		)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrDatabase, err)
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrDatabase, err)
+		}
+
+		if affected == 0 {
+			return ErrAttachmentNotFound
+		}
+
+		return nil
+	})
+}
+
+// GetTodoEvents returns history of the todo, the oldest event first.
+// History of deleted todos is kept until they are purged.
+func (r SQLiteRepository) GetTodoEvents(ctx context.Context, todoID uuid.UUID) (e []todos.Event, err error) {
+	var exists bool
+
+	err = r.db.QueryRowContext(ctx,
+		`
+		SELECT EXISTS (SELECT 1 FROM todos WHERE id=$1)
+		`,
+		sqliteArgs(todoID)...,
+	).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	if !exists {
+		return nil, ErrTodoNotFound
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`
+		SELECT `+eventColumns+`
+		FROM todo_events
+		WHERE todo_id=$1
+		ORDER BY occurred_at, rowid
+		`,
+		sqliteArgs(todoID)...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed querying database: %w", err)
+	}
+
+	e, err = collectSQLiteEvents(rows)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return e, nil
+}
+
+// GetTodoVersions returns versions of the todo, the oldest one first and the current one last.
+// Versions of deleted todos are kept until they are purged.
+func (r SQLiteRepository) GetTodoVersions(ctx context.Context, todoID uuid.UUID) (v []todos.Version, err error) {
+	rows, err := r.db.QueryContext(ctx,
+		`
+		SELECT `+versionColumns+`
+		FROM todo_versions
+		WHERE todo_id=$1
+		ORDER BY version
+		`,
+		sqliteArgs(todoID)...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed querying database: %w", err)
+	}
+
+	v, err = collectSQLiteVersions(rows)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	row := r.db.QueryRowContext(ctx,
+		`
+		SELECT `+sqliteTodoColumns+`
+		FROM todos
+		WHERE id=$1
+		`,
+		sqliteArgs(todoID)...,
+	)
+
+	todo, err := scanChangedSQLiteTodo(row)
+	if err != nil {
+		return nil, err
+	}
+
+	return completeVersions(v, todo), nil
+}
+
+// GetAPIKeys returns all keys including the revoked ones in the order they were created.
+func (r SQLiteRepository) GetAPIKeys(ctx context.Context) (k []auth.Key, err error) {
+	rows, err := r.db.QueryContext(ctx,
+		`
+		SELECT `+apiKeyColumns+`
+		FROM api_keys
+		ORDER BY created_at, id
+		`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed querying database: %w", err)
+	}
+
+	k, err = collectSQLiteAPIKeys(rows)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return k, nil
+}
+
+func (r SQLiteRepository) CreateAPIKey(ctx context.Context, key auth.Key) (createdKey auth.Key, err error) {
+	scopes, err := json.Marshal(key.Scopes)
+	if err != nil {
+		return auth.Key{}, fmt.Errorf("failed encoding scopes: %w", err)
+	}
+
+	row := r.db.QueryRowContext(ctx,
+		`
+		INSERT INTO api_keys (id, name, hash, scopes, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING `+apiKeyColumns,
+		sqliteArgs(uuid.New(), key.Name, key.Hash, string(scopes), key.ExpiresAt, key.CreatedAt)...,
+	)
+
+	createdKey, err = scanSQLiteAPIKey(row)
+	if err != nil {
+		return auth.Key{}, fmt.Errorf("failed creating api key: %w: %w", ErrDatabase, err)
+	}
+
+	return createdKey, nil
+}
+
+// RevokeAPIKey marks the key as revoked so that it can no longer be used.
+func (r SQLiteRepository) RevokeAPIKey(ctx context.Context, id uuid.UUID, revokedAt time.Time) error {
+	result, err := r.db.ExecContext(ctx,
+		`
+		UPDATE api_keys
+		SET revoked_at = $2
+		WHERE id=$1 AND revoked_at IS NULL
+		`,
+		sqliteArgs(id, revokedAt)...,
+	)
+	if err != nil {
+		return fmt.Errorf("failed revoking api key: %w: %w", ErrDatabase, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	if affected == 0 {
+		return ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+// UseAPIKey returns the key with the given hash and records it was used.
+// Keys which have expired or were revoked are treated as not existing.
+func (r SQLiteRepository) UseAPIKey(ctx context.Context, hash string, usedAt time.Time) (key auth.Key, err error) {
+	row := r.db.QueryRowContext(ctx,
+		`
+		UPDATE api_keys
+		SET last_used_at = $2
+		WHERE hash=$1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > $2)
+		RETURNING `+apiKeyColumns,
+		sqliteArgs(hash, usedAt)...,
+	)
+
+	key, err = scanSQLiteAPIKey(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return auth.Key{}, ErrInvalidAPIKey
+	}
+
+	if err != nil {
+		return auth.Key{}, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return key, nil
+}
+
+// GetSubtasks returns subtasks of the todo in their order.
+func (r SQLiteRepository) GetSubtasks(ctx context.Context, id uuid.UUID) (t []todos.Todo, err error) {
+	return getSQLiteSubtasks(ctx, r.db, id)
+}
+
+// ReorderSubtasks works like [Repository.ReorderSubtasks].
+func (r SQLiteRepository) ReorderSubtasks(
+	ctx context.Context,
+	id uuid.UUID,
+	order []uuid.UUID,
+	reorderedAt time.Time,
+) (subtasks []todos.Todo, err error) {
+	err = inSQLiteTransaction(ctx, r.db, func(tx *sql.Tx) error {
+		subtasks, err = getSQLiteSubtasks(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if !matchesSubtasks(subtasks, order) {
+			return ErrSubtasksMismatch
+		}
+
+		_, err = tx.ExecContext(ctx,
+			`
+			UPDATE todos
+			SET updated_at = $2, version = version + 1
+			WHERE id=$1
+			`,
+			sqliteArgs(id, reorderedAt)...,
+		)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrDatabase, err)
+		}
+
+		for i, subtaskID := range order {
+			_, err = tx.ExecContext(ctx,
+				`
+				UPDATE todos
+				SET subtask_order = $2, updated_at = $3, version = version + 1
+				WHERE id=$1
+				`,
+				sqliteArgs(subtaskID, i, reorderedAt)...,
+			)
+			if err != nil {
+				return fmt.Errorf("%w: %w", ErrDatabase, err)
+			}
+		}
+
+		subtasks, err = getSQLiteSubtasks(ctx, tx, id)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return subtasks, nil
+}
+
+// ExecuteBatch executes the operations in a single transaction
+// the same way as [Repository.ExecuteBatch] does.
+func (r SQLiteRepository) ExecuteBatch(
+	ctx context.Context,
+	operations []BatchOperation,
+	atomic bool,
+	now time.Time,
+) (results []BatchResult, err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	results = make([]BatchResult, len(operations))
+	for i, operation := range operations {
+		// Each operation runs in its own savepoint so it can be rolled back alone.
+		_, err = tx.ExecContext(ctx, "SAVEPOINT batch_operation")
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+		}
+
+		results[i] = executeSQLiteOperation(ctx, tx, operation, now)
+		if results[i].Err != nil {
+			_, _ = tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT batch_operation")
+
+			if atomic {
+				return abortBatch(results, i), nil
+			}
+		}
+
+		_, err = tx.ExecContext(ctx, "RELEASE SAVEPOINT batch_operation")
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return results, nil
+}
+
+func executeSQLiteOperation(ctx context.Context, tx *sql.Tx, operation BatchOperation, now time.Time) BatchResult {
+	var (
+		todo todos.Todo
+		err  error
+	)
+
+	switch operation.Kind {
+	case BatchCreate:
+		operation.Todo.CreatedAt = now
+		todo, err = createSQLiteTodo(ctx, tx, operation.Todo)
+	case BatchUpdate:
+		operation.Todo.UpdatedAt = &now
+		todo, err = saveSQLiteTodo(ctx, tx, operation.Todo)
+	case BatchDelete:
+		todo = todos.Todo{ID: operation.Todo.ID}
+		err = deleteSQLiteTodo(ctx, tx, operation.Todo.ID, operation.Todo.Version, now)
+	default:
+		err = fmt.Errorf("%w: %q", ErrUnknownBatchOperation, operation.Kind)
+	}
+
+	return BatchResult{
+		Todo: todo,
+		Err:  err,
+	}
+}
+
+func getSQLiteSubtasks(ctx context.Context, q sqliteQuerier, id uuid.UUID) (t []todos.Todo, err error) {
+	_, err = getSQLiteTodo(ctx, q, id)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := q.QueryContext(ctx,
+		`
+		SELECT `+sqliteTodoColumns+`
+		FROM todos
+		WHERE parent_id=$1 AND deleted_at IS NULL
+		ORDER BY subtask_order, created_at, id
+		`,
+		sqliteArgs(id)...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed querying database: %w", err)
+	}
+
+	t, err = collectSQLiteTodos(rows)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return t, nil
+}
+
+func getSQLiteTodo(ctx context.Context, q sqliteQuerier, id uuid.UUID) (t todos.Todo, err error) {
+	row := q.QueryRowContext(ctx,
+		`
+		SELECT `+sqliteTodoColumns+`
+		FROM todos
+		WHERE id=$1 AND deleted_at IS NULL
+		`,
+		sqliteArgs(id)...,
+	)
+
+	t, err = scanSQLiteTodo(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return todos.Todo{}, ErrTodoNotFound
+	}
+
+	if err != nil {
+		return todos.Todo{}, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return t, nil
+}
+
+// getDeletedSQLiteTodo returns the todo if it is deleted or [ErrTodoNotFound] otherwise.
+func getDeletedSQLiteTodo(ctx context.Context, q sqliteQuerier, id uuid.UUID) (todos.Todo, error) {
+	row := q.QueryRowContext(ctx,
+		`
+		SELECT `+sqliteTodoColumns+`
+		FROM todos
+		WHERE id=$1 AND deleted_at IS NOT NULL
+		`,
+		sqliteArgs(id)...,
+	)
+
+	return scanChangedSQLiteTodo(row)
+}
+
+// createSQLiteTodo inserts the todo and its tags. The querier should be a transaction
+// so that the todo is not created without its tags. Subtasks are ordered after the existing ones.
+func createSQLiteTodo(ctx context.Context, q sqliteQuerier, todo todos.Todo) (createdTodo todos.Todo, err error) {
+	err = checkSQLiteList(ctx, q, todo.ListID)
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	err = checkSQLiteParent(ctx, q, uuid.Nil, todo.ParentID)
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	position, err := appendSQLitePosition(ctx, q, todo.CreatedAt)
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	id := uuid.New()
+
+	_, err = q.ExecContext(ctx,
+		`
+		INSERT INTO todos (
+			id, description, list_id, parent_id, subtask_order, priority, due_at, recurrence, position, created_at
+		)
+		VALUES (
+			$1, $2, $3, $4, (SELECT COALESCE(MAX(subtask_order) + 1, 0) FROM todos WHERE parent_id = $4), $5, $6, $7, $8, $9
+		)
+		`,
+		sqliteArgs(
+			id,
+			todo.Description,
+			todo.ListID,
+			todo.ParentID,
+			todo.Priority,
+			todo.DueAt,
+			todo.Recurrence,
+			position,
+			todo.CreatedAt,
+		)...,
+	)
+	if err != nil {
+		return todos.Todo{}, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	err = setSQLiteTodoTags(ctx, q, id, normalizeTags(todo.Tags))
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	createdTodo, err = getSQLiteTodo(ctx, q, id)
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	event := newEvent(ctx, todos.EventCreated, nil, &createdTodo, todo.CreatedAt)
+
+	err = recordSQLiteChange(ctx, q, event, createdTodo)
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	return createdTodo, nil
+}
+
+// saveSQLiteTodo updates the todo and its tags. The querier should be a transaction
+// so that the todo is not updated without its tags. Re-parented todos are ordered after the existing subtasks.
+func saveSQLiteTodo(ctx context.Context, q sqliteQuerier, todo todos.Todo) (savedTodo todos.Todo, err error) {
+	previousTodo, err := getSQLiteTodo(ctx, q, todo.ID)
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	result, err := q.ExecContext(ctx,
+		`
+		UPDATE todos
+		SET description = $2, list_id = $3, priority = $4, due_at = $5, completed_at = $6, updated_at = $7,
+			version = version + 1,
+			subtask_order = CASE WHEN parent_id IS $9 THEN subtask_order ELSE
+				(SELECT COALESCE(MAX(subtasks.subtask_order) + 1, 0) FROM todos AS subtasks WHERE subtasks.parent_id = $9)
+			END,
+			parent_id = $9,
+			recurrence = $10
+		WHERE id=$1 AND deleted_at IS NULL AND ($8 = 0 OR version = $8)
+		`,
+		sqliteArgs(
+			todo.ID,
+			todo.Description,
+			todo.ListID,
+			todo.Priority,
+			todo.DueAt,
+			todo.CompletedAt,
+			todo.UpdatedAt,
+			todo.Version,
+			todo.ParentID,
+			todo.Recurrence,
+		)...,
+	)
+	if err != nil {
+		return todos.Todo{}, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return todos.Todo{}, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	if affected == 0 {
+		return todos.Todo{}, missingSQLiteTodoError(ctx, q, todo.ID, todo.Version)
+	}
+
+	err = checkSQLiteList(ctx, q, todo.ListID)
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	err = checkSQLiteParent(ctx, q, todo.ID, todo.ParentID)
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	err = setSQLiteTodoTags(ctx, q, todo.ID, normalizeTags(todo.Tags))
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	savedTodo, err = getSQLiteTodo(ctx, q, todo.ID)
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	err = recordSQLiteSave(ctx, q, previousTodo, savedTodo, changedAt(todo.UpdatedAt))
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	return savedTodo, nil
+}
+
+// recordSQLiteSave records the update of the todo and creates its next occurrence if the update completed it.
+func recordSQLiteSave(
+	ctx context.Context,
+	q sqliteQuerier,
+	previousTodo, savedTodo todos.Todo,
+	savedAt time.Time,
+) error {
+	event := newEvent(ctx, todos.EventUpdated, &previousTodo, &savedTodo, savedAt)
+
+	err := recordSQLiteChange(ctx, q, event, previousTodo, savedTodo)
+	if err != nil {
+		return err
+	}
+
+	return createNextSQLiteOccurrence(ctx, q, previousTodo, savedTodo)
+}
+
+// completeSQLiteTodo marks todo as completed and creates its next occurrence if it recurs.
+// The querier should be a transaction so that the todo is not completed without its next occurrence.
+func completeSQLiteTodo(ctx context.Context, q sqliteQuerier, id uuid.UUID, completedAt time.Time) (todos.Todo, error) {
+	previousTodo, err := getSQLiteTodo(ctx, q, id)
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	row := q.QueryRowContext(ctx,
+		`
+		UPDATE todos
+		SET completed_at = $2, updated_at = $2, version = version + 1
+		WHERE id=$1 AND deleted_at IS NULL AND completed_at IS NULL
+		RETURNING `+sqliteTodoColumns,
+		sqliteArgs(id, completedAt)...,
+	)
+
+	completedTodo, err := scanSQLiteTodo(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		// The todo is already completed.
+		return previousTodo, nil
+	}
+
+	if err != nil {
+		return todos.Todo{}, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	event := newEvent(ctx, todos.EventCompleted, &previousTodo, &completedTodo, completedAt)
+
+	err = recordSQLiteChange(ctx, q, event, previousTodo, completedTodo)
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	err = createNextSQLiteOccurrence(ctx, q, previousTodo, completedTodo)
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	return completedTodo, nil
+}
+
+// createNextSQLiteOccurrence creates the next occurrence of the recurring todo like [createNextOccurrence].
+func createNextSQLiteOccurrence(ctx context.Context, q sqliteQuerier, previousTodo, todo todos.Todo) error {
+	if previousTodo.CompletedAt != nil || todo.CompletedAt == nil {
+		return nil
+	}
+
+	next, ok := todo.NextOccurrence(*todo.CompletedAt)
+	if !ok {
+		return nil
+	}
+
+	_, err := createSQLiteTodo(ctx, q, next)
+
+	return err
+}
+
+func moveSQLiteTodo(
+	ctx context.Context,
+	q sqliteQuerier,
+	id uuid.UUID,
+	move TodoMove,
+	movedAt time.Time,
+) (todos.Todo, error) {
+	previousTodo, err := getSQLiteTodo(ctx, q, id)
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	position, err := balancedSQLitePosition(ctx, q, movedAt, func() (string, error) {
+		return moveSQLitePosition(ctx, q, id, move)
+	})
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	_, err = q.ExecContext(ctx,
+		`
+		UPDATE todos
+		SET position = $2, updated_at = $3, version = version + 1
+		WHERE id=$1
+		`,
+		sqliteArgs(id, position, movedAt)...,
+	)
+	if err != nil {
+		return todos.Todo{}, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	movedTodo, err := getSQLiteTodo(ctx, q, id)
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	event := newEvent(ctx, todos.EventMoved, &previousTodo, &movedTodo, movedAt)
+
+	err = recordSQLiteChange(ctx, q, event, previousTodo, movedTodo)
+	if err != nil {
+		return todos.Todo{}, err
+	}
+
+	return movedTodo, nil
+}
+
+// moveSQLitePosition finds position between the neighbors of the moved todo.
+func moveSQLitePosition(
+	ctx context.Context,
+	q sqliteQuerier,
+	id uuid.UUID,
+	move TodoMove,
+) (position string, err error) {
+	err = checkMove(id, move)
+	if err != nil {
+		return "", err
+	}
+
+	var lower, upper string
+
+	if move.After != nil {
+		lower, err = sqliteNeighborPosition(ctx, q, *move.After)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if move.Before != nil {
+		upper, err = sqliteNeighborPosition(ctx, q, *move.Before)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	switch {
+	case move.After == nil:
+		err = q.QueryRowContext(ctx,
+			"SELECT COALESCE(MAX(position), '') FROM todos WHERE position < $1 AND id <> $2 AND deleted_at IS NULL",
+			sqliteArgs(upper, id)...,
+		).Scan(&lower)
+	case move.Before == nil:
+		err = q.QueryRowContext(ctx,
+			"SELECT COALESCE(MIN(position), '') FROM todos WHERE position > $1 AND id <> $2 AND deleted_at IS NULL",
+			sqliteArgs(lower, id)...,
+		).Scan(&upper)
+	case lower >= upper:
+		return "", ErrInvalidMove
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return positionBetween(lower, upper), nil
+}
+
+// appendSQLitePosition returns position following all todos.
+func appendSQLitePosition(ctx context.Context, q sqliteQuerier, appendedAt time.Time) (position string, err error) {
+	return balancedSQLitePosition(ctx, q, appendedAt, func() (string, error) {
+		var last string
+
+		err := q.QueryRowContext(ctx, "SELECT COALESCE(MAX(position), '') FROM todos WHERE deleted_at IS NULL").
+			Scan(&last)
+		if err != nil {
+			return "", fmt.Errorf("%w: %w", ErrDatabase, err)
+		}
+
+		return positionAfter(last), nil
+	})
+}
+
+// balancedSQLitePosition works like [balancedPosition]. No lock is needed
+// as SQLite serialises the transactions writing to the database.
+func balancedSQLitePosition(
+	ctx context.Context,
+	q sqliteQuerier,
+	balancedAt time.Time,
+	find func() (string, error),
+) (position string, err error) {
+	position, err = find()
+	if err != nil || len(position) <= maxPositionLength {
+		return position, err
+	}
+
+	rows, err := q.QueryContext(ctx, "SELECT id FROM todos WHERE deleted_at IS NULL ORDER BY position, id")
+	if err != nil {
+		return "", fmt.Errorf("failed querying database: %w", err)
+	}
+
+	ids, err := collectSQLiteIDs(rows)
+	if err != nil {
+		return "", err
+	}
+
+	for i, balanced := range balancedPositions(len(ids)) {
+		_, err = q.ExecContext(ctx,
+			`
+			UPDATE todos
+			SET position = $2, updated_at = $3, version = version + 1
+			WHERE id=$1 AND position <> $2
+			`,
+			sqliteArgs(ids[i], balanced, balancedAt)...,
+		)
+		if err != nil {
+			return "", fmt.Errorf("%w: %w", ErrDatabase, err)
+		}
+	}
+
+	return find()
+}
+
+// restoredSQLitePosition works like [restoredPosition].
+func restoredSQLitePosition(
+	ctx context.Context,
+	q sqliteQuerier,
+	todo todos.Todo,
+	restoredAt time.Time,
+) (string, error) {
+	var taken bool
+
+	err := q.QueryRowContext(ctx,
+		"SELECT EXISTS (SELECT 1 FROM todos WHERE position=$1 AND id <> $2 AND deleted_at IS NULL)",
+		sqliteArgs(todo.Position, todo.ID)...,
+	).Scan(&taken)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	if !taken {
+		return todo.Position, nil
+	}
+
+	return appendSQLitePosition(ctx, q, restoredAt)
+}
+
+func sqliteNeighborPosition(ctx context.Context, q sqliteQuerier, id uuid.UUID) (position string, err error) {
+	err = q.QueryRowContext(ctx,
+		"SELECT position FROM todos WHERE id=$1 AND deleted_at IS NULL",
+		sqliteArgs(id)...,
+	).Scan(&position)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrNeighborNotFound
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return position, nil
+}
+
+func deleteSQLiteTodo(ctx context.Context, q sqliteQuerier, id uuid.UUID, version int, deletedAt time.Time) error {
+	previousTodo, err := getSQLiteTodo(ctx, q, id)
+	if err != nil {
+		return err
+	}
+
+	row := q.QueryRowContext(ctx,
+		`
+		UPDATE todos
+		SET deleted_at = $2, version = version + 1
+		WHERE id=$1 AND deleted_at IS NULL AND ($3 = 0 OR version = $3)
+		RETURNING `+sqliteTodoColumns,
+		sqliteArgs(id, deletedAt, version)...,
+	)
+
+	deletedTodo, err := scanSQLiteTodo(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return missingSQLiteTodoError(ctx, q, id, version)
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	event := newEvent(ctx, todos.EventDeleted, &previousTodo, nil, deletedAt)
+
+	err = recordSQLiteChange(ctx, q, event, previousTodo, deletedTodo)
+	if err != nil {
+		return err
+	}
+
+	rows, err := q.QueryContext(ctx,
+		`
+		WITH RECURSIVE subtasks AS (
+			SELECT id FROM todos WHERE parent_id=$1 AND deleted_at IS NULL
+			UNION
+			SELECT todos.id FROM todos JOIN subtasks ON todos.parent_id = subtasks.id
+			WHERE todos.deleted_at IS NULL
+		)
+		SELECT `+sqliteTodoColumns+`
+		FROM todos
+		WHERE id IN (SELECT id FROM subtasks)
+		ORDER BY id
+		`,
+		sqliteArgs(id)...,
+	)
+	if err != nil {
+		return fmt.Errorf("failed querying database: %w", err)
+	}
+
+	subtasks, err := collectSQLiteTodos(rows)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return deleteSQLiteTodos(ctx, q, subtasks, deletedAt)
+}
+
+// deleteSQLiteTodos marks the todos deleted together with their parent or list as deleted.
+// The todos are in the state before the deletion which is recorded for each of them.
+func deleteSQLiteTodos(ctx context.Context, q sqliteQuerier, previousTodos []todos.Todo, deletedAt time.Time) error {
+	for _, previousTodo := range previousTodos {
+		row := q.QueryRowContext(ctx,
+			`
+			UPDATE todos
+			SET deleted_at = $2, version = version + 1
+			WHERE id=$1
+			RETURNING `+sqliteTodoColumns,
+			sqliteArgs(previousTodo.ID, deletedAt)...,
+		)
+
+		deletedTodo, err := scanChangedSQLiteTodo(row)
+		if err != nil {
+			return err
+		}
+
+		event := newEvent(ctx, todos.EventDeleted, &previousTodo, nil, deletedAt)
+
+		err = recordSQLiteChange(ctx, q, event, previousTodo, deletedTodo)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// recordSQLiteEvent stores the event. The querier should be the transaction
+// changing the todo so that the change is never made without its event.
+func recordSQLiteEvent(ctx context.Context, q sqliteQuerier, event todos.Event) error {
+	_, err := q.ExecContext(ctx,
+		`
+		INSERT INTO todo_events (id, todo_id, type, actor, before, after, occurred_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`,
+		sqliteArgs(
+			event.ID,
+			event.TodoID,
+			event.Type,
+			event.Actor,
+			event.Before,
+			event.After,
+			event.OccurredAt,
+		)...,
+	)
+	if err != nil {
+		return fmt.Errorf("failed recording event: %w: %w", ErrDatabase, err)
+	}
+
+	return nil
+}
+
+// recordSQLiteChange records the event together with the versions of the changed todo.
+func recordSQLiteChange(ctx context.Context, q sqliteQuerier, event todos.Event, versions ...todos.Todo) error {
+	err := recordSQLiteEvent(ctx, q, event)
+	if err != nil {
+		return err
+	}
+
+	return recordSQLiteVersions(ctx, q, versions...)
+}
+
+// recordSQLiteVersions stores the todos as versions like [recordVersions].
+func recordSQLiteVersions(ctx context.Context, q sqliteQuerier, versions ...todos.Todo) error {
+	for _, todo := range versions {
+		_, err := q.ExecContext(ctx,
+			`
+			INSERT INTO todo_versions (todo_id, version, todo, valid_from)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (todo_id, version) DO NOTHING
+			`,
+			sqliteArgs(todo.ID, todo.Version, &todo, validFrom(todo))...,
+		)
+		if err != nil {
+			return fmt.Errorf("failed recording version: %w: %w", ErrDatabase, err)
+		}
+	}
+
+	return nil
+}
+
+// setSQLiteTodoTags replaces tags of the todo creating the tags which do not exist yet.
+func setSQLiteTodoTags(ctx context.Context, q sqliteQuerier, id uuid.UUID, names []string) error {
+	_, err := q.ExecContext(ctx,
+		`
+		DELETE FROM todo_tags
+		WHERE todo_id=$1
+		`,
+		sqliteArgs(id)...,
+	)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	for _, name := range names {
+		_, err = q.ExecContext(ctx,
+			`
+			INSERT INTO tags (id, name)
+			VALUES ($1, $2)
+			ON CONFLICT (name) DO NOTHING
+			`,
+			sqliteArgs(uuid.New(), name)...,
+		)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrDatabase, err)
+		}
+
+		_, err = q.ExecContext(ctx,
+			`
+			INSERT INTO todo_tags (todo_id, tag_id)
+			SELECT $1, id
+			FROM tags
+			WHERE name = $2
+			`,
+			sqliteArgs(id, name)...,
+		)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrDatabase, err)
+		}
+	}
+
+	return nil
+}
+
+// checkSQLiteList verifies that the optional list of a todo exists.
+func checkSQLiteList(ctx context.Context, q sqliteQuerier, id *uuid.UUID) error {
+	if id == nil {
+		return nil
+	}
+
+	var exists bool
+
+	err := q.QueryRowContext(ctx,
+		`
+		SELECT EXISTS (SELECT 1 FROM lists WHERE id=$1 AND deleted_at IS NULL)
+		`,
+		sqliteArgs(id)...,
+	).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	if !exists {
+		return ErrListNotFound
+	}
+
+	return nil
+}
+
+// checkSQLiteParent verifies that the optional parent of a todo exists
+// and that the todo is not one of its ancestors.
+func checkSQLiteParent(ctx context.Context, q sqliteQuerier, id uuid.UUID, parentID *uuid.UUID) error {
+	if parentID == nil {
+		return nil
+	}
+
+	var exists, cycle bool
+
+	err := q.QueryRowContext(ctx, ancestorsQuery, sqliteArgs(parentID, id)...).Scan(&exists, &cycle)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return parentError(exists, cycle)
+}
+
+// missingSQLiteTodoError finds out why conditional change of todo did not affect any rows.
+func missingSQLiteTodoError(ctx context.Context, q sqliteQuerier, id uuid.UUID, version int) error {
+	if version == 0 {
+		return ErrTodoNotFound
+	}
+
+	var exists bool
+
+	err := q.QueryRowContext(ctx,
+		`
+		SELECT EXISTS (SELECT 1 FROM todos WHERE id=$1 AND deleted_at IS NULL)
+		`,
+		sqliteArgs(id)...,
+	).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	if !exists {
+		return ErrTodoNotFound
+	}
+
+	return ErrVersionConflict
+}
+
+// sqliteScanner scans either a single row or the current row of rows.
+type sqliteScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanSQLiteTodo scans columns listed by sqliteTodoColumns.
+func scanSQLiteTodo(row sqliteScanner, extra ...any) (todo todos.Todo, err error) {
+	dest := append([]any{
+		&todo.ID,
+		&todo.Description,
+		&todo.ListID,
+		&todo.ParentID,
+		&todo.SubtaskOrder,
+		&todo.Priority,
+		sqliteNullTime{&todo.DueAt},
+		&todo.Recurrence,
+		&todo.Position,
+		sqliteNullTime{&todo.CompletedAt},
+		sqliteTime{&todo.CreatedAt},
+		sqliteNullTime{&todo.UpdatedAt},
+		sqliteNullTime{&todo.DeletedAt},
+		&todo.Version,
+		sqliteTags{&todo.Tags},
+		sqliteProgress{&todo.Progress},
+	}, extra...)
+
+	err = row.Scan(dest...)
+	if err != nil {
+		return todos.Todo{}, fmt.Errorf("failed scanning todo: %w", err)
+	}
+
+	return todo, nil
+}
+
+// scanSQLiteList scans columns listed by listColumns.
+func scanSQLiteList(row sqliteScanner) (list todos.List, err error) {
+	err = row.Scan(
+		&list.ID,
+		&list.Name,
+		&list.Color,
+		&list.Archived,
+		sqliteTime{&list.CreatedAt},
+		sqliteNullTime{&list.UpdatedAt},
+	)
+	if err != nil {
+		return todos.List{}, fmt.Errorf("failed scanning list: %w", err)
+	}
+
+	return list, nil
+}
+
+func scanSQLiteComment(row sqliteScanner) (comment todos.Comment, err error) {
+	err = row.Scan(
+		&comment.ID,
+		&comment.TodoID,
+		&comment.Author,
+		&comment.Body,
+		sqliteTime{&comment.CreatedAt},
+		sqliteNullTime{&comment.UpdatedAt},
+	)
+	if err != nil {
+		return todos.Comment{}, fmt.Errorf("failed scanning comment: %w", err)
+	}
+
+	return comment, nil
+}
+
+func scanSQLiteAttachment(row sqliteScanner) (attachment todos.Attachment, err error) {
+	err = row.Scan(
+		&attachment.ID,
+		&attachment.TodoID,
+		&attachment.Name,
+		&attachment.ContentType,
+		&attachment.Size,
+		sqliteTime{&attachment.CreatedAt},
+	)
+	if err != nil {
+		return todos.Attachment{}, fmt.Errorf("failed scanning attachment: %w", err)
+	}
+
+	return attachment, nil
+}
+
+func scanSQLiteEvent(row sqliteScanner) (event todos.Event, err error) {
+	err = row.Scan(
+		&event.ID,
+		&event.TodoID,
+		&event.Type,
+		&event.Actor,
+		sqliteJSONTodo{&event.Before},
+		sqliteJSONTodo{&event.After},
+		sqliteTime{&event.OccurredAt},
+	)
+	if err != nil {
+		return todos.Event{}, fmt.Errorf("failed scanning event: %w", err)
+	}
+
+	return event, nil
+}
+
+func scanSQLiteAPIKey(row sqliteScanner) (key auth.Key, err error) {
+	err = row.Scan(
+		&key.ID,
+		&key.Name,
+		&key.Hash,
+		sqliteScopes{&key.Scopes},
+		sqliteNullTime{&key.ExpiresAt},
+		sqliteNullTime{&key.LastUsedAt},
+		sqliteTime{&key.CreatedAt},
+		sqliteNullTime{&key.RevokedAt},
+	)
+	if err != nil {
+		return auth.Key{}, fmt.Errorf("failed scanning api key: %w", err)
+	}
+
+	return key, nil
+}
+
+// scanChangedSQLiteTodo scans the todo changed by the query or returns [ErrTodoNotFound] if there is none.
+func scanChangedSQLiteTodo(row sqliteScanner) (todos.Todo, error) {
+	todo, err := scanSQLiteTodo(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return todos.Todo{}, ErrTodoNotFound
+	}
+
+	if err != nil {
+		return todos.Todo{}, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return todo, nil
+}
+
+func scanSQLiteVersion(row sqliteScanner) (version todos.Version, err error) {
+	todo := &version.Todo
+
+	err = row.Scan(
+		&version.Number,
+		sqliteJSONTodo{&todo},
+		sqliteTime{&version.ValidFrom},
+	)
+	if err != nil {
+		return todos.Version{}, fmt.Errorf("failed scanning version: %w", err)
+	}
+
+	version.Todo = *todo
+
+	return version, nil
+}
+
+func collectSQLiteTodos(rows *sql.Rows) (t []todos.Todo, err error) {
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	// Use append to avoid returning nil slice
+	t = make([]todos.Todo, 0)
+
+	for rows.Next() {
+		todo, err := scanSQLiteTodo(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		t = append(t, todo)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("failed iterating rows: %w", err)
+	}
+
+	return t, nil
+}
+
+func collectSQLiteIDs(rows *sql.Rows) (ids []uuid.UUID, err error) {
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	for rows.Next() {
+		var id uuid.UUID
+
+		err = rows.Scan(&id)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+		}
+
+		ids = append(ids, id)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("failed iterating rows: %w", err)
+	}
+
+	return ids, nil
+}
+
+func collectSQLiteAttachments(rows *sql.Rows) (a []todos.Attachment, err error) {
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	// Use append to avoid returning nil slice
+	a = make([]todos.Attachment, 0)
+
+	for rows.Next() {
+		attachment, err := scanSQLiteAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		a = append(a, attachment)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("failed iterating rows: %w", err)
+	}
+
+	return a, nil
+}
+
+func collectSQLiteAPIKeys(rows *sql.Rows) (k []auth.Key, err error) {
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	// Use append to avoid returning nil slice
+	k = make([]auth.Key, 0)
+
+	for rows.Next() {
+		key, err := scanSQLiteAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		k = append(k, key)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("failed iterating rows: %w", err)
+	}
+
+	return k, nil
+}
+
+func collectSQLiteEvents(rows *sql.Rows) (e []todos.Event, err error) {
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	// Use append to avoid returning nil slice
+	e = make([]todos.Event, 0)
+
+	for rows.Next() {
+		event, err := scanSQLiteEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		e = append(e, event)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("failed iterating rows: %w", err)
+	}
+
+	return e, nil
+}
+
+func collectSQLiteVersions(rows *sql.Rows) (v []todos.Version, err error) {
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	// Use append to avoid returning nil slice
+	v = make([]todos.Version, 0)
+
+	for rows.Next() {
+		version, err := scanSQLiteVersion(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		v = append(v, version)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("failed iterating rows: %w", err)
+	}
+
+	return v, nil
+}
+
+func collectSQLiteSearchResults(rows *sql.Rows) (results []todos.SearchResult, err error) {
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	// Use append to avoid returning nil slice
+	results = make([]todos.SearchResult, 0)
+
+	for rows.Next() {
+		var (
+			rank    float64
+			snippet string
+		)
+
+		todo, err := scanSQLiteTodo(rows, &rank, &snippet)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, todos.SearchResult{
+			Todo:    todo,
+			Rank:    float32(rank),
+			Snippet: markSnippet(snippet),
+		})
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("failed iterating rows: %w", err)
+	}
+
+	return results, nil
+}
+
+// sqliteTime scans timestamp stored in [sqliteTimeFormat].
+type sqliteTime struct {
+	time *time.Time
+}
+
+func (s sqliteTime) Scan(src any) error {
+	text, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("unsupported timestamp type: %T", src)
+	}
+
+	t, err := time.Parse(sqliteTimeFormat, text)
+	if err != nil {
+		return fmt.Errorf("failed parsing timestamp: %w", err)
+	}
+
+	*s.time = t
+
+	return nil
+}
+
+// sqliteNullTime scans nullable timestamp stored in [sqliteTimeFormat].
+type sqliteNullTime struct {
+	time **time.Time
+}
+
+func (s sqliteNullTime) Scan(src any) error {
+	if src == nil {
+		*s.time = nil
+		return nil
+	}
+
+	var t time.Time
+
+	err := sqliteTime{&t}.Scan(src)
+	if err != nil {
+		return err
+	}
+
+	*s.time = &t
+
+	return nil
+}
+
+// sqliteTags scans tag names aggregated to JSON array and sorts them.
+type sqliteTags struct {
+	tags *[]string
+}
+
+func (s sqliteTags) Scan(src any) error {
+	text, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("unsupported tags type: %T", src)
+	}
+
+	var tags []string
+
+	err := json.Unmarshal([]byte(text), &tags)
+	if err != nil {
+		return fmt.Errorf("failed parsing tags: %w", err)
+	}
+
+	*s.tags = normalizeTags(tags)
+
+	return nil
+}
+
+// sqliteScopes scans scopes of API key encoded as JSON array.
+type sqliteScopes struct {
+	scopes *[]string
+}
+
+func (s sqliteScopes) Scan(src any) error {
+	text, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("unsupported scopes type: %T", src)
+	}
+
+	err := json.Unmarshal([]byte(text), s.scopes)
+	if err != nil {
+		return fmt.Errorf("failed parsing scopes: %w", err)
+	}
+
+	return nil
+}
+
+// sqliteProgress scans progress of todo subtasks encoded as JSON object, NULL meaning no subtasks.
+type sqliteProgress struct {
+	progress **todos.Progress
+}
+
+func (s sqliteProgress) Scan(src any) error {
+	if src == nil {
+		*s.progress = nil
+		return nil
+	}
+
+	text, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("unsupported progress type: %T", src)
+	}
+
+	var progress todos.Progress
+
+	err := json.Unmarshal([]byte(text), &progress)
+	if err != nil {
+		return fmt.Errorf("failed parsing progress: %w", err)
+	}
+
+	*s.progress = &progress
+
+	return nil
+}
+
+// sqliteJSONTodo scans todo encoded as JSON object, NULL meaning no todo.
+type sqliteJSONTodo struct {
+	todo **todos.Todo
+}
+
+func (s sqliteJSONTodo) Scan(src any) error {
+	if src == nil {
+		*s.todo = nil
+		return nil
+	}
+
+	text, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("unsupported JSON todo type: %T", src)
+	}
+
+	var todo todos.Todo
+
+	err := json.Unmarshal([]byte(text), &todo)
+	if err != nil {
+		return fmt.Errorf("failed parsing JSON todo: %w", err)
+	}
+
+	*s.todo = &todo
+
+	return nil
+}
+
+func formatSQLiteTime(t time.Time) string {
+	return normalizeTime(t).Format(sqliteTimeFormat)
+}
+
+// inSQLiteTransaction runs the function in a transaction which is committed only if the function succeeds.
+func inSQLiteTransaction(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	err = fn(tx)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return nil
+}
+
+func sqliteArgs(args ...any) []any {
+	for i, arg := range args {
+		args[i] = sqliteValue(arg)
+	}
+
+	return args
+}
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/course-go/todos/internal/todos"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ancestorsQuery finds out whether the parent given by the first argument exists
+// and whether the todo given by the second argument is the parent or any of its ancestors.
+const ancestorsQuery = `
+	WITH RECURSIVE ancestors AS (
+		SELECT id, parent_id FROM todos WHERE id=$1 AND deleted_at IS NULL
+		UNION
+		SELECT todos.id, todos.parent_id FROM todos JOIN ancestors ON todos.id = ancestors.parent_id
+	)
+	SELECT EXISTS (SELECT 1 FROM ancestors), EXISTS (SELECT 1 FROM ancestors WHERE id=$2)
+	`
+
+var (
+	ErrParentNotFound   = errors.New("parent todo with given UUID does not exist")
+	ErrParentCycle      = errors.New("todo cannot be subtask of its own subtask")
+	ErrSubtasksMismatch = errors.New("reordered subtasks do not match subtasks of the todo")
+)
+
+// SubtaskRepository retrieves and orders subtasks of todos. Subtasks are created and re-parented
+// by setting parent of the todo. Deleting a todo deletes all of its subtasks.
+type SubtaskRepository interface {
+	GetSubtasks(ctx context.Context, id uuid.UUID) ([]todos.Todo, error)
+	ReorderSubtasks(ctx context.Context, id uuid.UUID, order []uuid.UUID, reorderedAt time.Time) ([]todos.Todo, error)
+}
+
+// GetSubtasks returns subtasks of the todo in their order.
+func (r Repository) GetSubtasks(ctx context.Context, id uuid.UUID) (t []todos.Todo, err error) {
+	return getSubtasks(ctx, r.pool, id)
+}
+
+func getSubtasks(ctx context.Context, q querier, id uuid.UUID) (t []todos.Todo, err error) {
+	_, err = getTodo(ctx, q, id)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := q.Query(ctx,
+		`
+		SELECT `+postgresTodoColumns+`
+		FROM todos
+		WHERE parent_id=$1 AND deleted_at IS NULL
+		ORDER BY subtask_order, created_at, id
+		`,
+		id,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed querying database: %w", err)
+	}
+
+	// Use append to avoid returning nil slice
+	t = make([]todos.Todo, 0)
+
+	t, err = pgx.AppendRows(t, rows, pgx.RowToStructByName[todos.Todo])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return t, nil
+}
+
+// ReorderSubtasks orders subtasks of the todo as given by their IDs.
+// The IDs have to contain every subtask exactly once. The todo and its subtasks
+// are changed by the new order, so their versions are incremented.
+func (r Repository) ReorderSubtasks(
+	ctx context.Context,
+	id uuid.UUID,
+	order []uuid.UUID,
+	reorderedAt time.Time,
+) (subtasks []todos.Todo, err error) {
+	err = pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		subtasks, err = getSubtasks(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if !matchesSubtasks(subtasks, order) {
+			return ErrSubtasksMismatch
+		}
+
+		_, err = tx.Exec(ctx,
+			`
+			UPDATE todos
+			SET subtask_order = CASE WHEN id=$1 THEN subtask_order ELSE array_position($2::uuid[], id) END,
+				updated_at = $3,
+				version = version + 1
+			WHERE (parent_id=$1 OR id=$1) AND deleted_at IS NULL
+			`,
+			id,
+			order,
+			reorderedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrDatabase, err)
+		}
+
+		subtasks, err = getSubtasks(ctx, tx, id)
+
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed reordering subtasks: %w", err)
+	}
+
+	return subtasks, nil
+}
+
+// checkParent verifies that the optional parent of a todo exists
+// and that the todo is not one of its ancestors.
+func checkParent(ctx context.Context, q querier, id uuid.UUID, parentID *uuid.UUID) error {
+	if parentID == nil {
+		return nil
+	}
+
+	var exists, cycle bool
+
+	err := q.QueryRow(ctx, ancestorsQuery, parentID, id).Scan(&exists, &cycle)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return parentError(exists, cycle)
+}
+
+// deleteSubtasks marks all subtasks of the todo and their subtasks as deleted.
+func deleteSubtasks(ctx context.Context, q querier, id uuid.UUID, deletedAt time.Time) error {
+	rows, err := q.Query(ctx,
+		`
+		WITH RECURSIVE subtasks AS (
+			SELECT id FROM todos WHERE parent_id=$1 AND deleted_at IS NULL
+			UNION
+			SELECT todos.id FROM todos JOIN subtasks ON todos.parent_id = subtasks.id
+			WHERE todos.deleted_at IS NULL
+		)
+		SELECT `+postgresTodoColumns+`
+		FROM todos
+		WHERE id IN (SELECT id FROM subtasks)
+		ORDER BY id
+		`,
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed querying database: %w", err)
+	}
+
+	subtasks, err := pgx.CollectRows(rows, pgx.RowToStructByName[todos.Todo])
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return deleteTodos(ctx, q, subtasks, deletedAt)
+}
+
+func parentError(exists, cycle bool) error {
+	switch {
+	case !exists:
+		return ErrParentNotFound
+	case cycle:
+		return ErrParentCycle
+	default:
+		return nil
+	}
+}
+
+// matchesSubtasks reports whether the order contains every subtask exactly once.
+func matchesSubtasks(subtasks []todos.Todo, order []uuid.UUID) bool {
+	if len(subtasks) != len(order) {
+		return false
+	}
+
+	for _, subtask := range subtasks {
+		if !slices.Contains(order, subtask.ID) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,296 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/course-go/todos/internal/todos"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrTagNotFound = errors.New("tag with given UUID does not exist")
+	ErrTagExists   = errors.New("tag with given name already exists")
+)
+
+// TagRepository stores tags labeling todos.
+// Deleting a tag removes it from all todos. Renaming or deleting a tag
+// changes the version of the todos labeled by it as their tags change.
+type TagRepository interface {
+	GetTags(ctx context.Context) ([]todos.Tag, error)
+	GetTag(ctx context.Context, id uuid.UUID) (todos.Tag, error)
+	CreateTag(ctx context.Context, tag todos.Tag) (todos.Tag, error)
+	SaveTag(ctx context.Context, tag todos.Tag, savedAt time.Time) (todos.Tag, error)
+	DeleteTag(ctx context.Context, id uuid.UUID, deletedAt time.Time) error
+}
+
+// GetTags returns all tags sorted by their names.
+func (r Repository) GetTags(ctx context.Context) (t []todos.Tag, err error) {
+	rows, err := r.pool.Query(ctx,
+		`
+		SELECT id, name
+		FROM tags
+		ORDER BY name
+		`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed querying database: %w", err)
+	}
+
+	// Use append to avoid returning nil slice
+	t = make([]todos.Tag, 0)
+
+	t, err = pgx.AppendRows(t, rows, pgx.RowToStructByName[todos.Tag])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return t, nil
+}
+
+func (r Repository) GetTag(ctx context.Context, id uuid.UUID) (t todos.Tag, err error) {
+	rows, err := r.pool.Query(ctx,
+		`
+		SELECT id, name
+		FROM tags
+		WHERE id=$1
+		`,
+		id,
+	)
+	if err != nil {
+		return todos.Tag{}, fmt.Errorf("failed querying database: %w", err)
+	}
+
+	t, err = pgx.CollectOneRow(rows, pgx.RowToStructByName[todos.Tag])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return todos.Tag{}, ErrTagNotFound
+	}
+
+	if err != nil {
+		return todos.Tag{}, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return t, nil
+}
+
+// CreateTag creates tag with the given name unless there already is one.
+func (r Repository) CreateTag(ctx context.Context, tag todos.Tag) (createdTag todos.Tag, err error) {
+	rows, err := r.pool.Query(ctx,
+		`
+		INSERT INTO tags (name)
+		VALUES ($1)
+		ON CONFLICT (name) DO NOTHING
+		RETURNING id, name
+		`,
+		tag.Name,
+	)
+	if err != nil {
+		return todos.Tag{}, fmt.Errorf("failed querying database: %w", err)
+	}
+
+	createdTag, err = pgx.CollectOneRow(rows, pgx.RowToStructByName[todos.Tag])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return todos.Tag{}, ErrTagExists
+	}
+
+	if err != nil {
+		return todos.Tag{}, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return createdTag, nil
+}
+
+// SaveTag renames the tag unless another tag already has the name.
+func (r Repository) SaveTag(ctx context.Context, tag todos.Tag, savedAt time.Time) (savedTag todos.Tag, err error) {
+	err = pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		previousTodos, err := taggedTodos(ctx, tx, tag.ID)
+		if err != nil {
+			return err
+		}
+
+		rows, err := tx.Query(ctx,
+			`
+			UPDATE tags
+			SET name = $2
+			WHERE id=$1 AND NOT EXISTS (SELECT 1 FROM tags WHERE name = $2 AND id <> $1)
+			RETURNING id, name
+			`,
+			tag.ID,
+			tag.Name,
+		)
+		if err != nil {
+			return fmt.Errorf("failed querying database: %w", err)
+		}
+
+		savedTag, err = pgx.CollectOneRow(rows, pgx.RowToStructByName[todos.Tag])
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrTagExists
+		}
+
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrDatabase, err)
+		}
+
+		return touchTodos(ctx, tx, previousTodos, savedAt)
+	})
+	if errors.Is(err, ErrTagExists) {
+		_, err = r.GetTag(ctx, tag.ID)
+		if err != nil {
+			return todos.Tag{}, err
+		}
+
+		return todos.Tag{}, ErrTagExists
+	}
+
+	if err != nil {
+		return todos.Tag{}, fmt.Errorf("failed saving tag: %w", err)
+	}
+
+	return savedTag, nil
+}
+
+func (r Repository) DeleteTag(ctx context.Context, id uuid.UUID, deletedAt time.Time) error {
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		previousTodos, err := taggedTodos(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		c, err := tx.Exec(ctx,
+			`
+			DELETE FROM tags
+			WHERE id=$1
+			`,
+			id,
+		)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrDatabase, err)
+		}
+
+		if c.RowsAffected() == 0 {
+			return ErrTagNotFound
+		}
+
+		return touchTodos(ctx, tx, previousTodos, deletedAt)
+	})
+	if err != nil {
+		return fmt.Errorf("failed deleting tag: %w", err)
+	}
+
+	return nil
+}
+
+// taggedTodos returns the todos labeled by the tag including the deleted ones.
+func taggedTodos(ctx context.Context, q querier, tagID uuid.UUID) ([]todos.Todo, error) {
+	rows, err := q.Query(ctx,
+		`
+		SELECT `+postgresTodoColumns+`
+		FROM todos
+		WHERE id IN (SELECT todo_id FROM todo_tags WHERE tag_id=$1)
+		ORDER BY id
+		`,
+		tagID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed querying database: %w", err)
+	}
+
+	taggedTodos, err := pgx.CollectRows(rows, pgx.RowToStructByName[todos.Todo])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return taggedTodos, nil
+}
+
+// touchTodos marks the todos, which are in the state before their tag was renamed or deleted,
+// as updated and records the change. The querier should be the transaction renaming or deleting
+// the tag so that entity tags and history of the todos change with it.
+func touchTodos(ctx context.Context, q querier, previousTodos []todos.Todo, changedAt time.Time) error {
+	for _, previousTodo := range previousTodos {
+		rows, err := q.Query(ctx,
+			`
+			UPDATE todos
+			SET updated_at = $2, version = version + 1
+			WHERE id=$1
+			RETURNING `+postgresTodoColumns,
+			previousTodo.ID,
+			changedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed querying database: %w", err)
+		}
+
+		touchedTodo, err := collectChangedTodo(rows)
+		if err != nil {
+			return err
+		}
+
+		event := newEvent(ctx, todos.EventUpdated, &previousTodo, &touchedTodo, changedAt)
+
+		err = recordChange(ctx, q, event, previousTodo, touchedTodo)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setTodoTags replaces tags of the todo creating the tags which do not exist yet.
+func setTodoTags(ctx context.Context, q querier, id uuid.UUID, names []string) error {
+	_, err := q.Exec(ctx,
+		`
+		INSERT INTO tags (name)
+		SELECT unnest($1::text[])
+		ON CONFLICT (name) DO NOTHING
+		`,
+		names,
+	)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	_, err = q.Exec(ctx,
+		`
+		DELETE FROM todo_tags
+		WHERE todo_id=$1
+		`,
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	_, err = q.Exec(ctx,
+		`
+		INSERT INTO todo_tags (todo_id, tag_id)
+		SELECT $1, id
+		FROM tags
+		WHERE name = ANY($2::text[])
+		`,
+		id,
+		names,
+	)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return nil
+}
+
+// normalizeTags sorts the tag names and removes duplicates.
+func normalizeTags(names []string) []string {
+	if len(names) == 0 {
+		return nil
+	}
+
+	names = slices.Clone(names)
+	slices.Sort(names)
+
+	return slices.Compact(names)
+}
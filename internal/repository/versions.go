@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/course-go/todos/internal/todos"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// versionColumns are the columns mapped to [todos.Version] fields.
+const versionColumns = "version AS number, todo, valid_from"
+
+// VersionRepository provides past states of todos. Versions are recorded in the same transaction
+// as the creation, update, completion, reopening, move, deletion or restoration of the todo,
+// including subtasks deleted together with their parent and todos deleted together with their list.
+// Other changes, such as the progress of subtasks, are only seen
+// in the current version until the todo is changed again.
+type VersionRepository interface {
+	GetTodoVersions(ctx context.Context, todoID uuid.UUID) ([]todos.Version, error)
+}
+
+// GetTodoVersions returns versions of the todo, the oldest one first and the current one last.
+// Versions of deleted todos are kept until they are purged.
+func (r Repository) GetTodoVersions(ctx context.Context, todoID uuid.UUID) (v []todos.Version, err error) {
+	rows, err := r.pool.Query(ctx,
+		`
+		SELECT `+versionColumns+`
+		FROM todo_versions
+		WHERE todo_id=$1
+		ORDER BY version
+		`,
+		todoID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed querying database: %w", err)
+	}
+
+	// Use append to avoid returning nil slice
+	v = make([]todos.Version, 0)
+
+	v, err = pgx.AppendRows(v, rows, pgx.RowToStructByName[todos.Version])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	rows, err = r.pool.Query(ctx,
+		`
+		SELECT `+postgresTodoColumns+`
+		FROM todos
+		WHERE id=$1
+		`,
+		todoID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed querying database: %w", err)
+	}
+
+	todo, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[todos.Todo])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrTodoNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDatabase, err)
+	}
+
+	return completeVersions(v, todo), nil
+}
+
+// recordVersions stores the todos as versions. The querier should be the transaction
+// changing the todo so that the change is never made without its version.
+// Versions which are already recorded are skipped, so the todo before the change can be passed
+// as well to keep versions of todos changed before they were recorded or without recording them.
+func recordVersions(ctx context.Context, q querier, versions ...todos.Todo) error {
+	for _, todo := range versions {
+		_, err := q.Exec(ctx,
+			`
+			INSERT INTO todo_versions (todo_id, version, todo, valid_from)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (todo_id, version) DO NOTHING
+			`,
+			todo.ID,
+			todo.Version,
+			&todo,
+			validFrom(todo),
+		)
+		if err != nil {
+			return fmt.Errorf("failed recording version: %w: %w", ErrDatabase, err)
+		}
+	}
+
+	return nil
+}
+
+// recordChange records the event together with the versions of the changed todo.
+func recordChange(ctx context.Context, q querier, event todos.Event, versions ...todos.Todo) error {
+	err := recordEvent(ctx, q, event)
+	if err != nil {
+		return err
+	}
+
+	return recordVersions(ctx, q, versions...)
+}
+
+// completeVersions makes the current todo the last version and sets the end
+// of each version to the start of the next one. The current todo replaces the last recorded
+// version of the same number as it also reflects changes of its subtasks.
+func completeVersions(versions []todos.Version, current todos.Todo) []todos.Version {
+	last := len(versions) - 1
+	if last >= 0 && versions[last].Number == current.Version {
+		versions[last].Todo = current
+	} else {
+		versions = append(versions, todos.Version{Number: current.Version, Todo: current, ValidFrom: validFrom(current)})
+	}
+
+	for i := range versions {
+		// Version of the todo is not part of the recorded todo.
+		versions[i].Todo.Version = versions[i].Number
+
+		if i > 0 {
+			validTo := versions[i].ValidFrom
+			versions[i-1].ValidTo = &validTo
+		}
+	}
+
+	return versions
+}
+
+// validFrom returns time of the last change of the todo.
+func validFrom(todo todos.Todo) time.Time {
+	switch {
+	case todo.DeletedAt != nil:
+		return *todo.DeletedAt
+	case todo.UpdatedAt != nil:
+		return *todo.UpdatedAt
+	default:
+		return todo.CreatedAt
+	}
+}
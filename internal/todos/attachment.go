@@ -0,0 +1,17 @@
+package todos
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Attachment describes a file attached to a todo. The content itself is kept in blob storage.
+type Attachment struct {
+	ID          uuid.UUID `json:"id,omitempty"`
+	TodoID      uuid.UUID `json:"todoId,omitempty"`
+	Name        string    `json:"name,omitempty"`
+	ContentType string    `json:"contentType,omitempty"`
+	Size        int64     `json:"size"`
+	CreatedAt   time.Time `json:"createdAt,omitzero"`
+}
@@ -0,0 +1,18 @@
+package todos
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Comment is a note in the discussion of a todo.
+// Comments of a deleted todo are hidden until the todo is restored.
+type Comment struct {
+	ID        uuid.UUID  `json:"id,omitempty"`
+	TodoID    uuid.UUID  `json:"todoId,omitempty"`
+	Author    string     `json:"author,omitempty"`
+	Body      string     `json:"body,omitempty"`
+	CreatedAt time.Time  `json:"createdAt,omitzero"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
@@ -0,0 +1,32 @@
+package todos
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventType describes how the todo was changed.
+type EventType string
+
+const (
+	EventCreated   EventType = "created"
+	EventUpdated   EventType = "updated"
+	EventCompleted EventType = "completed"
+	EventReopened  EventType = "reopened"
+	EventMoved     EventType = "moved"
+	EventDeleted   EventType = "deleted"
+	EventRestored  EventType = "restored"
+)
+
+// Event records a single change of a todo in its history.
+// Before is not set for created todos and After is not set for deleted ones.
+type Event struct {
+	ID         uuid.UUID `json:"id,omitempty"`
+	TodoID     uuid.UUID `json:"todoId,omitempty"`
+	Type       EventType `json:"type,omitempty"`
+	Actor      string    `json:"actor,omitempty"`
+	Before     *Todo     `json:"before,omitempty"`
+	After      *Todo     `json:"after,omitempty"`
+	OccurredAt time.Time `json:"occurredAt,omitzero"`
+}
@@ -0,0 +1,18 @@
+package todos
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// List groups todos. Deleting a list deletes all of its todos as well.
+// Archived lists keep their todos, they are only meant to be hidden by clients.
+type List struct {
+	ID        uuid.UUID  `json:"id,omitempty"`
+	Name      string     `json:"name,omitempty"`
+	Color     string     `json:"color,omitempty"`
+	Archived  bool       `json:"archived"`
+	CreatedAt time.Time  `json:"createdAt,omitzero"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
@@ -0,0 +1,64 @@
+package todos
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+var ErrUnknownPriority = errors.New("unknown priority")
+
+// Priority tells how important the todo is. Higher priorities are greater.
+// It is represented by its name in JSON and by its number in the database.
+type Priority int //nolint: recvcheck // UnmarshalText needs pointer while MarshalText works on values.
+
+const (
+	PriorityNone Priority = iota
+	PriorityLow
+	PriorityMedium
+	PriorityHigh
+	PriorityUrgent
+)
+
+var priorityNames = []string{"none", "low", "medium", "high", "urgent"}
+
+// ParsePriority parses priority by its name. Empty name means [PriorityNone].
+func ParsePriority(name string) (priority Priority, err error) {
+	if name == "" {
+		return PriorityNone, nil
+	}
+
+	i := slices.Index(priorityNames, name)
+	if i < 0 {
+		return PriorityNone, fmt.Errorf("%w: %q", ErrUnknownPriority, name)
+	}
+
+	return Priority(i), nil
+}
+
+func (p Priority) String() string {
+	if p < PriorityNone || int(p) >= len(priorityNames) {
+		return fmt.Sprintf("Priority(%d)", int(p))
+	}
+
+	return priorityNames[p]
+}
+
+func (p Priority) MarshalText() (text []byte, err error) {
+	if p < PriorityNone || int(p) >= len(priorityNames) {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownPriority, int(p))
+	}
+
+	return []byte(priorityNames[p]), nil
+}
+
+func (p *Priority) UnmarshalText(text []byte) error {
+	priority, err := ParsePriority(string(text))
+	if err != nil {
+		return err
+	}
+
+	*p = priority
+
+	return nil
+}
@@ -0,0 +1,7 @@
+package todos
+
+// Progress counts the subtasks of a todo which are not deleted.
+type Progress struct {
+	Completed int `json:"completed"`
+	Total     int `json:"total"`
+}
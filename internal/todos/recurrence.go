@@ -0,0 +1,296 @@
+package todos
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidRecurrence = errors.New("invalid recurrence rule")
+
+// Frequency is the base period of a recurrence rule.
+type Frequency string
+
+const (
+	FrequencyDaily   Frequency = "DAILY"
+	FrequencyWeekly  Frequency = "WEEKLY"
+	FrequencyMonthly Frequency = "MONTHLY"
+)
+
+const (
+	// recurrenceUntilFormat is the UTC date-time format of UNTIL part of the rule.
+	recurrenceUntilFormat = "20060102T150405Z"
+	// recurrenceUntilDateFormat is the date format of UNTIL part of the rule.
+	recurrenceUntilDateFormat = "20060102"
+	// maxRecurrenceSteps bounds the search for the next occurrence of rules which never match.
+	maxRecurrenceSteps = 1000
+
+	day        = 24 * time.Hour
+	daysInWeek = 7
+)
+
+var weekdayNames = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// Recurrence is a subset of RFC 5545 recurrence rule like "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH;COUNT=10".
+// It supports daily, weekly and monthly frequencies, interval, weekdays and either until or count limit.
+// Weeks start on Monday. It is represented by the rule text in JSON and in the database.
+type Recurrence struct { //nolint: recvcheck // Unmarshalling needs pointer while marshalling works on values.
+	Frequency Frequency `json:"-"`
+	// Interval is the number of periods between occurrences. Zero means every period.
+	Interval int `json:"-"`
+	// ByDay limits daily and weekly occurrences to the given weekdays.
+	ByDay []time.Weekday `json:"-"`
+	// Until is the last time an occurrence can be due at.
+	Until *time.Time `json:"-"`
+	// Count is the number of the remaining occurrences including the current one. Zero means no limit.
+	Count int `json:"-"`
+}
+
+// ParseRecurrence parses recurrence rule text. The parts can be in any order but FREQ is required.
+func ParseRecurrence(text string) (recurrence Recurrence, err error) {
+	for part := range strings.SplitSeq(strings.TrimPrefix(text, "RRULE:"), ";") {
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return Recurrence{}, fmt.Errorf("%w: malformed part %q", ErrInvalidRecurrence, part)
+		}
+
+		err = recurrence.parsePart(strings.ToUpper(name), strings.ToUpper(value))
+		if err != nil {
+			return Recurrence{}, err
+		}
+	}
+
+	err = recurrence.validate()
+	if err != nil {
+		return Recurrence{}, err
+	}
+
+	return recurrence, nil
+}
+
+// Next returns the first occurrence after the given one and the rule of the occurrences following it.
+// False is returned when the rule has no more occurrences.
+func (r Recurrence) Next(after time.Time) (next time.Time, rest Recurrence, ok bool) {
+	if r.Count == 1 {
+		return time.Time{}, Recurrence{}, false
+	}
+
+	next, ok = r.next(after)
+	if !ok || (r.Until != nil && next.After(*r.Until)) {
+		return time.Time{}, Recurrence{}, false
+	}
+
+	rest = r
+	rest.ByDay = slices.Clone(r.ByDay)
+
+	if r.Count > 0 {
+		rest.Count--
+	}
+
+	return next, rest, true
+}
+
+func (r Recurrence) String() string {
+	parts := []string{"FREQ=" + string(r.Frequency)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+
+	if len(r.ByDay) > 0 {
+		days := make([]string, 0, len(r.ByDay))
+		for _, day := range r.ByDay {
+			days = append(days, weekdayNames[day])
+		}
+
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format(recurrenceUntilFormat))
+	}
+
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+
+	return strings.Join(parts, ";")
+}
+
+func (r Recurrence) MarshalText() (text []byte, err error) {
+	err = r.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(r.String()), nil
+}
+
+func (r *Recurrence) UnmarshalText(text []byte) error {
+	recurrence, err := ParseRecurrence(string(text))
+	if err != nil {
+		return err
+	}
+
+	*r = recurrence
+
+	return nil
+}
+
+// Value stores the recurrence as its rule text.
+func (r Recurrence) Value() (driver.Value, error) {
+	return r.String(), nil
+}
+
+// Scan reads the recurrence from its rule text.
+func (r *Recurrence) Scan(src any) error {
+	switch v := src.(type) {
+	case string:
+		return r.UnmarshalText([]byte(v))
+	case []byte:
+		return r.UnmarshalText(v)
+	default:
+		return fmt.Errorf("unsupported recurrence type: %T", src)
+	}
+}
+
+func (r *Recurrence) parsePart(name, value string) (err error) {
+	switch name {
+	case "FREQ":
+		r.Frequency = Frequency(value)
+	case "INTERVAL":
+		r.Interval, err = strconv.Atoi(value)
+	case "BYDAY":
+		r.ByDay, err = parseWeekdays(value)
+	case "UNTIL":
+		r.Until, err = parseUntil(value)
+	case "COUNT":
+		r.Count, err = strconv.Atoi(value)
+	default:
+		return fmt.Errorf("%w: unsupported part %q", ErrInvalidRecurrence, name)
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w: invalid %s: %w", ErrInvalidRecurrence, name, err)
+	}
+
+	return nil
+}
+
+func (r Recurrence) validate() error {
+	switch {
+	case !slices.Contains([]Frequency{FrequencyDaily, FrequencyWeekly, FrequencyMonthly}, r.Frequency):
+		return fmt.Errorf("%w: unsupported frequency %q", ErrInvalidRecurrence, r.Frequency)
+	case r.Interval < 0 || r.Count < 0:
+		return fmt.Errorf("%w: interval and count cannot be negative", ErrInvalidRecurrence)
+	case r.Until != nil && r.Count > 0:
+		return fmt.Errorf("%w: until and count cannot be combined", ErrInvalidRecurrence)
+	case r.Frequency == FrequencyMonthly && len(r.ByDay) > 0:
+		return fmt.Errorf("%w: weekdays are not supported for monthly frequency", ErrInvalidRecurrence)
+	default:
+		return nil
+	}
+}
+
+// next finds the next occurrence ignoring the limits of the rule.
+func (r Recurrence) next(after time.Time) (next time.Time, ok bool) {
+	interval := max(r.Interval, 1)
+
+	switch r.Frequency {
+	case FrequencyDaily:
+		return r.nextDaily(after, interval)
+	case FrequencyWeekly:
+		return r.nextWeekly(after, interval)
+	case FrequencyMonthly:
+		return nextMonthly(after, interval)
+	default:
+		return time.Time{}, false
+	}
+}
+
+func (r Recurrence) nextDaily(after time.Time, interval int) (next time.Time, ok bool) {
+	for i := 1; i <= maxRecurrenceSteps; i++ {
+		next = after.AddDate(0, 0, i*interval)
+		if r.onDay(next) {
+			return next, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+func (r Recurrence) nextWeekly(after time.Time, interval int) (next time.Time, ok bool) {
+	if len(r.ByDay) == 0 {
+		return after.AddDate(0, 0, daysInWeek*interval), true
+	}
+
+	week := startOfWeek(after)
+	for i := 1; i <= maxRecurrenceSteps; i++ {
+		next = after.AddDate(0, 0, i)
+
+		// Rounding compensates for days shortened or prolonged by daylight saving time changes.
+		days := int(startOfWeek(next).Sub(week).Round(day) / day)
+		if (days/daysInWeek)%interval == 0 && r.onDay(next) {
+			return next, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+func (r Recurrence) onDay(t time.Time) bool {
+	return len(r.ByDay) == 0 || slices.Contains(r.ByDay, t.Weekday())
+}
+
+// nextMonthly finds the same day in the following months skipping the months which do not have it.
+func nextMonthly(after time.Time, interval int) (next time.Time, ok bool) {
+	for i := 1; i <= maxRecurrenceSteps; i++ {
+		next = after.AddDate(0, i*interval, 0)
+		if next.Day() == after.Day() {
+			return next, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+func parseWeekdays(value string) (weekdays []time.Weekday, err error) {
+	for name := range strings.SplitSeq(value, ",") {
+		i := slices.Index(weekdayNames, name)
+		if i < 0 {
+			return nil, fmt.Errorf("unknown weekday %q", name)
+		}
+
+		if !slices.Contains(weekdays, time.Weekday(i)) {
+			weekdays = append(weekdays, time.Weekday(i))
+		}
+	}
+
+	return weekdays, nil
+}
+
+func parseUntil(value string) (until *time.Time, err error) {
+	t, err := time.Parse(recurrenceUntilFormat, value)
+	if err != nil {
+		// Dates include the whole day.
+		t, err = time.Parse(recurrenceUntilDateFormat, value)
+		if err != nil {
+			return nil, fmt.Errorf("expected date or UTC date-time: %w", err)
+		}
+
+		t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+
+	return &t, nil
+}
+
+// startOfWeek returns midnight of the Monday in the week of the given time.
+func startOfWeek(t time.Time) time.Time {
+	daysSinceMonday := (int(t.Weekday()) + daysInWeek - 1) % daysInWeek
+	year, month, day := t.AddDate(0, 0, -daysSinceMonday).Date()
+
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,119 @@
+package todos_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/course-go/todos/internal/todos"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	t.Parallel()
+	t.Run("Format parsed recurrence", func(t *testing.T) {
+		t.Parallel()
+
+		recurrence, err := todos.ParseRecurrence("RRULE:byday=mo,th;FREQ=WEEKLY;INTERVAL=1;UNTIL=20241231")
+		if err != nil {
+			t.Fatalf("could not parse recurrence: expected: nil != actual: %v", err)
+		}
+
+		expectedRule := "FREQ=WEEKLY;BYDAY=MO,TH;UNTIL=20241231T235959Z"
+		if recurrence.String() != expectedRule {
+			t.Fatalf("formatted recurrence does not match: expected: %s != actual: %s", expectedRule, recurrence)
+		}
+	})
+	t.Run("Invalid recurrence", func(t *testing.T) {
+		t.Parallel()
+
+		rules := []string{
+			"",
+			"INTERVAL=2",
+			"FREQ=HOURLY",
+			"FREQ=DAILY;INTERVAL=-1",
+			"FREQ=DAILY;BYDAY=MON",
+			"FREQ=DAILY;COUNT=5;UNTIL=20241231",
+			"FREQ=MONTHLY;BYDAY=MO",
+			"FREQ=DAILY;BYMONTH=1",
+		}
+		for _, rule := range rules {
+			_, err := todos.ParseRecurrence(rule)
+			if !errors.Is(err, todos.ErrInvalidRecurrence) {
+				t.Errorf("recurrence %q should not be parsed: expected: %v != actual: %v",
+					rule,
+					todos.ErrInvalidRecurrence,
+					err,
+				)
+			}
+		}
+	})
+}
+
+func TestRecurrenceNext(t *testing.T) {
+	t.Parallel()
+	t.Run("Next occurrences", func(t *testing.T) {
+		t.Parallel()
+
+		// Wednesday.
+		after := time.Date(2024, time.January, 31, 9, 0, 0, 0, time.UTC)
+		expectedOccurrences := map[string]time.Time{
+			"FREQ=DAILY":                         time.Date(2024, time.February, 1, 9, 0, 0, 0, time.UTC),
+			"FREQ=DAILY;INTERVAL=3":              time.Date(2024, time.February, 3, 9, 0, 0, 0, time.UTC),
+			"FREQ=DAILY;BYDAY=MO,TU":             time.Date(2024, time.February, 5, 9, 0, 0, 0, time.UTC),
+			"FREQ=WEEKLY":                        time.Date(2024, time.February, 7, 9, 0, 0, 0, time.UTC),
+			"FREQ=WEEKLY;BYDAY=TU,FR":            time.Date(2024, time.February, 2, 9, 0, 0, 0, time.UTC),
+			"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE": time.Date(2024, time.February, 12, 9, 0, 0, 0, time.UTC),
+			"FREQ=MONTHLY":                       time.Date(2024, time.March, 31, 9, 0, 0, 0, time.UTC),
+			"FREQ=MONTHLY;INTERVAL=2":            time.Date(2024, time.March, 31, 9, 0, 0, 0, time.UTC),
+			"FREQ=WEEKLY;UNTIL=20240207T090000Z": time.Date(2024, time.February, 7, 9, 0, 0, 0, time.UTC),
+			"FREQ=DAILY;COUNT=2":                 time.Date(2024, time.February, 1, 9, 0, 0, 0, time.UTC),
+			"FREQ=WEEKLY;INTERVAL=3;BYDAY=WE,TH": time.Date(2024, time.February, 1, 9, 0, 0, 0, time.UTC),
+		}
+
+		for rule, expected := range expectedOccurrences {
+			recurrence, err := todos.ParseRecurrence(rule)
+			if err != nil {
+				t.Fatalf("could not parse recurrence %q: %v", rule, err)
+			}
+
+			next, _, ok := recurrence.Next(after)
+			if !ok || !next.Equal(expected) {
+				t.Errorf("next occurrence of %q does not match: expected: %s != actual: %s", rule, expected, next)
+			}
+		}
+	})
+}
+
+func TestRecurrenceEnd(t *testing.T) {
+	t.Parallel()
+	t.Run("Last occurrence", func(t *testing.T) {
+		t.Parallel()
+
+		after := time.Date(2024, time.January, 31, 9, 0, 0, 0, time.UTC)
+
+		for _, rule := range []string{"FREQ=DAILY;COUNT=1", "FREQ=WEEKLY;UNTIL=20240206", "FREQ=DAILY;INTERVAL=7;BYDAY=MO"} {
+			recurrence, err := todos.ParseRecurrence(rule)
+			if err != nil {
+				t.Fatalf("could not parse recurrence %q: %v", rule, err)
+			}
+
+			next, _, ok := recurrence.Next(after)
+			if ok {
+				t.Errorf("recurrence %q should have ended: expected: no occurrence != actual: %s", rule, next)
+			}
+		}
+	})
+	t.Run("Count remaining occurrences", func(t *testing.T) {
+		t.Parallel()
+
+		recurrence, err := todos.ParseRecurrence("FREQ=DAILY;COUNT=3")
+		if err != nil {
+			t.Fatalf("could not parse recurrence: %v", err)
+		}
+
+		_, rest, ok := recurrence.Next(time.Date(2024, time.January, 31, 9, 0, 0, 0, time.UTC))
+		if !ok || rest.Count != 2 {
+			t.Fatalf("remaining occurrences do not match: expected: 2 != actual: %d", rest.Count)
+		}
+	})
+}
@@ -0,0 +1,10 @@
+package todos
+
+// SearchResult is a todo matching full-text search.
+// Snippet contains the description escaped as HTML with matching words enclosed in <mark> tags.
+type SearchResult struct {
+	Todo
+
+	Rank    float32 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
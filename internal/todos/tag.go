@@ -0,0 +1,9 @@
+package todos
+
+import "github.com/google/uuid"
+
+// Tag labels todos. Todos refer to their tags by the names which are unique.
+type Tag struct {
+	ID   uuid.UUID `json:"id,omitempty"`
+	Name string    `json:"name,omitempty"`
+}
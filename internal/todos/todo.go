@@ -1,6 +1,7 @@
 package todos
 
 import (
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,7 +10,57 @@ import (
 type Todo struct {
 	ID          uuid.UUID  `json:"id,omitempty"`
 	Description string     `json:"description,omitempty"`
+	ListID      *uuid.UUID `json:"listId,omitempty"`
+	// ParentID is set for subtasks of another todo.
+	ParentID *uuid.UUID `json:"parentId,omitempty"`
+	Priority Priority   `json:"priority,omitempty"`
+	DueAt    *time.Time `json:"dueAt,omitempty"`
+	// Recurrence schedules the next occurrence of the todo once it is completed.
+	Recurrence *Recurrence `json:"recurrence,omitempty"`
+	// Position orders todos manually. Positions are compared lexicographically.
+	Position string `json:"position,omitempty"`
+	// Tags are names of the tags sorted alphabetically.
+	Tags        []string   `json:"tags,omitempty"`
 	CreatedAt   time.Time  `json:"createdAt,omitzero"`
 	CompletedAt *time.Time `json:"completedAt,omitempty"`
 	UpdatedAt   *time.Time `json:"updatedAt,omitempty"`
+	DeletedAt   *time.Time `json:"deletedAt,omitempty"`
+	// Progress summarizes the subtasks. It is only set for todos which have any.
+	Progress *Progress `json:"progress,omitempty"`
+	// SubtaskOrder orders subtasks of the same parent. It is exposed as the order of the subtasks instead.
+	SubtaskOrder int `json:"-"`
+	// Version is incremented on every change. It is exposed as entity tag instead.
+	Version int `json:"-"`
+}
+
+// NextOccurrence returns the todo which replaces the recurring todo completed at the given time.
+// The next occurrence is due after the due time of the todo or after its completion if it had none.
+// False is returned if the todo does not recur or its recurrence has ended.
+func (t Todo) NextOccurrence(completedAt time.Time) (next Todo, ok bool) {
+	if t.Recurrence == nil {
+		return Todo{}, false
+	}
+
+	after := completedAt
+	if t.DueAt != nil {
+		after = *t.DueAt
+	}
+
+	dueAt, recurrence, ok := t.Recurrence.Next(after)
+	if !ok {
+		return Todo{}, false
+	}
+
+	next = Todo{
+		Description: t.Description,
+		ListID:      t.ListID,
+		ParentID:    t.ParentID,
+		Priority:    t.Priority,
+		DueAt:       &dueAt,
+		Recurrence:  &recurrence,
+		Tags:        slices.Clone(t.Tags),
+		CreatedAt:   completedAt,
+	}
+
+	return next, true
 }
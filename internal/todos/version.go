@@ -0,0 +1,26 @@
+package todos
+
+import "time"
+
+// Version is the state of a todo after one of its changes.
+// It is valid from the change until the next version, the current version has no end.
+type Version struct {
+	Number    int        `json:"version"`
+	Todo      Todo       `json:"todo"`
+	ValidFrom time.Time  `json:"validFrom,omitzero"`
+	ValidTo   *time.Time `json:"validTo,omitempty"`
+}
+
+// VersionAt returns the version valid at the given time from the versions ordered from the oldest one.
+// False is returned if the todo did not exist yet.
+func VersionAt(versions []Version, t time.Time) (version Version, ok bool) {
+	for _, v := range versions {
+		if v.ValidFrom.After(t) {
+			break
+		}
+
+		version, ok = v, true
+	}
+
+	return version, ok
+}
@@ -6,40 +6,45 @@ import (
 	"net/http"
 	"testing"
 
+	"github.com/course-go/todos/internal/blob"
+	"github.com/course-go/todos/internal/config"
 	"github.com/course-go/todos/internal/health"
 	thttp "github.com/course-go/todos/internal/http"
+	cattachments "github.com/course-go/todos/internal/http/controllers/attachments"
+	ccomments "github.com/course-go/todos/internal/http/controllers/comments"
 	chealth "github.com/course-go/todos/internal/http/controllers/health"
+	ckeys "github.com/course-go/todos/internal/http/controllers/keys"
+	clists "github.com/course-go/todos/internal/http/controllers/lists"
+	ctags "github.com/course-go/todos/internal/http/controllers/tags"
 	ctodos "github.com/course-go/todos/internal/http/controllers/todos"
 	"github.com/course-go/todos/internal/http/metrics"
-	"github.com/course-go/todos/internal/repository"
+	"github.com/course-go/todos/internal/http/middleware"
 	"github.com/go-playground/validator/v10"
-	"github.com/testcontainers/testcontainers-go/modules/postgres"
 )
 
+// AdminKey is token of the admin key of routers requiring authentication.
+const AdminKey = "todos_test-admin-key"
+
+// NewTestRouter creates router backed by in-memory repository seeded with the test todos.
+// Authentication is disabled so that requests need no API key.
 func NewTestRouter(ctx context.Context, t *testing.T, logger *slog.Logger) http.Handler {
 	t.Helper()
-	c := NewTestContainer(ctx, t)
-	t.Cleanup(func() {
-		err := c.Terminate(ctx)
-		if err != nil {
-			t.Logf("failed terminating postgres container: %v", err)
-		}
-	})
-	cfg := NewTestDatabaseConfig(ctx, t, c)
 
-	err := repository.Migrate(cfg, logger)
-	if err != nil {
-		t.Fatalf("failed migrating database: %v", err)
-	}
+	return newTestRouter(ctx, t, logger, &config.Auth{Disabled: true})
+}
 
-	SeedDatabase(ctx, t, c)
+// NewTestAuthRouter creates router like [NewTestRouter] which requires API keys.
+// Keys can be minted with the [AdminKey].
+func NewTestAuthRouter(ctx context.Context, t *testing.T, logger *slog.Logger) http.Handler {
+	t.Helper()
 
-	err = c.Snapshot(ctx, postgres.WithSnapshotName("test-todos"))
-	if err != nil {
-		t.Fatalf("failed creating database snapshot: %v", err)
-	}
+	return newTestRouter(ctx, t, logger, &config.Auth{AdminKey: AdminKey})
+}
+
+func newTestRouter(ctx context.Context, t *testing.T, logger *slog.Logger, cfg *config.Auth) http.Handler {
+	t.Helper()
 
-	r := NewTestRepository(ctx, t, logger, cfg)
+	r := NewTestMemoryRepository(t)
 	p := NewMetricProvider(t)
 
 	m, err := metrics.New(p)
@@ -52,10 +57,22 @@ func NewTestRouter(ctx context.Context, t *testing.T, logger *slog.Logger) http.
 		t.Fatalf("failed creating health registry: %v", err)
 	}
 
-	tc := ctodos.NewController(NewTestLogger(t), validator.New(validator.WithRequiredStructEnabled()), r, NewTimeNow(t))
+	s, err := blob.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed creating blob store: %v", err)
+	}
+
+	v := validator.New(validator.WithRequiredStructEnabled())
+	tc := ctodos.NewController(NewTestLogger(t), v, r, NewTimeNow(t))
+	tgc := ctags.NewController(NewTestLogger(t), v, r, NewTimeNow(t))
+	lc := clists.NewController(NewTestLogger(t), v, r, NewTimeNow(t))
+	cc := ccomments.NewController(NewTestLogger(t), v, r, NewTimeNow(t))
+	ac := cattachments.NewController(NewTestLogger(t), r, s, NewTestAttachmentsConfig(t), NewTimeNow(t))
+	kc := ckeys.NewController(NewTestLogger(t), v, r, NewTimeNow(t))
 	hc := chealth.NewController(h)
+	a := middleware.Authenticate(NewTestLogger(t), r, cfg, NewTimeNow(t))
 
-	server, err := thttp.NewServer(logger, m, "testing", hc, tc)
+	server, err := thttp.NewServer(logger, m, "testing", a, hc, tc, tgc, lc, cc, ac, kc)
 	if err != nil {
 		t.Fatalf("failed creating http server: %v", err)
 	}
@@ -8,6 +8,8 @@ import (
 	"github.com/testcontainers/testcontainers-go/modules/postgres"
 )
 
+const attachmentsMaxSize = 1024
+
 func NewTestDatabaseConfig(ctx context.Context, t *testing.T, c *postgres.PostgresContainer) *config.Database {
 	t.Helper()
 
@@ -30,3 +32,13 @@ func NewTestDatabaseConfig(ctx context.Context, t *testing.T, c *postgres.Postgr
 		Name:     dbName,
 	}
 }
+
+// NewTestAttachmentsConfig allows only small plain text and PNG attachments with sniffed content types.
+func NewTestAttachmentsConfig(t *testing.T) *config.Attachments {
+	t.Helper()
+
+	return &config.Attachments{
+		MaxSize:      attachmentsMaxSize,
+		AllowedTypes: []string{"text/plain", "image/png"},
+	}
+}
@@ -0,0 +1,90 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/course-go/todos/internal/repository"
+	"github.com/course-go/todos/internal/todos"
+	"github.com/google/uuid"
+)
+
+// NewTestMemoryRepository creates in-memory repository
+// containing the same todos and tags as the seeded test database.
+func NewTestMemoryRepository(t *testing.T) *repository.MemoryRepository {
+	t.Helper()
+
+	return repository.NewMemoryWithTags(
+		[]todos.Tag{
+			{ID: parseUUID(t, "c5f3a5e2-1d4b-4c8e-9a51-7f2b8d3e6a10"), Name: "home"},
+			{ID: parseUUID(t, "0b9e4d7a-5c2f-4e61-8d3a-2f6c1b7e9d42"), Name: "work"},
+			{ID: parseUUID(t, "8e2d6c1f-3a7b-4d95-b0c4-5e9f2a8d1c37"), Name: "urgent"},
+		},
+		todos.Todo{
+			ID:          parseUUID(t, "62446c85-3798-471f-abb8-75c1cdd7153b"),
+			Description: "Mop the floor",
+			DueAt:       parseTimePointer(t, "2024-08-01T12:00:00Z"),
+			Tags:        []string{"home"},
+			Position:    "3",
+			CreatedAt:   parseTime(t, "2024-07-26T22:48:21.090537Z"),
+		},
+		todos.Todo{
+			ID:          parseUUID(t, "f52bad23-c201-414e-9bdb-af4327c42aa7"),
+			Description: "Vacuum",
+			Priority:    todos.PriorityHigh,
+			DueAt:       parseTimePointer(t, "2024-07-28T12:00:00Z"),
+			Tags:        []string{"home", "urgent"},
+			Position:    "4",
+			CreatedAt:   parseTime(t, "2024-07-26T22:49:47.366006Z"),
+			UpdatedAt:   parseTimePointer(t, "2024-07-27T22:50:19.594495Z"),
+			CompletedAt: parseTimePointer(t, "2024-07-27T22:50:19.594495Z"),
+		},
+		todos.Todo{
+			ID:          parseUUID(t, "aeec043e-05ea-4271-9772-ddefe87628d6"),
+			Description: "Clean the car",
+			Position:    "2",
+			CreatedAt:   parseTime(t, "2024-07-25T22:49:47.366006Z"),
+			UpdatedAt:   parseTimePointer(t, "2024-07-27T22:50:19.594495Z"),
+			DeletedAt:   parseTimePointer(t, "2024-07-27T22:50:19.594495Z"),
+		},
+		todos.Todo{
+			ID:          parseUUID(t, "1221a4fb-34cb-43cd-bc94-88e720ae8511"),
+			Description: "Do nothing",
+			Position:    "1",
+			CreatedAt:   parseTime(t, "2024-07-25T22:49:47.366006Z"),
+			UpdatedAt:   parseTimePointer(t, "2024-07-27T22:50:19.594495Z"),
+			CompletedAt: parseTimePointer(t, "2024-07-27T22:45:20.594495Z"),
+			DeletedAt:   parseTimePointer(t, "2024-07-27T22:50:19.594495Z"),
+		},
+	)
+}
+
+func parseUUID(t *testing.T, s string) uuid.UUID {
+	t.Helper()
+
+	id, err := uuid.Parse(s)
+	if err != nil {
+		t.Fatalf("could not parse uuid: %v", err)
+	}
+
+	return id
+}
+
+func parseTime(t *testing.T, s string) time.Time {
+	t.Helper()
+
+	parsed, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		t.Fatalf("could not parse time: %v", err)
+	}
+
+	return parsed
+}
+
+func parseTimePointer(t *testing.T, s string) *time.Time {
+	t.Helper()
+
+	parsed := parseTime(t, s)
+
+	return &parsed
+}
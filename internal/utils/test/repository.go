@@ -71,6 +71,27 @@ func NewTestContainer(ctx context.Context, t *testing.T) *postgres.PostgresConta
 func SeedDatabase(ctx context.Context, t *testing.T, c *postgres.PostgresContainer) {
 	t.Helper()
 
+	for _, seedQuery := range seedQueries(t) {
+		_, _, err := c.Exec(ctx, []string{"psql", "-U", dbUser, "-d", dbName, "-c", seedQuery})
+		if err != nil {
+			t.Fatalf("failed executing seeding commands: %v", err)
+		}
+	}
+}
+
+func RestoreDatabase(ctx context.Context, t *testing.T, c *postgres.PostgresContainer) {
+	t.Helper()
+
+	err := c.Restore(ctx, postgres.WithSnapshotName("test-todos"))
+	if err != nil {
+		t.Fatalf("failed restoring database: %v", err)
+	}
+}
+
+// seedQueries returns contents of the seed files shared by all databases.
+func seedQueries(t *testing.T) []string {
+	t.Helper()
+
 	_, filename, _, ok := runtime.Caller(0)
 	if !ok {
 		t.Fatalf("failed retrieving current runtime filename")
@@ -83,26 +104,15 @@ func SeedDatabase(ctx context.Context, t *testing.T, c *postgres.PostgresContain
 		t.Fatalf("failed reading directory %s: %v", dir, err)
 	}
 
+	queries := make([]string, 0, len(files))
 	for _, file := range files {
 		bytes, err := os.ReadFile(path.Join(dir, file.Name())) //nolint: gosec
 		if err != nil {
 			t.Fatalf("could not read seed file: %v", err)
 		}
 
-		seedQuery := string(bytes)
-
-		_, _, err = c.Exec(ctx, []string{"psql", "-U", dbUser, "-d", dbName, "-c", seedQuery})
-		if err != nil {
-			t.Fatalf("failed executing seeding commands: %v", err)
-		}
+		queries = append(queries, string(bytes))
 	}
-}
 
-func RestoreDatabase(ctx context.Context, t *testing.T, c *postgres.PostgresContainer) {
-	t.Helper()
-
-	err := c.Restore(ctx, postgres.WithSnapshotName("test-todos"))
-	if err != nil {
-		t.Fatalf("failed restoring database: %v", err)
-	}
+	return queries
 }
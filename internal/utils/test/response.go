@@ -0,0 +1,59 @@
+package test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/course-go/todos/internal/http/dto/response"
+	"github.com/google/go-cmp/cmp"
+)
+
+// CompareResponseCodes reports the response if it does not have the expected status code.
+func CompareResponseCodes(t *testing.T, res *http.Response, expectedCode int) {
+	t.Helper()
+
+	actualCode := res.StatusCode
+	if expectedCode != actualCode {
+		t.Errorf("expected %d status code but was %d", expectedCode, actualCode)
+	}
+}
+
+// CompareResponseBodies reports the response if its body does not match the expected JSON body.
+func CompareResponseBodies(t *testing.T, res *http.Response, expectedBody []byte) {
+	t.Helper()
+
+	var expectedResponseBody response.Response
+
+	err := json.Unmarshal(expectedBody, &expectedResponseBody)
+	if err != nil {
+		t.Errorf("could not unmarshal expected body bytes: %v", err)
+	}
+
+	actualResponseBody := DecodeResponseBody(t, res)
+	if !cmp.Equal(expectedResponseBody, actualResponseBody) {
+		t.Errorf("expected and actual response bodies do not match: %s",
+			cmp.Diff(expectedResponseBody, actualResponseBody),
+		)
+	}
+}
+
+// DecodeResponseBody reads and decodes the JSON body of the response.
+func DecodeResponseBody(t *testing.T, res *http.Response) response.Response {
+	t.Helper()
+
+	bodyBytes, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("could not read body bytes: %v", err)
+	}
+
+	var body response.Response
+
+	err = json.Unmarshal(bodyBytes, &body)
+	if err != nil {
+		t.Fatalf("could not unmarshal body bytes: %v", err)
+	}
+
+	return body
+}
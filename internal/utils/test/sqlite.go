@@ -0,0 +1,51 @@
+package test
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"github.com/course-go/todos/internal/config"
+	"github.com/course-go/todos/internal/health"
+	"github.com/course-go/todos/internal/repository"
+)
+
+// NewTestSQLiteRepository creates repository backed by a new SQLite database seeded with testdata.
+func NewTestSQLiteRepository(ctx context.Context, t *testing.T, logger *slog.Logger) *repository.SQLiteRepository {
+	t.Helper()
+
+	cfg := &config.Database{
+		Protocol: repository.SQLiteProtocol,
+		Name:     filepath.Join(t.TempDir(), "todos.db"),
+	}
+
+	h, err := health.NewRegistry(ctx)
+	if err != nil {
+		t.Fatalf("failed to health registry: %v", err)
+	}
+
+	r, err := repository.NewSQLite(ctx, logger, h, cfg)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+
+	db, err := sql.Open("sqlite", cfg.Name)
+	if err != nil {
+		t.Fatalf("failed opening database: %v", err)
+	}
+
+	defer func() {
+		_ = db.Close()
+	}()
+
+	for _, seedQuery := range seedQueries(t) {
+		_, err = db.ExecContext(ctx, seedQuery)
+		if err != nil {
+			t.Fatalf("failed executing seeding commands: %v", err)
+		}
+	}
+
+	return r
+}
@@ -1,6 +1,18 @@
-INSERT INTO todos (id, description, created_at, updated_at, completed_at, deleted_at)
+INSERT INTO todos (id, description, priority, due_at, position, created_at, updated_at, completed_at, deleted_at)
 VALUES
-  ('62446c85-3798-471f-abb8-75c1cdd7153b', 'Mop the floor', '2024-07-26 22:48:21.090537Z', NULL, NULL, NULL),
-  ('f52bad23-c201-414e-9bdb-af4327c42aa7', 'Vacuum', '2024-07-26 22:49:47.366006Z', '2024-07-27 22:50:19.594495Z', '2024-07-27 22:50:19.594495Z', NULL),
-  ('aeec043e-05ea-4271-9772-ddefe87628d6', 'Clean the car', '2024-07-25 22:49:47.366006Z', '2024-07-27 22:50:19.594495Z', NULL, '2024-07-27 22:50:19.594495Z'),
-  ('1221a4fb-34cb-43cd-bc94-88e720ae8511', 'Do nothing', '2024-07-25 22:49:47.366006Z', '2024-07-27 22:50:19.594495Z', '2024-07-27 22:45:20.594495Z', '2024-07-27 22:50:19.594495Z');
+  ('62446c85-3798-471f-abb8-75c1cdd7153b', 'Mop the floor', 0, '2024-08-01 12:00:00.000000Z', '3', '2024-07-26 22:48:21.090537Z', NULL, NULL, NULL),
+  ('f52bad23-c201-414e-9bdb-af4327c42aa7', 'Vacuum', 3, '2024-07-28 12:00:00.000000Z', '4', '2024-07-26 22:49:47.366006Z', '2024-07-27 22:50:19.594495Z', '2024-07-27 22:50:19.594495Z', NULL),
+  ('aeec043e-05ea-4271-9772-ddefe87628d6', 'Clean the car', 0, NULL, '2', '2024-07-25 22:49:47.366006Z', '2024-07-27 22:50:19.594495Z', NULL, '2024-07-27 22:50:19.594495Z'),
+  ('1221a4fb-34cb-43cd-bc94-88e720ae8511', 'Do nothing', 0, NULL, '1', '2024-07-25 22:49:47.366006Z', '2024-07-27 22:50:19.594495Z', '2024-07-27 22:45:20.594495Z', '2024-07-27 22:50:19.594495Z');
+
+INSERT INTO tags (id, name)
+VALUES
+  ('c5f3a5e2-1d4b-4c8e-9a51-7f2b8d3e6a10', 'home'),
+  ('0b9e4d7a-5c2f-4e61-8d3a-2f6c1b7e9d42', 'work'),
+  ('8e2d6c1f-3a7b-4d95-b0c4-5e9f2a8d1c37', 'urgent');
+
+INSERT INTO todo_tags (todo_id, tag_id)
+VALUES
+  ('62446c85-3798-471f-abb8-75c1cdd7153b', 'c5f3a5e2-1d4b-4c8e-9a51-7f2b8d3e6a10'),
+  ('f52bad23-c201-414e-9bdb-af4327c42aa7', 'c5f3a5e2-1d4b-4c8e-9a51-7f2b8d3e6a10'),
+  ('f52bad23-c201-414e-9bdb-af4327c42aa7', '8e2d6c1f-3a7b-4d95-b0c4-5e9f2a8d1c37');